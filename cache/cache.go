@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Cache 바이트 값을 저장하는 캐시
+// 값의 인코딩은 호출하는 쪽에서 정함, 캐시는 만료 시간만 관리함
+// 키는 "네임스페이스:..." 형태로 쓰고, 적중/실패 통계는 네임스페이스별로 모음
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(keys ...string)
+	DeletePrefix(prefix string)
+	Stats() map[string]Stats
+}
+
+// Stats hit and miss counts of one key namespace
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+type counter struct {
+	hits   uint64
+	misses uint64
+}
+
+// metrics 캐시 구현체들이 공유하는 적중/실패 카운터
+type metrics struct {
+	counters sync.Map // namespace -> *counter
+}
+
+func namespaceOf(key string) string {
+	if i := strings.IndexByte(key, ':'); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+func (m *metrics) counterOf(key string) *counter {
+	namespace := namespaceOf(key)
+	if existing, ok := m.counters.Load(namespace); ok {
+		return existing.(*counter)
+	}
+	created, _ := m.counters.LoadOrStore(namespace, &counter{})
+	return created.(*counter)
+}
+
+func (m *metrics) record(key string, hit bool) {
+	c := m.counterOf(key)
+	if hit {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+}
+
+func (m *metrics) snapshot() map[string]Stats {
+	result := make(map[string]Stats)
+	m.counters.Range(func(key, value interface{}) bool {
+		c := value.(*counter)
+		result[key.(string)] = Stats{
+			Hits:   atomic.LoadUint64(&c.hits),
+			Misses: atomic.LoadUint64(&c.misses),
+		}
+		return true
+	})
+	return result
+}
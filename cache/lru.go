@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LRU 프로세스 안에서 동작하는 캐시, capacity를 넘으면 가장 오래 안 쓴 값부터 버림
+type LRU struct {
+	mutex    sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List // 앞쪽일수록 최근에 사용됨
+	items    map[string]*list.Element
+	metrics  metrics
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRU creates an in-process cache holding at most capacity values for ttl each
+func NewLRU(capacity int, ttl time.Duration) *LRU {
+	return &LRU{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (cache *LRU) Get(key string) ([]byte, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, ok := cache.items[key]
+	if ok && time.Now().After(element.Value.(*lruEntry).expiresAt) {
+		cache.remove(element)
+		ok = false
+	}
+	cache.metrics.record(key, ok)
+	if !ok {
+		return nil, false
+	}
+
+	cache.order.MoveToFront(element)
+	return element.Value.(*lruEntry).value, true
+}
+
+func (cache *LRU) Set(key string, value []byte) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	expiresAt := time.Now().Add(cache.ttl)
+	if element, ok := cache.items[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		cache.order.MoveToFront(element)
+		return
+	}
+
+	cache.items[key] = cache.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for cache.order.Len() > cache.capacity {
+		cache.remove(cache.order.Back())
+	}
+}
+
+func (cache *LRU) Delete(keys ...string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for _, key := range keys {
+		if element, ok := cache.items[key]; ok {
+			cache.remove(element)
+		}
+	}
+}
+
+func (cache *LRU) DeletePrefix(prefix string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for key, element := range cache.items {
+		if strings.HasPrefix(key, prefix) {
+			cache.remove(element)
+		}
+	}
+}
+
+func (cache *LRU) Stats() map[string]Stats {
+	return cache.metrics.snapshot()
+}
+
+func (cache *LRU) remove(element *list.Element) {
+	cache.order.Remove(element)
+	delete(cache.items, element.Value.(*lruEntry).key)
+}
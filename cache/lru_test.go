@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRU(2, time.Minute)
+	cache.Set("menu:1", []byte("a"))
+	cache.Set("menu:2", []byte("b"))
+
+	// menu:1을 읽었으므로 가장 오래 안 쓴 값은 menu:2가 됨
+	if _, ok := cache.Get("menu:1"); !ok {
+		t.Fatal("menu:1 should be cached")
+	}
+	cache.Set("menu:3", []byte("c"))
+
+	for key, want := range map[string]bool{"menu:1": true, "menu:2": false, "menu:3": true} {
+		if _, ok := cache.Get(key); ok != want {
+			t.Errorf("Get(%q) cached = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestLRUSetExistingKeyDoesNotEvict(t *testing.T) {
+	cache := NewLRU(2, time.Minute)
+	cache.Set("menu:1", []byte("a"))
+	cache.Set("menu:2", []byte("b"))
+	cache.Set("menu:1", []byte("c"))
+
+	value, ok := cache.Get("menu:1")
+	if !ok || string(value) != "c" {
+		t.Errorf("Get(menu:1) = %q, %v, want \"c\", true", value, ok)
+	}
+	if _, ok := cache.Get("menu:2"); !ok {
+		t.Error("menu:2 should still be cached")
+	}
+}
+
+func TestLRUExpiresAfterTTL(t *testing.T) {
+	cache := NewLRU(10, 20*time.Millisecond)
+	cache.Set("account:id:1", []byte("a"))
+	if _, ok := cache.Get("account:id:1"); !ok {
+		t.Fatal("value should be cached before the ttl")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := cache.Get("account:id:1"); ok {
+		t.Error("value should expire after the ttl")
+	}
+	if len(cache.items) != 0 || cache.order.Len() != 0 {
+		t.Errorf("expired value should be removed, %d items left", len(cache.items))
+	}
+}
+
+func TestLRUDeletePrefix(t *testing.T) {
+	cache := NewLRU(10, time.Minute)
+	cache.Set("menu:school:A:1", []byte("a"))
+	cache.Set("menu:id:1", []byte("b"))
+	cache.Set("events:1", []byte("c"))
+
+	cache.DeletePrefix("menu:")
+
+	for key, want := range map[string]bool{"menu:school:A:1": false, "menu:id:1": false, "events:1": true} {
+		if _, ok := cache.Get(key); ok != want {
+			t.Errorf("Get(%q) cached = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestLRUStatsByNamespace(t *testing.T) {
+	cache := NewLRU(10, time.Minute)
+	cache.Set("menu:1", []byte("a"))
+	cache.Get("menu:1")
+	cache.Get("menu:2")
+	cache.Get("events:1")
+
+	stats := cache.Stats()
+	if stats["menu"] != (Stats{Hits: 1, Misses: 1}) {
+		t.Errorf("menu stats = %+v, want 1 hit and 1 miss", stats["menu"])
+	}
+	if stats["events"] != (Stats{Hits: 0, Misses: 1}) {
+		t.Errorf("events stats = %+v, want 1 miss", stats["events"])
+	}
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"log"
+	"time"
+)
+
+// redisTimeout Redis가 느리면 캐시를 건너뛰고 DB에서 바로 읽는 게 나음
+const redisTimeout = 200 * time.Millisecond
+
+// Redis 여러 서버가 같이 쓰는 캐시
+// Redis에 문제가 생기면 에러를 로그로 남기고 캐시 실패로 처리함
+type Redis struct {
+	client  *redis.Client
+	ttl     time.Duration
+	prefix  string
+	metrics metrics
+}
+
+// NewRedis connects to the Redis server at addr; every key is stored under prefix
+func NewRedis(addr string, password string, database int, prefix string, ttl time.Duration) (*Redis, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       database,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := client.Ping(ctx).Err()
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	return &Redis{client: client, ttl: ttl, prefix: prefix}, nil
+}
+
+func (cache *Redis) Get(key string) ([]byte, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	value, err := cache.client.Get(ctx, cache.prefix+key).Bytes()
+	if err != nil && err != redis.Nil {
+		log.Printf("Error reading %s from redis: %s", key, err.Error())
+	}
+	hit := err == nil
+	cache.metrics.record(key, hit)
+	return value, hit
+}
+
+func (cache *Redis) Set(key string, value []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	err := cache.client.Set(ctx, cache.prefix+key, value, cache.ttl).Err()
+	if err != nil {
+		log.Printf("Error writing %s to redis: %s", key, err.Error())
+	}
+}
+
+func (cache *Redis) Delete(keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = cache.prefix + key
+	}
+	err := cache.client.Del(ctx, prefixed...).Err()
+	if err != nil {
+		log.Printf("Error deleting %v from redis: %s", keys, err.Error())
+	}
+}
+
+func (cache *Redis) DeletePrefix(prefix string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	iter := cache.client.Scan(ctx, 0, cache.prefix+prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		err := cache.client.Del(ctx, iter.Val()).Err()
+		if err != nil {
+			log.Printf("Error deleting %s from redis: %s", iter.Val(), err.Error())
+		}
+	}
+	if err := iter.Err(); err != nil {
+		log.Printf("Error scanning %s from redis: %s", prefix, err.Error())
+	}
+}
+
+func (cache *Redis) Stats() map[string]Stats {
+	return cache.metrics.snapshot()
+}
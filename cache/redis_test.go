@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestRedis(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	cache, err := NewRedis(server.Addr(), "", 0, "schoolapp:", time.Minute)
+	if err != nil {
+		t.Fatalf("NewRedis: %s", err.Error())
+	}
+	t.Cleanup(func() {
+		_ = cache.client.Close()
+	})
+	return cache, server
+}
+
+func TestRedisSetStoresUnderPrefixWithTTL(t *testing.T) {
+	cache, server := newTestRedis(t)
+	cache.Set("menu:1", []byte("a"))
+
+	value, err := server.Get("schoolapp:menu:1")
+	if err != nil || value != "a" {
+		t.Fatalf("stored value = %q, %v, want \"a\"", value, err)
+	}
+	if ttl := server.TTL("schoolapp:menu:1"); ttl != time.Minute {
+		t.Errorf("ttl = %s, want %s", ttl, time.Minute)
+	}
+
+	server.FastForward(time.Minute + time.Second)
+	if _, ok := cache.Get("menu:1"); ok {
+		t.Error("value should expire after the ttl")
+	}
+}
+
+func TestRedisDeletePrefixScansEveryPage(t *testing.T) {
+	cache, server := newTestRedis(t)
+
+	// SCAN은 한번에 100개씩 보므로 여러 페이지에 걸치게 만듦
+	for i := 0; i < 250; i++ {
+		cache.Set("menu:school:A:"+strconv.Itoa(i), []byte("x"))
+	}
+	cache.Set("events:1", []byte("y"))
+	// 다른 앱이 같은 Redis에 넣은 키는 건드리지 않아야 함
+	if err := server.Set("otherapp:menu:1", "z"); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.DeletePrefix("menu:")
+
+	for _, key := range server.Keys() {
+		if key != "schoolapp:events:1" && key != "otherapp:menu:1" {
+			t.Errorf("key %q should have been deleted", key)
+		}
+	}
+	if !server.Exists("schoolapp:events:1") || !server.Exists("otherapp:menu:1") {
+		t.Errorf("keys outside the prefix were deleted, left %v", server.Keys())
+	}
+}
+
+func TestRedisDeleteAndStats(t *testing.T) {
+	cache, _ := newTestRedis(t)
+	cache.Set("account:id:1", []byte("a"))
+	cache.Set("account:email:a", []byte("b"))
+
+	cache.Delete("account:id:1", "account:email:a")
+	if _, ok := cache.Get("account:id:1"); ok {
+		t.Error("account:id:1 should be deleted")
+	}
+	cache.Set("account:id:2", []byte("c"))
+	if value, ok := cache.Get("account:id:2"); !ok || string(value) != "c" {
+		t.Errorf("Get(account:id:2) = %q, %v, want \"c\", true", value, ok)
+	}
+
+	if stats := cache.Stats()["account"]; stats != (Stats{Hits: 1, Misses: 1}) {
+		t.Errorf("account stats = %+v, want 1 hit and 1 miss", stats)
+	}
+}
+
+func TestRedisUnavailableIsAMiss(t *testing.T) {
+	cache, server := newTestRedis(t)
+	cache.Set("menu:1", []byte("a"))
+	server.Close()
+
+	if _, ok := cache.Get("menu:1"); ok {
+		t.Error("Get should miss when redis is down")
+	}
+}
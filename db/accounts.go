@@ -0,0 +1,449 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/models"
+	"strings"
+)
+
+// accountColumns 시간표와 친구 목록은 student_timetable_entries, friendships 테이블에 따로 저장됨
+const accountColumns = "id, user_id, name, email, password, permission_level, school_id, timetable_is_public, grade, class, number, checklist_id, version"
+
+type sqlAccountStore struct {
+	q ctxQuerier
+}
+
+func scanFlatAccount(row scanner) (*models.FlatAccount, error) {
+	var flataccount models.FlatAccount
+	err := row.Scan(&flataccount.DbId, &flataccount.UserId, &flataccount.Name, &flataccount.Email, &flataccount.Password, &flataccount.PermissionLevel, &flataccount.SchoolId, &flataccount.TimeTableIsPublic, &flataccount.Grade, &flataccount.Class, &flataccount.Number, &flataccount.ChecklistId, &flataccount.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &flataccount, nil
+}
+
+// restore 계정 행에 시간표와 친구 목록을 채워서 Account로 변환함
+// 트랜잭션 안에서는 열려 있는 rows가 없을 때만 호출해야 함
+func (s sqlAccountStore) restore(flataccount *models.FlatAccount) (*models.Account, error) {
+	if flataccount.PermissionLevel == models.STUDENT {
+		entries, err := s.getTimetableEntryIds(flataccount.DbId)
+		if err != nil {
+			return nil, err
+		}
+		friends, err := s.getFriendIds(flataccount.DbId)
+		if err != nil {
+			return nil, err
+		}
+		flataccount.TimeTableEntries = entries
+		flataccount.Friends = friends
+	}
+
+	user, err := flataccount.Restore()
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (s sqlAccountStore) getTimetableEntryIds(id models.DbId) ([]models.DbId, error) {
+	query := "SELECT entry_id FROM student_timetable_entries WHERE account_id = ? ORDER BY entry_id"
+
+	rows, err := s.q.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	entries := make([]models.DbId, 0)
+	for rows.Next() {
+		var entry models.DbId
+		if err := rows.Scan(&entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (s sqlAccountStore) getFriendIds(id models.DbId) ([]uuid.UUID, error) {
+	query := "SELECT a.user_id FROM friendships f JOIN accounts a ON a.id = f.friend_id WHERE f.account_id = ? ORDER BY a.id"
+
+	rows, err := s.q.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	friends := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		friend, err := uuid.FromBytes(raw)
+		if err != nil {
+			return nil, err
+		}
+		friends = append(friends, friend)
+	}
+	return friends, rows.Err()
+}
+
+// saveRelations 계정의 시간표와 친구 목록을 통째로 다시 씀
+func (s sqlAccountStore) saveRelations(flataccount *models.FlatAccount) error {
+	_, err := s.q.Exec("DELETE FROM student_timetable_entries WHERE account_id = ?", flataccount.DbId)
+	if err != nil {
+		return err
+	}
+	for _, entry := range flataccount.TimeTableEntries {
+		_, err = s.q.Exec("INSERT INTO student_timetable_entries (account_id, entry_id) VALUES (?, ?)", flataccount.DbId, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.q.Exec("DELETE FROM friendships WHERE account_id = ?", flataccount.DbId)
+	if err != nil {
+		return err
+	}
+	for _, friend := range flataccount.Friends {
+		_, err = s.q.Exec("INSERT INTO friendships (account_id, friend_id) SELECT ?, id FROM accounts WHERE user_id = ?", flataccount.DbId, friend[:])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queryAccounts rows를 모두 읽고 닫은 다음에 시간표와 친구 목록을 채움
+func (s sqlAccountStore) queryAccounts(query string, args ...interface{}) ([]models.Account, error) {
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var flats []*models.FlatAccount
+	for rows.Next() {
+		flat, err := scanFlatAccount(rows)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		flats = append(flats, flat)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var accounts []models.Account
+	for _, flat := range flats {
+		account, err := s.restore(flat)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+
+	return accounts, nil
+}
+
+// GetAccountByEmail returns a user by Email
+func GetAccountByEmail(Email *string) (*models.Account, error) {
+	return stores.Accounts.GetAccountByEmail(Email)
+}
+
+func (s sqlAccountStore) GetAccountByEmail(Email *string) (*models.Account, error) {
+	// Prepare query
+	query := "SELECT " + accountColumns + " FROM accounts WHERE email = ?"
+
+	// Execute query
+	row := s.q.QueryRow(query, Email)
+
+	// Scan row into account object
+	flataccount, err := scanFlatAccount(row)
+	if err != nil {
+		return nil, err
+	}
+	return s.restore(flataccount)
+}
+
+// GetAccountById returns a student by ID
+func GetAccountById(id *uuid.UUID) (*models.Account, error) {
+	return stores.Accounts.GetAccountById(id)
+}
+
+func (s sqlAccountStore) GetAccountById(id *uuid.UUID) (*models.Account, error) {
+	// Prepare query
+	query := "SELECT " + accountColumns + " FROM accounts WHERE user_id = ?"
+
+	// Execute query
+	row := s.q.QueryRow(query, id[:])
+
+	// Scan row into account object
+	flataccount, err := scanFlatAccount(row)
+	if err != nil {
+		return nil, err
+	}
+	return s.restore(flataccount)
+}
+
+// LockAccount 같은 계정에 대한 시간표 변경이 한번에 하나씩만 진행되도록 트랜잭션이 끝날 때까지 행을 잠그고 다시 읽음
+// WithTx 안에서만 의미가 있으므로 패키지 함수는 따로 두지 않음
+func (s sqlAccountStore) LockAccount(id *uuid.UUID) (*models.Account, error) {
+	query := "SELECT " + accountColumns + " FROM accounts WHERE user_id = ? FOR UPDATE"
+	flataccount, err := scanFlatAccount(s.q.QueryRow(query, id[:]))
+	if err != nil {
+		return nil, err
+	}
+	return s.restore(flataccount)
+}
+
+// GetAccountByDbId returns an account by its database ID
+func GetAccountByDbId(id models.DbId) (*models.Account, error) {
+	return stores.Accounts.GetAccountByDbId(id)
+}
+
+func (s sqlAccountStore) GetAccountByDbId(id models.DbId) (*models.Account, error) {
+	query := "SELECT " + accountColumns + " FROM accounts WHERE id = ?"
+
+	flataccount, err := scanFlatAccount(s.q.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+	return s.restore(flataccount)
+}
+
+// GetAllAccounts returns every account
+func GetAllAccounts() ([]models.Account, error) {
+	return stores.Accounts.GetAllAccounts()
+}
+
+func (s sqlAccountStore) GetAllAccounts() ([]models.Account, error) {
+	return s.queryAccounts("SELECT " + accountColumns + " FROM accounts")
+}
+
+// GetStudentsOfTimetableEntry returns every student enrolled in a lesson
+func GetStudentsOfTimetableEntry(id models.DbId) ([]models.Account, error) {
+	return stores.Accounts.GetStudentsOfTimetableEntry(id)
+}
+
+func (s sqlAccountStore) GetStudentsOfTimetableEntry(id models.DbId) ([]models.Account, error) {
+	query := "SELECT " + accountColumns + " FROM accounts WHERE id IN (SELECT account_id FROM student_timetable_entries WHERE entry_id = ?) ORDER BY grade, class, number"
+	return s.queryAccounts(query, id)
+}
+
+// GetAccountsWithFriend returns every account that has the given user in their friends list
+func GetAccountsWithFriend(id *uuid.UUID) ([]models.Account, error) {
+	return stores.Accounts.GetAccountsWithFriend(id)
+}
+
+func (s sqlAccountStore) GetAccountsWithFriend(id *uuid.UUID) ([]models.Account, error) {
+	query := "SELECT " + accountColumns + " FROM accounts WHERE id IN (SELECT f.account_id FROM friendships f JOIN accounts a ON a.id = f.friend_id WHERE a.user_id = ?)"
+	return s.queryAccounts(query, id[:])
+}
+
+// GetAccountNames returns the names of the given accounts keyed by UUID
+// 없는 계정은 결과에서 빠짐
+func GetAccountNames(ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	return stores.Accounts.GetAccountNames(ids)
+}
+
+func (s sqlAccountStore) GetAccountNames(ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	names := make(map[uuid.UUID]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	placeholders := strings.Repeat(", ?", len(ids))[2:]
+	args := make([]interface{}, len(ids))
+	for i := range ids {
+		args[i] = ids[i][:]
+	}
+
+	query := "SELECT user_id, name FROM accounts WHERE user_id IN (" + placeholders + ")"
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	for rows.Next() {
+		var id uuid.UUID
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		names[id] = name
+	}
+	return names, rows.Err()
+}
+
+// CreateAccount creates a new student
+func CreateAccount(account *models.Account) (models.DbId, error) {
+	var id models.DbId
+	err := WithTx(context.Background(), func(tx Stores) error {
+		var err error
+		id, err = tx.Accounts.CreateAccount(account)
+		return err
+	})
+	return id, err
+}
+
+func (s sqlAccountStore) CreateAccount(account *models.Account) (models.DbId, error) {
+	// Prepare query
+	query := "INSERT INTO accounts (user_id, name, email, password, permission_level, school_id, timetable_is_public, grade, class, number, checklist_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	var result sql.Result
+	var err error
+
+	flataccount, err := account.ToSql()
+	if err != nil {
+		return models.DbId(0), err
+	}
+
+	result, err = s.q.Exec(query, flataccount.UserId, flataccount.Name, flataccount.Email, flataccount.Password, flataccount.PermissionLevel, flataccount.SchoolId, flataccount.TimeTableIsPublic, flataccount.Grade, flataccount.Class, flataccount.Number, flataccount.ChecklistId)
+
+	if err != nil {
+		return 0, err
+	}
+
+	// Get the ID of the newly created account
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	account.DbId = models.DbId(id)
+	account.Version = 1
+	flataccount.DbId = account.DbId
+
+	err = s.saveRelations(&flataccount)
+	if err != nil {
+		return 0, err
+	}
+
+	return account.DbId, nil
+}
+
+// UpdateAccount updates a student
+// account.Version이 DB에 저장된 값과 다르면 ErrVersionConflict를 반환함
+func UpdateAccount(account *models.Account) error {
+	return WithTx(context.Background(), func(tx Stores) error {
+		return tx.Accounts.UpdateAccount(account)
+	})
+}
+
+func (s sqlAccountStore) UpdateAccount(account *models.Account) error {
+	// Prepare query
+	query := "UPDATE accounts SET user_id = ?, name = ?, email = ?, password = ?, permission_level = ?, school_id = ?, timetable_is_public = ?, grade = ?, class = ?, number = ?, checklist_id = ?, version = version + 1 WHERE id = ? AND version = ?"
+
+	flataccount, err := account.ToSql()
+	if err != nil {
+		return err
+	}
+	// Execute query
+	result, err := s.q.Exec(query, flataccount.UserId, flataccount.Name, flataccount.Email, flataccount.Password, flataccount.PermissionLevel, flataccount.SchoolId, flataccount.TimeTableIsPublic, flataccount.Grade, flataccount.Class, flataccount.Number, flataccount.ChecklistId, flataccount.DbId, flataccount.Version)
+	if err != nil {
+		return err
+	}
+	err = checkVersioned(result)
+	if err != nil {
+		return err
+	}
+	account.Version++
+
+	return s.saveRelations(&flataccount)
+}
+
+// AddTimetableEntry enrolls a student in a lesson
+func AddTimetableEntry(account *models.Account, entryId models.DbId) error {
+	return stores.Accounts.AddTimetableEntry(account, entryId)
+}
+
+func (s sqlAccountStore) AddTimetableEntry(account *models.Account, entryId models.DbId) error {
+	// 이미 듣는 수업이면 아무것도 안 함
+	result, err := s.q.Exec("INSERT IGNORE INTO student_timetable_entries (account_id, entry_id) VALUES (?, ?)", account.DbId, entryId)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return err
+	}
+	return s.bumpVersion(account)
+}
+
+// RemoveTimetableEntry drops a lesson from a student's timetable
+// 듣지 않는 수업이면 sql.ErrNoRows를 반환함
+func RemoveTimetableEntry(account *models.Account, entryId models.DbId) error {
+	return stores.Accounts.RemoveTimetableEntry(account, entryId)
+}
+
+func (s sqlAccountStore) RemoveTimetableEntry(account *models.Account, entryId models.DbId) error {
+	result, err := s.q.Exec("DELETE FROM student_timetable_entries WHERE account_id = ? AND entry_id = ?", account.DbId, entryId)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return s.bumpVersion(account)
+}
+
+// SetTimetableVisibility changes whether a student's timetable is public
+func SetTimetableVisibility(account *models.Account, isPublic bool) error {
+	return stores.Accounts.SetTimetableVisibility(account, isPublic)
+}
+
+func (s sqlAccountStore) SetTimetableVisibility(account *models.Account, isPublic bool) error {
+	_, err := s.q.Exec("UPDATE accounts SET timetable_is_public = ?, version = version + 1 WHERE id = ?", isPublic, account.DbId)
+	if err != nil {
+		return err
+	}
+	account.Version++
+	return nil
+}
+
+// bumpVersion 시간표처럼 다른 테이블에 저장되는 값이 바뀌어도 계정의 ETag가 바뀌도록 version을 올림
+func (s sqlAccountStore) bumpVersion(account *models.Account) error {
+	_, err := s.q.Exec("UPDATE accounts SET version = version + 1 WHERE id = ?", account.DbId)
+	if err != nil {
+		return err
+	}
+	account.Version++
+	return nil
+}
+
+// DeleteAccount deletes a student by ID
+func DeleteAccount(id models.DbId) error {
+	return stores.Accounts.DeleteAccount(id)
+}
+
+func (s sqlAccountStore) DeleteAccount(id models.DbId) error {
+	// Prepare query
+	// student_timetable_entries와 friendships 행은 외래 키의 ON DELETE CASCADE로 같이 삭제됨
+	query := "DELETE FROM accounts WHERE id = ?"
+
+	// Execute query
+	_, err := s.q.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
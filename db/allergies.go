@@ -0,0 +1,221 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"log"
+	"time"
+)
+
+// 알레르기 정보와 알림은 Account에 들어가지 않으므로 계정 캐시를 무효화할 필요가 없음
+
+// AllergySubscriber is a student who wants to be told about allergens in today's meals
+type AllergySubscriber struct {
+	AccountId models.DbId
+	SchoolId  models.SchoolId
+	Profile   models.AllergyProfile
+}
+
+func scanAllergyProfile(allergens string, ingredients string, profile *models.AllergyProfile) error {
+	err := json.Unmarshal([]byte(allergens), &profile.Allergens)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(ingredients), &profile.Ingredients)
+}
+
+// GetAllergyProfile returns a student's allergy profile, which is empty if they never saved one
+func GetAllergyProfile(account *models.Account) (*models.AllergyProfile, error) {
+	return stores.Accounts.GetAllergyProfile(account)
+}
+
+func (s sqlAccountStore) GetAllergyProfile(account *models.Account) (*models.AllergyProfile, error) {
+	profile := models.AllergyProfile{Allergens: make([]models.AllergyType, 0), Ingredients: make([]string, 0)}
+
+	var allergens, ingredients string
+	err := s.q.QueryRow("SELECT allergens, ingredients, notify FROM allergy_profiles WHERE account_id = ?", account.DbId).Scan(&allergens, &ingredients, &profile.Notify)
+	if err == sql.ErrNoRows {
+		return &profile, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = scanAllergyProfile(allergens, ingredients, &profile)
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// SetAllergyProfile replaces a student's allergy profile
+func SetAllergyProfile(account *models.Account, profile *models.AllergyProfile) error {
+	return stores.Accounts.SetAllergyProfile(account, profile)
+}
+
+func (s sqlAccountStore) SetAllergyProfile(account *models.Account, profile *models.AllergyProfile) error {
+	err := utils.ValidateAllergyProfile(profile)
+	if err != nil {
+		return err
+	}
+
+	allergens, err := json.Marshal(profile.Allergens)
+	if err != nil {
+		return err
+	}
+	ingredients, err := json.Marshal(profile.Ingredients)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO allergy_profiles (account_id, allergens, ingredients, notify) VALUES (?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE allergens = VALUES(allergens), ingredients = VALUES(ingredients), notify = VALUES(notify)"
+	_, err = s.q.Exec(query, account.DbId, string(allergens), string(ingredients), profile.Notify)
+	return err
+}
+
+// GetAllergySubscribers returns every student who turned on allergy notifications
+func GetAllergySubscribers() ([]AllergySubscriber, error) {
+	return stores.Accounts.GetAllergySubscribers()
+}
+
+func (s sqlAccountStore) GetAllergySubscribers() ([]AllergySubscriber, error) {
+	query := "SELECT a.id, a.school_id, p.allergens, p.ingredients FROM allergy_profiles p JOIN accounts a ON a.id = p.account_id WHERE p.notify AND a.permission_level = ?"
+
+	rows, err := s.q.Query(query, models.STUDENT)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	subscribers := make([]AllergySubscriber, 0)
+	for rows.Next() {
+		subscriber := AllergySubscriber{Profile: models.AllergyProfile{Notify: true}}
+		var allergens, ingredients string
+		err := rows.Scan(&subscriber.AccountId, &subscriber.SchoolId, &allergens, &ingredients)
+		if err != nil {
+			return nil, err
+		}
+		err = scanAllergyProfile(allergens, ingredients, &subscriber.Profile)
+		if err != nil {
+			return nil, err
+		}
+		subscribers = append(subscribers, subscriber)
+	}
+	return subscribers, rows.Err()
+}
+
+// GetNotifications returns the 50 latest notifications of an account, newest first
+func GetNotifications(account *models.Account) ([]models.Notification, error) {
+	return stores.Accounts.GetNotifications(account)
+}
+
+func (s sqlAccountStore) GetNotifications(account *models.Account) ([]models.Notification, error) {
+	query := "SELECT id, kind, message, created_at, read_at FROM notifications WHERE account_id = ? ORDER BY created_at DESC, id DESC LIMIT 50"
+
+	rows, err := s.q.Query(query, account.DbId)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	notifications := make([]models.Notification, 0)
+	for rows.Next() {
+		var notification models.Notification
+		err := rows.Scan(&notification.ID, &notification.Kind, &notification.Message, &notification.CreatedAt, &notification.ReadAt)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, rows.Err()
+}
+
+// AddNotification puts a notification in an account's inbox
+// dedupKey가 같은 알림이 이미 있으면 아무것도 하지 않으므로 같은 일을 여러 번 알리지 않음
+func AddNotification(accountId models.DbId, dedupKey string, notification *models.Notification) error {
+	return stores.Accounts.AddNotification(accountId, dedupKey, notification)
+}
+
+func (s sqlAccountStore) AddNotification(accountId models.DbId, dedupKey string, notification *models.Notification) error {
+	query := "INSERT IGNORE INTO notifications (account_id, kind, dedup_key, message) VALUES (?, ?, ?, ?)"
+
+	_, err := s.q.Exec(query, accountId, notification.Kind, dedupKey, notification.Message)
+	return err
+}
+
+// ReadNotification marks one of the account's notifications as read
+func ReadNotification(account *models.Account, id models.DbId) error {
+	return stores.Accounts.ReadNotification(account, id)
+}
+
+func (s sqlAccountStore) ReadNotification(account *models.Account, id models.DbId) error {
+	var count int
+	err := s.q.QueryRow("SELECT COUNT(*) FROM notifications WHERE id = ? AND account_id = ?", id, account.DbId).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = s.q.Exec("UPDATE notifications SET read_at = CURRENT_TIMESTAMP WHERE id = ? AND account_id = ? AND read_at IS NULL", id, account.DbId)
+	return err
+}
+
+// NotifyAllergies warns subscribed students about allergens in today's meals of their school
+// 급식마다 한번만 알리므로 여러 번 실행해도 되고, 급식을 늦게 가져와도 다음 실행에서 알림
+func NotifyAllergies(now time.Time) error {
+	subscribers, err := GetAllergySubscribers()
+	if err != nil {
+		return err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	menus := make(map[models.SchoolId][]models.CafeteriaMenu)
+	for _, subscriber := range subscribers {
+		schoolMenus, ok := menus[subscriber.SchoolId]
+		if !ok {
+			schoolMenus, err = GetMenus(subscriber.SchoolId, today, today, "")
+			if err != nil {
+				return err
+			}
+			menus[subscriber.SchoolId] = schoolMenus
+		}
+
+		for _, menu := range schoolMenus {
+			warnings := subscriber.Profile.Check(menu.Dishes)
+			if len(warnings) == 0 {
+				continue
+			}
+			notification := models.Notification{Kind: models.NotificationAllergy, Message: models.AllergyMessage(menu, warnings)}
+			err := AddNotification(subscriber.AccountId, fmt.Sprintf("%s:menu:%d", models.NotificationAllergy, menu.ID), &notification)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// StartAllergyNotifier 오늘 급식의 알레르기 알림을 interval마다 보내는 고루틴을 시작함
+func StartAllergyNotifier(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			err := NotifyAllergies(time.Now())
+			if err != nil {
+				log.Printf("Error sending allergy notifications: %s", err.Error())
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,251 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const bellScheduleColumns = "id, school_id, name, weekdays, is_default, periods, version"
+
+// weekdays 열에는 요일 번호를 "1,3,5"처럼 쉼표로 이어서 저장함
+func formatWeekdays(days []models.Weekday) string {
+	parts := make([]string, len(days))
+	for i, day := range days {
+		parts[i] = strconv.Itoa(int(day))
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseWeekdays(s string) ([]models.Weekday, error) {
+	days := make([]models.Weekday, 0)
+	if s == "" {
+		return days, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		day, err := models.ParseWeekday(part)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func scanBellSchedule(row scanner) (*models.BellSchedule, error) {
+	var schedule models.BellSchedule
+	var weekdays, periods string
+	err := row.Scan(&schedule.ID, &schedule.SchoolId, &schedule.Name, &weekdays, &schedule.IsDefault, &periods, &schedule.Version)
+	if err != nil {
+		return nil, err
+	}
+	schedule.Weekdays, err = parseWeekdays(weekdays)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(periods), &schedule.Periods)
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// GetBellSchedules returns every bell schedule of a school
+func GetBellSchedules(schoolId models.SchoolId) ([]models.BellSchedule, error) {
+	return stores.Schools.GetBellSchedules(schoolId)
+}
+
+func (s sqlSchoolStore) GetBellSchedules(schoolId models.SchoolId) ([]models.BellSchedule, error) {
+	query := "SELECT " + bellScheduleColumns + " FROM bell_schedules WHERE school_id = ? ORDER BY id"
+
+	rows, err := s.q.Query(query, schoolId)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	schedules := make([]models.BellSchedule, 0)
+	for rows.Next() {
+		schedule, err := scanBellSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, rows.Err()
+}
+
+// GetBellSchedule returns a bell schedule by ID
+func GetBellSchedule(id models.DbId) (*models.BellSchedule, error) {
+	return stores.Schools.GetBellSchedule(id)
+}
+
+func (s sqlSchoolStore) GetBellSchedule(id models.DbId) (*models.BellSchedule, error) {
+	query := "SELECT " + bellScheduleColumns + " FROM bell_schedules WHERE id = ?"
+	return scanBellSchedule(s.q.QueryRow(query, id))
+}
+
+// CreateBellSchedule creates a new bell schedule
+// IsDefault면 같은 학교의 다른 기본 시간표는 기본에서 빠지므로 WithTx 안에서 호출하는 것이 좋음
+func CreateBellSchedule(schedule *models.BellSchedule) (models.DbId, error) {
+	return stores.Schools.CreateBellSchedule(schedule)
+}
+
+func (s sqlSchoolStore) CreateBellSchedule(schedule *models.BellSchedule) (models.DbId, error) {
+	err := utils.ValidateBellSchedule(schedule)
+	if err != nil {
+		return 0, err
+	}
+	periods, err := json.Marshal(schedule.Periods)
+	if err != nil {
+		return 0, err
+	}
+
+	query := "INSERT INTO bell_schedules (school_id, name, weekdays, is_default, periods) VALUES (?, ?, ?, ?, ?)"
+
+	result, err := s.q.Exec(query, schedule.SchoolId, schedule.Name, formatWeekdays(schedule.Weekdays), schedule.IsDefault, string(periods))
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	schedule.ID = models.DbId(id)
+	schedule.Version = 1
+
+	return schedule.ID, s.clearOtherDefaults(schedule)
+}
+
+// UpdateBellSchedule updates a bell schedule
+// schedule.Version이 DB에 저장된 값과 다르면 ErrVersionConflict를 반환함
+func UpdateBellSchedule(schedule *models.BellSchedule) error {
+	return stores.Schools.UpdateBellSchedule(schedule)
+}
+
+func (s sqlSchoolStore) UpdateBellSchedule(schedule *models.BellSchedule) error {
+	err := utils.ValidateBellSchedule(schedule)
+	if err != nil {
+		return err
+	}
+	periods, err := json.Marshal(schedule.Periods)
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE bell_schedules SET name = ?, weekdays = ?, is_default = ?, periods = ?, version = version + 1 WHERE id = ? AND version = ?"
+
+	result, err := s.q.Exec(query, schedule.Name, formatWeekdays(schedule.Weekdays), schedule.IsDefault, string(periods), schedule.ID, schedule.Version)
+	if err != nil {
+		return err
+	}
+	err = checkVersioned(result)
+	if err != nil {
+		return err
+	}
+	schedule.Version++
+
+	return s.clearOtherDefaults(schedule)
+}
+
+// clearOtherDefaults 학교마다 기본 시간표는 하나뿐이어야 함
+func (s sqlSchoolStore) clearOtherDefaults(schedule *models.BellSchedule) error {
+	if !schedule.IsDefault {
+		return nil
+	}
+	query := "UPDATE bell_schedules SET is_default = FALSE, version = version + 1 WHERE school_id = ? AND id <> ? AND is_default"
+	_, err := s.q.Exec(query, schedule.SchoolId, schedule.ID)
+	return err
+}
+
+// DeleteBellSchedule deletes a bell schedule and the date overrides that use it
+func DeleteBellSchedule(id models.DbId) error {
+	return stores.Schools.DeleteBellSchedule(id)
+}
+
+func (s sqlSchoolStore) DeleteBellSchedule(id models.DbId) error {
+	// bell_schedule_overrides는 외래 키로 같이 지워짐
+	query := "DELETE FROM bell_schedules WHERE id = ?"
+
+	_, err := s.q.Exec(query, id)
+	return err
+}
+
+// GetBellOverrides returns the date overrides of a school between from and to, inclusive
+func GetBellOverrides(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.BellScheduleOverride, error) {
+	return stores.Schools.GetBellOverrides(schoolId, from, to)
+}
+
+func (s sqlSchoolStore) GetBellOverrides(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.BellScheduleOverride, error) {
+	query := "SELECT id, school_id, date, schedule_id, note FROM bell_schedule_overrides WHERE school_id = ? AND date BETWEEN ? AND ? ORDER BY date"
+
+	rows, err := s.q.Query(query, schoolId, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	overrides := make([]models.BellScheduleOverride, 0)
+	for rows.Next() {
+		var override models.BellScheduleOverride
+		var date time.Time
+		err := rows.Scan(&override.ID, &override.SchoolId, &date, &override.ScheduleId, &override.Note)
+		if err != nil {
+			return nil, err
+		}
+		override.Date = date.Format("2006-01-02")
+		overrides = append(overrides, override)
+	}
+	return overrides, rows.Err()
+}
+
+// SetBellOverride makes a school use another bell schedule on a date, replacing any earlier override
+func SetBellOverride(override *models.BellScheduleOverride) error {
+	return stores.Schools.SetBellOverride(override)
+}
+
+func (s sqlSchoolStore) SetBellOverride(override *models.BellScheduleOverride) error {
+	query := "INSERT INTO bell_schedule_overrides (school_id, date, schedule_id, note) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), schedule_id = VALUES(schedule_id), note = VALUES(note)"
+
+	result, err := s.q.Exec(query, override.SchoolId, override.Date, override.ScheduleId, override.Note)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	override.ID = models.DbId(id)
+	return nil
+}
+
+// DeleteBellOverride removes the override of a date so the school's usual schedule applies again
+func DeleteBellOverride(schoolId models.SchoolId, date time.Time) error {
+	return stores.Schools.DeleteBellOverride(schoolId, date)
+}
+
+func (s sqlSchoolStore) DeleteBellOverride(schoolId models.SchoolId, date time.Time) error {
+	query := "DELETE FROM bell_schedule_overrides WHERE school_id = ? AND date = ?"
+
+	result, err := s.q.Exec(query, schoolId, date.Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,433 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/cache"
+	"github.com/username/schoolapp/models"
+	"strconv"
+	"time"
+)
+
+// storeCache EnableCache로 설정되기 전까지는 nil이고, 그동안은 캐시 없이 DB에서 바로 읽음
+var storeCache cache.Cache
+
+// EnableCache puts a read-through cache in front of the account, menu, event and school stores
+// Connect 다음에 호출해야 함
+func EnableCache(c cache.Cache) {
+	storeCache = c
+	stores = cachedStores(newStores(context.Background(), db), cacheLayer{cache: c, invalidator: c, reads: true})
+}
+
+// CacheStats returns hit and miss counts per key namespace, or nil when caching is disabled
+func CacheStats() map[string]cache.Stats {
+	if storeCache == nil {
+		return nil
+	}
+	return storeCache.Stats()
+}
+
+type invalidator interface {
+	Delete(keys ...string)
+	DeletePrefix(prefix string)
+}
+
+// pendingInvalidations 트랜잭션 안에서 생긴 무효화를 모아 뒀다가 커밋한 뒤에 적용함
+// 커밋 전에 지우면 다른 요청이 커밋 전 값을 다시 캐시에 넣을 수 있음
+type pendingInvalidations struct {
+	keys     []string
+	prefixes []string
+}
+
+func (pending *pendingInvalidations) Delete(keys ...string) {
+	pending.keys = append(pending.keys, keys...)
+}
+
+func (pending *pendingInvalidations) DeletePrefix(prefix string) {
+	pending.prefixes = append(pending.prefixes, prefix)
+}
+
+func (pending *pendingInvalidations) apply(target invalidator) {
+	target.Delete(pending.keys...)
+	for _, prefix := range pending.prefixes {
+		target.DeletePrefix(prefix)
+	}
+}
+
+// cacheLayer reads가 false면 캐시를 읽지 않고 무효화만 함 (트랜잭션 안에서는 항상 DB를 읽어야 하므로)
+type cacheLayer struct {
+	cache       cache.Cache
+	invalidator invalidator
+	reads       bool
+}
+
+// cachedStores 캐시를 쓰는 스토어만 감싸고 나머지는 그대로 둠
+// 감싼 스토어에 쓰기 메소드를 새로 추가하면 여기서도 무효화하도록 오버라이드해야 함!
+func cachedStores(inner Stores, layer cacheLayer) Stores {
+	inner.Accounts = cachedAccountStore{inner.Accounts, layer}
+	inner.Menus = cachedMenuStore{inner.Menus, layer}
+	inner.Events = cachedEventStore{inner.Events, layer}
+	inner.Schools = cachedSchoolStore{inner.Schools, layer}
+	return inner
+}
+
+// readThrough 캐시에 있으면 캐시에서, 없으면 load로 읽어서 캐시에 넣음
+// 에러는 캐시하지 않음
+func readThrough[T any](layer cacheLayer, key string, load func() (*T, error)) (*T, error) {
+	if !layer.reads {
+		return load()
+	}
+
+	if raw, ok := layer.cache.Get(key); ok {
+		var value T
+		if json.Unmarshal(raw, &value) == nil {
+			return &value, nil
+		}
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if raw, err := json.Marshal(value); err == nil {
+		layer.cache.Set(key, raw)
+	}
+	return value, nil
+}
+
+// Accounts
+// PermissionInfo는 인터페이스라 JSON으로 복원할 수 없어서 FlatAccount 형태로 캐시함
+
+type cachedAccountStore struct {
+	AccountStore
+	layer cacheLayer
+}
+
+func accountKey(id uuid.UUID) string {
+	return "account:id:" + id.String()
+}
+
+func accountEmailKey(email string) string {
+	return "account:email:" + email
+}
+
+func (s cachedAccountStore) GetAccountById(id *uuid.UUID) (*models.Account, error) {
+	flat, err := readThrough(s.layer, accountKey(*id), func() (*models.FlatAccount, error) {
+		account, err := s.AccountStore.GetAccountById(id)
+		if err != nil {
+			return nil, err
+		}
+		flat, err := account.ToSql()
+		return &flat, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := flat.Restore()
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetAccountByEmail 이메일은 UUID만 캐시하고 계정은 GetAccountById의 캐시를 씀
+// 이메일이 바뀌었으면 예전 이메일의 UUID가 남아 있을 수 있으니 한번 더 확인함
+func (s cachedAccountStore) GetAccountByEmail(email *string) (*models.Account, error) {
+	if s.layer.reads {
+		if raw, ok := s.layer.cache.Get(accountEmailKey(*email)); ok {
+			if id, err := uuid.ParseBytes(raw); err == nil {
+				account, err := s.GetAccountById(&id)
+				if err == nil && account.Email == *email {
+					return account, nil
+				}
+			}
+		}
+	}
+
+	account, err := s.AccountStore.GetAccountByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if s.layer.reads {
+		s.layer.cache.Set(accountEmailKey(*email), []byte(account.UserId.String()))
+	}
+	return account, nil
+}
+
+func (s cachedAccountStore) CreateAccount(account *models.Account) (models.DbId, error) {
+	id, err := s.AccountStore.CreateAccount(account)
+	if err != nil {
+		return id, err
+	}
+	s.layer.invalidator.Delete(accountKey(account.UserId), accountEmailKey(account.Email))
+	return id, nil
+}
+
+func (s cachedAccountStore) UpdateAccount(account *models.Account) error {
+	err := s.AccountStore.UpdateAccount(account)
+	if err != nil {
+		return err
+	}
+	s.layer.invalidator.Delete(accountKey(account.UserId), accountEmailKey(account.Email))
+	return nil
+}
+
+func (s cachedAccountStore) AddTimetableEntry(account *models.Account, entryId models.DbId) error {
+	err := s.AccountStore.AddTimetableEntry(account, entryId)
+	if err == nil {
+		s.layer.invalidator.Delete(accountKey(account.UserId))
+	}
+	return err
+}
+
+func (s cachedAccountStore) RemoveTimetableEntry(account *models.Account, entryId models.DbId) error {
+	err := s.AccountStore.RemoveTimetableEntry(account, entryId)
+	if err == nil {
+		s.layer.invalidator.Delete(accountKey(account.UserId))
+	}
+	return err
+}
+
+func (s cachedAccountStore) EnrollClass(schoolId models.SchoolId, grade int, class int, entryIds []models.DbId) ([]uuid.UUID, error) {
+	enrolled, err := s.AccountStore.EnrollClass(schoolId, grade, class, entryIds)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(enrolled))
+	for i, id := range enrolled {
+		keys[i] = accountKey(id)
+	}
+	s.layer.invalidator.Delete(keys...)
+	return enrolled, nil
+}
+
+func (s cachedAccountStore) UnenrollEntries(entryIds []models.DbId) ([]uuid.UUID, error) {
+	unenrolled, err := s.AccountStore.UnenrollEntries(entryIds)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(unenrolled))
+	for i, id := range unenrolled {
+		keys[i] = accountKey(id)
+	}
+	s.layer.invalidator.Delete(keys...)
+	return unenrolled, nil
+}
+
+func (s cachedAccountStore) SetTimetableVisibility(account *models.Account, isPublic bool) error {
+	err := s.AccountStore.SetTimetableVisibility(account, isPublic)
+	if err == nil {
+		s.layer.invalidator.Delete(accountKey(account.UserId))
+	}
+	return err
+}
+
+// DeleteAccount 지워진 계정을 친구로 둔 계정들의 친구 목록도 바뀌므로 같이 무효화함
+func (s cachedAccountStore) DeleteAccount(id models.DbId) error {
+	account, err := s.AccountStore.GetAccountByDbId(id)
+	if err != nil {
+		return s.AccountStore.DeleteAccount(id)
+	}
+	befriended, err := s.AccountStore.GetAccountsWithFriend(&account.UserId)
+	if err != nil {
+		return err
+	}
+
+	err = s.AccountStore.DeleteAccount(id)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{accountKey(account.UserId), accountEmailKey(account.Email)}
+	for _, other := range befriended {
+		keys = append(keys, accountKey(other.UserId))
+	}
+	s.layer.invalidator.Delete(keys...)
+	return nil
+}
+
+// Menus
+// 급식은 하루에 한번 바뀌는 정도라 쓰기가 있으면 급식 캐시를 통째로 비움
+
+const menuPrefix = "menu:"
+
+type cachedMenuStore struct {
+	MenuStore
+	layer cacheLayer
+}
+
+func (s cachedMenuStore) GetMenuByID(id models.DbId) (*models.CafeteriaMenu, error) {
+	key := menuPrefix + "id:" + strconv.FormatInt(int64(id), 10)
+	return readThrough(s.layer, key, func() (*models.CafeteriaMenu, error) {
+		return s.MenuStore.GetMenuByID(id)
+	})
+}
+
+func (s cachedMenuStore) GetMenus(schoolId models.SchoolId, from time.Time, to time.Time, mealName string) ([]models.CafeteriaMenu, error) {
+	key := menuPrefix + "school:" + string(schoolId) + ":" + from.Format("2006-01-02") + ":" + to.Format("2006-01-02") + ":" + mealName
+	menus, err := readThrough(s.layer, key, func() (*[]models.CafeteriaMenu, error) {
+		menus, err := s.MenuStore.GetMenus(schoolId, from, to, mealName)
+		return &menus, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return *menus, nil
+}
+
+func (s cachedMenuStore) CreateMenu(menu *models.CafeteriaMenu) (models.DbId, error) {
+	id, err := s.MenuStore.CreateMenu(menu)
+	if err == nil {
+		s.layer.invalidator.DeletePrefix(menuPrefix)
+	}
+	return id, err
+}
+
+func (s cachedMenuStore) UpdateMenu(menu *models.CafeteriaMenu) error {
+	err := s.MenuStore.UpdateMenu(menu)
+	if err == nil {
+		s.layer.invalidator.DeletePrefix(menuPrefix)
+	}
+	return err
+}
+
+func (s cachedMenuStore) ImportMenu(menu *models.CafeteriaMenu) (ImportAction, error) {
+	action, err := s.MenuStore.ImportMenu(menu)
+	if err == nil && (action == ImportCreated || action == ImportUpdated) {
+		s.layer.invalidator.DeletePrefix(menuPrefix)
+	}
+	return action, err
+}
+
+func (s cachedMenuStore) DeleteMenu(id models.DbId, version int64) error {
+	err := s.MenuStore.DeleteMenu(id, version)
+	if err == nil {
+		s.layer.invalidator.DeletePrefix(menuPrefix)
+	}
+	return err
+}
+
+func (s cachedMenuStore) RestoreMenu(id models.DbId) error {
+	err := s.MenuStore.RestoreMenu(id)
+	if err == nil {
+		s.layer.invalidator.DeletePrefix(menuPrefix)
+	}
+	return err
+}
+
+// Events
+
+const eventsPrefix = "events:"
+
+type cachedEventStore struct {
+	EventStore
+	layer cacheLayer
+}
+
+func (s cachedEventStore) GetAllEvents() (*models.Events, error) {
+	return readThrough(s.layer, eventsPrefix+"all", func() (*models.Events, error) {
+		return s.EventStore.GetAllEvents()
+	})
+}
+
+func (s cachedEventStore) GetEventsByMonth(month int) (*models.Events, error) {
+	key := eventsPrefix + "month:" + strconv.Itoa(month)
+	return readThrough(s.layer, key, func() (*models.Events, error) {
+		return s.EventStore.GetEventsByMonth(month)
+	})
+}
+
+func (s cachedEventStore) GetSchoolEventsByMonth(schoolId models.SchoolId, month int) (*models.Events, error) {
+	key := eventsPrefix + "school:" + string(schoolId) + ":month:" + strconv.Itoa(month)
+	return readThrough(s.layer, key, func() (*models.Events, error) {
+		return s.EventStore.GetSchoolEventsByMonth(schoolId, month)
+	})
+}
+
+func (s cachedEventStore) GetEventsById(id models.DbId) (*models.Events, error) {
+	key := eventsPrefix + "id:" + strconv.FormatInt(int64(id), 10)
+	return readThrough(s.layer, key, func() (*models.Events, error) {
+		return s.EventStore.GetEventsById(id)
+	})
+}
+
+func (s cachedEventStore) CreateEvents(events *models.Events) (models.DbId, error) {
+	id, err := s.EventStore.CreateEvents(events)
+	if err == nil {
+		s.layer.invalidator.DeletePrefix(eventsPrefix)
+	}
+	return id, err
+}
+
+func (s cachedEventStore) DeleteEvents(id models.DbId, version int64) error {
+	err := s.EventStore.DeleteEvents(id, version)
+	if err == nil {
+		s.layer.invalidator.DeletePrefix(eventsPrefix)
+	}
+	return err
+}
+
+func (s cachedEventStore) RestoreEvents(id models.DbId) error {
+	err := s.EventStore.RestoreEvents(id)
+	if err == nil {
+		s.layer.invalidator.DeletePrefix(eventsPrefix)
+	}
+	return err
+}
+
+// Schools
+
+type cachedSchoolStore struct {
+	SchoolStore
+	layer cacheLayer
+}
+
+func schoolKey(id models.SchoolId) string {
+	return "school:" + string(id)
+}
+
+func (s cachedSchoolStore) GetSchool(id models.SchoolId) (*models.School, error) {
+	return readThrough(s.layer, schoolKey(id), func() (*models.School, error) {
+		return s.SchoolStore.GetSchool(id)
+	})
+}
+
+func (s cachedSchoolStore) CreateSchool(school *models.School) (models.DbId, error) {
+	id, err := s.SchoolStore.CreateSchool(school)
+	if err == nil {
+		s.layer.invalidator.Delete(schoolKey(school.SchoolId))
+	}
+	return id, err
+}
+
+// 종 시간표는 GetSchool 결과에 같이 들어가므로 바뀌면 학교 캐시를 지움
+
+func (s cachedSchoolStore) CreateBellSchedule(schedule *models.BellSchedule) (models.DbId, error) {
+	id, err := s.SchoolStore.CreateBellSchedule(schedule)
+	if err == nil {
+		s.layer.invalidator.Delete(schoolKey(schedule.SchoolId))
+	}
+	return id, err
+}
+
+func (s cachedSchoolStore) UpdateBellSchedule(schedule *models.BellSchedule) error {
+	err := s.SchoolStore.UpdateBellSchedule(schedule)
+	if err == nil {
+		s.layer.invalidator.Delete(schoolKey(schedule.SchoolId))
+	}
+	return err
+}
+
+func (s cachedSchoolStore) DeleteBellSchedule(id models.DbId) error {
+	schedule, err := s.SchoolStore.GetBellSchedule(id)
+	if err != nil {
+		return s.SchoolStore.DeleteBellSchedule(id)
+	}
+	err = s.SchoolStore.DeleteBellSchedule(id)
+	if err == nil {
+		s.layer.invalidator.Delete(schoolKey(schedule.SchoolId))
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/username/schoolapp/cache"
+)
+
+const (
+	cachedMenuKey   = "menu:school:A:2024-03-04:2024-03-04:"
+	cachedEventsKey = "events:school:A:3"
+)
+
+// useMockDB 패키지 전역의 db와 캐시를 sqlmock과 LRU로 바꾸고, 테스트가 끝나면 되돌림
+func useMockDB(t *testing.T) (sqlmock.Sqlmock, *cache.LRU) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lru := cache.NewLRU(100, time.Minute)
+	lru.Set(cachedMenuKey, []byte("[]"))
+	lru.Set(cachedEventsKey, []byte("{}"))
+
+	previousDB, previousCache, previousStores := db, storeCache, stores
+	db = conn
+	EnableCache(lru)
+	t.Cleanup(func() {
+		db, storeCache, stores = previousDB, previousCache, previousStores
+		_ = conn.Close()
+	})
+	return mock, lru
+}
+
+func expectTrashMenuAndEvents(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE cafeteria_menus SET deleted_at").WithArgs(1, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE schoolevents SET deleted_at").WithArgs(2, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func isCached(lru *cache.LRU, key string) bool {
+	_, ok := lru.Get(key)
+	return ok
+}
+
+func TestWithTxInvalidatesAfterCommit(t *testing.T) {
+	mock, lru := useMockDB(t)
+	expectTrashMenuAndEvents(mock)
+	mock.ExpectCommit()
+
+	err := WithTx(context.Background(), func(tx Stores) error {
+		if err := tx.Menus.DeleteMenu(1, 3); err != nil {
+			return err
+		}
+		if err := tx.Events.DeleteEvents(2, 5); err != nil {
+			return err
+		}
+		// 커밋 전에 지우면 다른 요청이 커밋 전 값을 다시 캐시에 넣을 수 있음
+		if !isCached(lru, cachedMenuKey) || !isCached(lru, cachedEventsKey) {
+			t.Error("cache was invalidated before commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %s", err.Error())
+	}
+
+	if isCached(lru, cachedMenuKey) || isCached(lru, cachedEventsKey) {
+		t.Error("cache should be invalidated after commit")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithTxDropsInvalidationsOnRollback(t *testing.T) {
+	mock, lru := useMockDB(t)
+	expectTrashMenuAndEvents(mock)
+	mock.ExpectRollback()
+
+	failed := errors.New("failed after the writes")
+	err := WithTx(context.Background(), func(tx Stores) error {
+		if err := tx.Menus.DeleteMenu(1, 3); err != nil {
+			return err
+		}
+		if err := tx.Events.DeleteEvents(2, 5); err != nil {
+			return err
+		}
+		return failed
+	})
+	if err != failed {
+		t.Fatalf("WithTx error = %v, want %v", err, failed)
+	}
+
+	if !isCached(lru, cachedMenuKey) || !isCached(lru, cachedEventsKey) {
+		t.Error("rolled back writes should not invalidate the cache")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithTxDropsInvalidationsWhenCommitFails(t *testing.T) {
+	mock, lru := useMockDB(t)
+	expectTrashMenuAndEvents(mock)
+	mock.ExpectCommit().WillReturnError(errors.New("connection lost"))
+
+	err := WithTx(context.Background(), func(tx Stores) error {
+		if err := tx.Menus.DeleteMenu(1, 3); err != nil {
+			return err
+		}
+		return tx.Events.DeleteEvents(2, 5)
+	})
+	if err == nil {
+		t.Fatal("WithTx should return the commit error")
+	}
+
+	if !isCached(lru, cachedMenuKey) || !isCached(lru, cachedEventsKey) {
+		t.Error("a failed commit should not invalidate the cache")
+	}
+}
+
+func TestWithTxVersionConflictDoesNotInvalidate(t *testing.T) {
+	mock, lru := useMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE cafeteria_menus SET deleted_at").WithArgs(1, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := WithTx(context.Background(), func(tx Stores) error {
+		return tx.Menus.DeleteMenu(1, 3)
+	})
+	if err != ErrVersionConflict {
+		t.Fatalf("WithTx error = %v, want %v", err, ErrVersionConflict)
+	}
+	if !isCached(lru, cachedMenuKey) {
+		t.Error("a write that changed nothing should not invalidate the cache")
+	}
+}
+
+// 트랜잭션 밖의 쓰기는 바로 무효화됨
+func TestInvalidatesRightAwayOutsideTx(t *testing.T) {
+	mock, lru := useMockDB(t)
+	mock.ExpectExec("UPDATE schoolevents SET deleted_at").WithArgs(2, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := DeleteEvents(2, 5); err != nil {
+		t.Fatalf("DeleteEvents: %s", err.Error())
+	}
+	if isCached(lru, cachedEventsKey) {
+		t.Error("a write outside a transaction should invalidate right away")
+	}
+	if !isCached(lru, cachedMenuKey) {
+		t.Error("menus should stay cached when only events changed")
+	}
+}
@@ -0,0 +1,198 @@
+package db
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+)
+
+const checklistColumns = "id, student_id, title, items, version, deleted_at"
+
+type sqlChecklistStore struct {
+	q ctxQuerier
+}
+
+func scanChecklist(row scanner) (*models.Checklist, error) {
+	var flat models.FlatCheckList
+	err := row.Scan(&flat.ID, &flat.StudentId, &flat.Title, &flat.Items, &flat.Version, &flat.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	checklist, err := flat.Restore()
+	if err != nil {
+		return nil, err
+	}
+
+	return &checklist, nil
+}
+
+// GetChecklistsOfStudent returns a checklist
+func GetChecklistsOfStudent(studentID *uuid.UUID) (*models.Checklist, error) {
+	return stores.Checklists.GetChecklistsOfStudent(studentID)
+}
+
+func (s sqlChecklistStore) GetChecklistsOfStudent(studentID *uuid.UUID) (*models.Checklist, error) {
+	// Prepare query
+	query := "SELECT " + checklistColumns + " FROM checklists WHERE student_id = ? AND deleted_at IS NULL"
+
+	// Execute query
+	row := s.q.QueryRow(query, studentID)
+
+	// Scan row into checklist object
+	return scanChecklist(row)
+}
+
+// GetChecklistsById returns a checklist by ID
+func GetChecklistsById(id models.DbId) (*models.Checklist, error) {
+	return stores.Checklists.GetChecklistsById(id)
+}
+
+func (s sqlChecklistStore) GetChecklistsById(id models.DbId) (*models.Checklist, error) {
+	// Prepare query
+	query := "SELECT " + checklistColumns + " FROM checklists WHERE id = ? AND deleted_at IS NULL"
+
+	// Execute query
+	row := s.q.QueryRow(query, id)
+
+	// Scan row into checklist object
+	return scanChecklist(row)
+}
+
+// CreateChecklist creates a new checklist
+func CreateChecklist(checklist *models.Checklist) (models.DbId, error) {
+	return stores.Checklists.CreateChecklist(checklist)
+}
+
+func (s sqlChecklistStore) CreateChecklist(checklist *models.Checklist) (models.DbId, error) {
+	err := utils.ValidateChecklist(checklist)
+	if err != nil {
+		return 0, err
+	}
+	flat, err := checklist.Flatten()
+	if err != nil {
+		return 0, err
+	}
+
+	// Prepare query to insert checklist
+	createQuery := "INSERT INTO checklists (student_id, title, items) VALUES (?, ?, ?)"
+
+	// Execute query to insert checklist
+	result, err := s.q.Exec(createQuery, flat.StudentId, flat.Title, flat.Items)
+	if err != nil {
+		return 0, err
+	}
+
+	// Get the ID of the newly created checklist
+	listId, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	checklist.ID = models.DbId(listId)
+	checklist.Version = 1
+
+	return models.DbId(listId), nil
+}
+
+// UpdateChecklist updates a checklist
+// checklist.Version이 DB에 저장된 값과 다르면 ErrVersionConflict를 반환함
+func UpdateChecklist(checklist *models.Checklist) error {
+	return stores.Checklists.UpdateChecklist(checklist)
+}
+
+func (s sqlChecklistStore) UpdateChecklist(checklist *models.Checklist) error {
+	err := utils.ValidateChecklist(checklist)
+	if err != nil {
+		return err
+	}
+	flat, err := checklist.Flatten()
+	if err != nil {
+		return err
+	}
+
+	// Prepare query to update checklist
+	update := "UPDATE checklists SET student_id = ?, title = ?, items = ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL"
+
+	// Execute query to update checklist
+	result, err := s.q.Exec(update, flat.StudentId, flat.Title, flat.Items, flat.ID, flat.Version)
+	if err != nil {
+		return err
+	}
+	err = checkVersioned(result)
+	if err != nil {
+		return err
+	}
+	checklist.Version++
+
+	return nil
+}
+
+// DeleteChecklist moves a checklist to the trash
+func DeleteChecklist(id models.DbId) error {
+	return stores.Checklists.DeleteChecklist(id)
+}
+
+func (s sqlChecklistStore) DeleteChecklist(id models.DbId) error {
+	// Prepare query to delete checklist
+	// 실제로 지우지 않고 휴지통으로 옮김, 보관 기간이 지나면 PurgeTrash가 지움
+	deleteQuery := "UPDATE checklists SET deleted_at = NOW(), version = version + 1 WHERE id = ? AND deleted_at IS NULL"
+
+	// Execute query to delete checklist
+	_, err := s.q.Exec(deleteQuery, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetDeletedChecklists returns every checklist in the trash
+func GetDeletedChecklists() ([]models.Checklist, error) {
+	return stores.Checklists.GetDeletedChecklists()
+}
+
+func (s sqlChecklistStore) GetDeletedChecklists() ([]models.Checklist, error) {
+	query := "SELECT " + checklistColumns + " FROM checklists WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+
+	rows, err := s.q.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	checklists := make([]models.Checklist, 0)
+	for rows.Next() {
+		checklist, err := scanChecklist(rows)
+		if err != nil {
+			return nil, err
+		}
+		checklists = append(checklists, *checklist)
+	}
+	return checklists, rows.Err()
+}
+
+// GetDeletedChecklistById returns a checklist in the trash by ID
+func GetDeletedChecklistById(id models.DbId) (*models.Checklist, error) {
+	return stores.Checklists.GetDeletedChecklistById(id)
+}
+
+func (s sqlChecklistStore) GetDeletedChecklistById(id models.DbId) (*models.Checklist, error) {
+	query := "SELECT " + checklistColumns + " FROM checklists WHERE id = ? AND deleted_at IS NOT NULL"
+	return scanChecklist(s.q.QueryRow(query, id))
+}
+
+// RestoreChecklist takes a checklist out of the trash
+func RestoreChecklist(id models.DbId) error {
+	return stores.Checklists.RestoreChecklist(id)
+}
+
+func (s sqlChecklistStore) RestoreChecklist(id models.DbId) error {
+	query := "UPDATE checklists SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL"
+
+	result, err := s.q.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	return checkRestored(result)
+}
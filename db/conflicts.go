@@ -0,0 +1,242 @@
+package db
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/models"
+	"strconv"
+	"strings"
+)
+
+// conflictGroup 충돌하는 수업의 ID만 모아 둔 것, resolveConflicts에서 수업을 한번에 불러옴
+type conflictGroup struct {
+	conflict models.TimetableConflict
+	ids      []models.DbId
+}
+
+// GetSlotConflicts returns the lessons that would clash with entry at its day and period
+// entry.ID가 0이 아니면 그 수업을 듣는 학생들의 다른 수업과 겹치는지도 확인함
+func GetSlotConflicts(entry *models.TimetableEntry) ([]models.TimetableConflict, error) {
+	return stores.Timetables.GetSlotConflicts(entry)
+}
+
+func (s sqlTimetableStore) GetSlotConflicts(entry *models.TimetableEntry) ([]models.TimetableConflict, error) {
+	groups := make([]conflictGroup, 0)
+
+	teacherQuery := "SELECT id FROM timetables WHERE teacher_id = ? AND day = ? AND period = ? AND id <> ? AND deleted_at IS NULL ORDER BY id"
+	ids, err := s.queryIds(teacherQuery, entry.TeacherId[:], entry.Day, entry.Period, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		teacher := entry.TeacherId
+		groups = append(groups, conflictGroup{
+			conflict: models.TimetableConflict{Kind: models.TeacherConflict, TeacherId: &teacher},
+			ids:      ids,
+		})
+	}
+
+	locationQuery := "SELECT id FROM timetables WHERE school_id = ? AND location = ? AND day = ? AND period = ? AND id <> ? AND deleted_at IS NULL ORDER BY id"
+	ids, err = s.queryIds(locationQuery, entry.SchoolId, entry.Location, entry.Day, entry.Period, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		groups = append(groups, conflictGroup{
+			conflict: models.TimetableConflict{Kind: models.LocationConflict, Location: entry.Location},
+			ids:      ids,
+		})
+	}
+
+	if entry.ID != 0 {
+		students, err := s.studentSlotConflicts(entry)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, students...)
+	}
+
+	// 아직 저장되지 않았거나 바뀐 값으로 확인하는 중이므로 entry는 DB에서 읽지 않고 그대로 넣음
+	conflicts, err := s.resolveConflicts(groups)
+	if err != nil {
+		return nil, err
+	}
+	for i := range conflicts {
+		conflicts[i].Day = entry.Day
+		conflicts[i].Period = entry.Period
+		conflicts[i].Entries = append(conflicts[i].Entries, *entry)
+	}
+	return conflicts, nil
+}
+
+// studentSlotConflicts entry를 듣는 학생마다 entry의 요일, 교시에 듣는 다른 수업을 찾음
+func (s sqlTimetableStore) studentSlotConflicts(entry *models.TimetableEntry) ([]conflictGroup, error) {
+	query := "SELECT a.user_id, t.id FROM student_timetable_entries mine " +
+		"JOIN student_timetable_entries other ON other.account_id = mine.account_id AND other.entry_id <> mine.entry_id " +
+		"JOIN timetables t ON t.id = other.entry_id " +
+		"JOIN accounts a ON a.id = mine.account_id " +
+		"WHERE mine.entry_id = ? AND t.day = ? AND t.period = ? AND t.deleted_at IS NULL ORDER BY a.id, t.id"
+
+	rows, err := s.q.Query(query, entry.ID, entry.Day, entry.Period)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	groups := make([]conflictGroup, 0)
+	for rows.Next() {
+		var student uuid.UUID
+		var id models.DbId
+		if err := rows.Scan(&student, &id); err != nil {
+			return nil, err
+		}
+		last := len(groups) - 1
+		if last >= 0 && *groups[last].conflict.StudentId == student {
+			groups[last].ids = append(groups[last].ids, id)
+			continue
+		}
+		groups = append(groups, conflictGroup{
+			conflict: models.TimetableConflict{Kind: models.StudentConflict, StudentId: &student},
+			ids:      []models.DbId{id},
+		})
+	}
+	return groups, rows.Err()
+}
+
+// GetSchoolConflicts returns every clash between existing lessons of a school
+func GetSchoolConflicts(schoolId models.SchoolId) ([]models.TimetableConflict, error) {
+	return stores.Timetables.GetSchoolConflicts(schoolId)
+}
+
+func (s sqlTimetableStore) GetSchoolConflicts(schoolId models.SchoolId) ([]models.TimetableConflict, error) {
+	groups := make([]conflictGroup, 0)
+
+	teacherQuery := "SELECT teacher_id, day, period, GROUP_CONCAT(id ORDER BY id) FROM timetables WHERE school_id = ? AND deleted_at IS NULL GROUP BY teacher_id, day, period HAVING COUNT(*) > 1 ORDER BY day, period"
+	err := s.queryConflictGroups(teacherQuery, schoolId, func(key []byte, group *conflictGroup) error {
+		teacher, err := uuid.FromBytes(key)
+		if err != nil {
+			return err
+		}
+		group.conflict.Kind = models.TeacherConflict
+		group.conflict.TeacherId = &teacher
+		groups = append(groups, *group)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	locationQuery := "SELECT location, day, period, GROUP_CONCAT(id ORDER BY id) FROM timetables WHERE school_id = ? AND deleted_at IS NULL GROUP BY location, day, period HAVING COUNT(*) > 1 ORDER BY day, period"
+	err = s.queryConflictGroups(locationQuery, schoolId, func(key []byte, group *conflictGroup) error {
+		group.conflict.Kind = models.LocationConflict
+		group.conflict.Location = string(key)
+		groups = append(groups, *group)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	studentQuery := "SELECT a.user_id, t.day, t.period, GROUP_CONCAT(t.id ORDER BY t.id) FROM student_timetable_entries se " +
+		"JOIN timetables t ON t.id = se.entry_id JOIN accounts a ON a.id = se.account_id " +
+		"WHERE t.school_id = ? AND t.deleted_at IS NULL GROUP BY se.account_id, a.user_id, t.day, t.period HAVING COUNT(*) > 1 ORDER BY t.day, t.period"
+	err = s.queryConflictGroups(studentQuery, schoolId, func(key []byte, group *conflictGroup) error {
+		student, err := uuid.FromBytes(key)
+		if err != nil {
+			return err
+		}
+		group.conflict.Kind = models.StudentConflict
+		group.conflict.StudentId = &student
+		groups = append(groups, *group)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.resolveConflicts(groups)
+}
+
+// queryConflictGroups (키, 요일, 교시, 쉼표로 이은 수업 ID) 행마다 add를 호출함
+func (s sqlTimetableStore) queryConflictGroups(query string, schoolId models.SchoolId, add func(key []byte, group *conflictGroup) error) error {
+	rows, err := s.q.Query(query, schoolId)
+	if err != nil {
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	for rows.Next() {
+		var key []byte
+		var ids string
+		var group conflictGroup
+		err := rows.Scan(&key, &group.conflict.Day, &group.conflict.Period, &ids)
+		if err != nil {
+			return err
+		}
+		for _, part := range strings.Split(ids, ",") {
+			id, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				return err
+			}
+			group.ids = append(group.ids, models.DbId(id))
+		}
+		err = add(key, &group)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s sqlTimetableStore) queryIds(query string, args ...interface{}) ([]models.DbId, error) {
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	ids := make([]models.DbId, 0)
+	for rows.Next() {
+		var id models.DbId
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// resolveConflicts 모든 충돌에 나온 수업을 한번에 불러와서 채움
+func (s sqlTimetableStore) resolveConflicts(groups []conflictGroup) ([]models.TimetableConflict, error) {
+	all := make([]models.DbId, 0)
+	for _, group := range groups {
+		all = append(all, group.ids...)
+	}
+	entries, err := s.GetTimeTableEntries(all)
+	if err != nil {
+		return nil, err
+	}
+	byId := make(map[models.DbId]models.TimetableEntry, len(entries))
+	for _, entry := range entries {
+		byId[entry.ID] = entry
+	}
+
+	conflicts := make([]models.TimetableConflict, 0, len(groups))
+	for _, group := range groups {
+		conflict := group.conflict
+		conflict.Entries = make([]models.TimetableEntry, 0, len(group.ids))
+		for _, id := range group.ids {
+			if entry, ok := byId[id]; ok {
+				conflict.Entries = append(conflict.Entries, entry)
+			}
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/models"
+)
+
+var (
+	teacherKim = uuid.UUID{15: 2}
+	teacherLee = uuid.UUID{15: 3}
+	studentPak = uuid.UUID{15: 4}
+)
+
+func lessonAt(id models.DbId, teacher uuid.UUID, location string) models.TimetableEntry {
+	return models.TimetableEntry{ID: id, SchoolId: "7430310", TeacherId: teacher, Location: location, Day: models.Monday, Period: 2, Subject: "과목"}
+}
+
+func TestGetSlotConflicts(t *testing.T) {
+	stored := map[models.DbId]models.TimetableEntry{
+		1: lessonAt(1, teacherLee, "과학실"),
+		2: lessonAt(2, teacherKim, "1-3"),
+		3: lessonAt(3, teacherLee, "음악실"),
+	}
+	tests := []struct {
+		name  string
+		entry models.TimetableEntry
+		// 쿼리마다 DB가 돌려줄 수업 ID
+		teacherIds  []models.DbId
+		locationIds []models.DbId
+		studentIds  []models.DbId
+		want        []models.TimetableConflict
+	}{
+		{
+			name:        "same slot with a different teacher in the same room",
+			entry:       lessonAt(0, teacherKim, "과학실"),
+			locationIds: []models.DbId{1},
+			want: []models.TimetableConflict{{
+				Kind: models.LocationConflict, Day: models.Monday, Period: 2, Location: "과학실",
+				Entries: []models.TimetableEntry{stored[1], lessonAt(0, teacherKim, "과학실")},
+			}},
+		},
+		{
+			name:       "same teacher in another room",
+			entry:      lessonAt(0, teacherKim, "과학실"),
+			teacherIds: []models.DbId{2},
+			want: []models.TimetableConflict{{
+				Kind: models.TeacherConflict, Day: models.Monday, Period: 2, TeacherId: &teacherKim,
+				Entries: []models.TimetableEntry{stored[2], lessonAt(0, teacherKim, "과학실")},
+			}},
+		},
+		{
+			// 저장된 수업이면 듣는 학생의 다른 수업도 확인함
+			name:       "a student of the lesson takes another lesson then",
+			entry:      lessonAt(9, teacherKim, "과학실"),
+			studentIds: []models.DbId{3},
+			want: []models.TimetableConflict{{
+				Kind: models.StudentConflict, Day: models.Monday, Period: 2, StudentId: &studentPak,
+				Entries: []models.TimetableEntry{stored[3], lessonAt(9, teacherKim, "과학실")},
+			}},
+		},
+		{
+			name:  "free slot",
+			entry: lessonAt(9, teacherKim, "과학실"),
+			want:  []models.TimetableConflict{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			idRows := func(ids []models.DbId) *sqlmock.Rows {
+				rows := sqlmock.NewRows([]string{"id"})
+				for _, id := range ids {
+					rows.AddRow(id)
+				}
+				return rows
+			}
+			entry := test.entry
+			mock.ExpectQuery("SELECT id FROM timetables WHERE teacher_id").
+				WithArgs(entry.TeacherId[:], entry.Day, entry.Period, entry.ID).
+				WillReturnRows(idRows(test.teacherIds))
+			mock.ExpectQuery("SELECT id FROM timetables WHERE school_id").
+				WithArgs(entry.SchoolId, entry.Location, entry.Day, entry.Period, entry.ID).
+				WillReturnRows(idRows(test.locationIds))
+			if entry.ID != 0 {
+				rows := sqlmock.NewRows([]string{"user_id", "id"})
+				for _, id := range test.studentIds {
+					rows.AddRow(studentPak[:], id)
+				}
+				mock.ExpectQuery("FROM student_timetable_entries mine").WithArgs(entry.ID, entry.Day, entry.Period).WillReturnRows(rows)
+			}
+			found := append(append(append([]models.DbId{}, test.teacherIds...), test.locationIds...), test.studentIds...)
+			if len(found) > 0 {
+				rows := sqlmock.NewRows([]string{"id", "school_id", "teacher_id", "location", "day", "period", "subject", "version", "deleted_at"})
+				for _, id := range found {
+					lesson := stored[id]
+					rows.AddRow(lesson.ID, lesson.SchoolId, lesson.TeacherId[:], lesson.Location, lesson.Day, lesson.Period, lesson.Subject, lesson.Version, nil)
+				}
+				mock.ExpectQuery("SELECT " + timetableColumns + " FROM timetables WHERE id IN").WillReturnRows(rows)
+			}
+
+			store := sqlTimetableStore{ctxQuerier{context.Background(), conn}}
+			got, err := store.GetSlotConflicts(&entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetSlotConflicts() =\n%+v\nwant\n%+v", got, test.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
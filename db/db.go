@@ -1,11 +1,11 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
-	"github.com/google/uuid"
 	"github.com/username/schoolapp/models"
 	"github.com/username/schoolapp/utils"
 	"log"
@@ -18,11 +18,30 @@ var db *sql.DB
 func createTables() {
 	// prepare query
 	createSchools := "CREATE TABLE IF NOT EXISTS `schools` (id INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `school_id` VARCHAR(255) NOT NULL, `region_id` VARCHAR(255) NOT NULL, `school_name` VARCHAR(255) NOT NULL, `region_name` VARCHAR(255) NOT NULL, `school_email_only` BOOL NOT NULL, `school_email` VARCHAR(255) NOT NULL)  ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
-	createAccounts := "CREATE TABLE IF NOT EXISTS `accounts` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `user_id` TINYBLOB NOT NULL, `name` VARCHAR(255) NOT NULL, `email` VARCHAR(255) NOT NULL, `password` TINYBLOB NOT NULL, `permission_level` TINYINT NOT NULL, `school_id` VARCHAR(255), `timetable_list` LONGBLOB, `timetable_is_public` BOOL,`grade` TINYINT, `class` TINYINT, `number` TINYINT, `checklist_id` INT(11), `friends` LONGBLOB) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
-	createTimeTables := "CREATE TABLE IF NOT EXISTS `timetables` (`id` INT(11) NOT NULL AUTO_INCREMENT, `teacher_id` TINYBLOB NOT NULL, `location` VARCHAR(255) NOT NULL, `day` INT(11) NOT NULL, `period` TIME NOT NULL, `subject` VARCHAR(255) NOT NULL, PRIMARY KEY (`id`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
-	createCafeteria := "CREATE TABLE IF NOT EXISTS `cafeteria_menus` ( `id` INT(11) NOT NULL AUTO_INCREMENT, `school_id` VARCHAR(255) NOT NULL, `meal_name` VARCHAR(255) NOT NULL, `date` DATE NOT NULL, `contents` TEXT NOT NULL, PRIMARY KEY (`id`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
-	createChecklists := "CREATE TABLE IF NOT EXISTS `checklists` (`id` INT(11) NOT NULL AUTO_INCREMENT, `student_id` TINYBLOB NOT NULL, `title` TEXT NOT NULL, `items` TEXT NOT NULL, PRIMARY KEY (`id`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
-	createEvents := "CREATE TABLE IF NOT EXISTS `schoolevents` (`id` INT(11) NOT NULL AUTO_INCREMENT, `school_id` VARCHAR(255) NOT NULL, `month` INT(11) NOT NULL, `events` TEXT NOT NULL, PRIMARY KEY (`id`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createAccounts := "CREATE TABLE IF NOT EXISTS `accounts` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `user_id` TINYBLOB NOT NULL, `name` VARCHAR(255) NOT NULL, `email` VARCHAR(255) NOT NULL, `password` TINYBLOB NOT NULL, `permission_level` TINYINT NOT NULL, `school_id` VARCHAR(255), `timetable_is_public` BOOL,`grade` TINYINT, `class` TINYINT, `number` TINYINT, `checklist_id` INT(11), `version` INT NOT NULL DEFAULT 1) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createTimeTables := "CREATE TABLE IF NOT EXISTS `timetables` (`id` INT(11) NOT NULL AUTO_INCREMENT, `school_id` VARCHAR(255) NOT NULL DEFAULT '', `teacher_id` TINYBLOB NOT NULL, `location` VARCHAR(255) NOT NULL, `day` INT(11) NOT NULL, `period` INT(11) NOT NULL, `subject` VARCHAR(255) NOT NULL, `version` INT NOT NULL DEFAULT 1, `deleted_at` DATETIME NULL, `external_key` VARCHAR(255) NULL, PRIMARY KEY (`id`), UNIQUE KEY `uq_timetables_external_key` (`external_key`), KEY `idx_timetables_slot` (`school_id`, `day`, `period`), KEY `idx_deleted_at` (`deleted_at`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createCafeteria := "CREATE TABLE IF NOT EXISTS `cafeteria_menus` ( `id` INT(11) NOT NULL AUTO_INCREMENT, `school_id` VARCHAR(255) NOT NULL, `meal_name` VARCHAR(255) NOT NULL, `date` DATE NOT NULL, `contents` TEXT NOT NULL, `dishes` TEXT NULL, `calories` DOUBLE NULL, `nutrients` TEXT NULL, `origins` TEXT NULL, `version` INT NOT NULL DEFAULT 1, `deleted_at` DATETIME NULL, PRIMARY KEY (`id`), KEY `idx_cafeteria_menus_meal` (`school_id`, `date`, `meal_name`), KEY `idx_deleted_at` (`deleted_at`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createChecklists := "CREATE TABLE IF NOT EXISTS `checklists` (`id` INT(11) NOT NULL AUTO_INCREMENT, `student_id` TINYBLOB NOT NULL, `title` TEXT NOT NULL, `items` TEXT NOT NULL, `version` INT NOT NULL DEFAULT 1, `deleted_at` DATETIME NULL, PRIMARY KEY (`id`), KEY `idx_deleted_at` (`deleted_at`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createEvents := "CREATE TABLE IF NOT EXISTS `schoolevents` (`id` INT(11) NOT NULL AUTO_INCREMENT, `school_id` VARCHAR(255) NOT NULL, `month` INT(11) NOT NULL, `events` TEXT NOT NULL, `version` INT NOT NULL DEFAULT 1, `deleted_at` DATETIME NULL, PRIMARY KEY (`id`), KEY `idx_deleted_at` (`deleted_at`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createStudentTimetableEntries := "CREATE TABLE IF NOT EXISTS `student_timetable_entries` (`account_id` INT(11) NOT NULL, `entry_id` INT(11) NOT NULL, PRIMARY KEY (`account_id`, `entry_id`), KEY `idx_student_timetable_entries_entry` (`entry_id`), FOREIGN KEY (`account_id`) REFERENCES `accounts`(`id`) ON DELETE CASCADE, FOREIGN KEY (`entry_id`) REFERENCES `timetables`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createFriendships := "CREATE TABLE IF NOT EXISTS `friendships` (`account_id` INT(11) NOT NULL, `friend_id` INT(11) NOT NULL, PRIMARY KEY (`account_id`, `friend_id`), KEY `idx_friendships_friend` (`friend_id`), FOREIGN KEY (`account_id`) REFERENCES `accounts`(`id`) ON DELETE CASCADE, FOREIGN KEY (`friend_id`) REFERENCES `accounts`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createBellSchedules := "CREATE TABLE IF NOT EXISTS `bell_schedules` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `school_id` VARCHAR(255) NOT NULL, `name` VARCHAR(255) NOT NULL, `weekdays` VARCHAR(255) NOT NULL, `is_default` BOOL NOT NULL, `periods` TEXT NOT NULL, `version` INT NOT NULL DEFAULT 1, KEY `idx_bell_schedules_school` (`school_id`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createBellScheduleOverrides := "CREATE TABLE IF NOT EXISTS `bell_schedule_overrides` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `school_id` VARCHAR(255) NOT NULL, `date` DATE NOT NULL, `schedule_id` INT(11) NOT NULL, `note` VARCHAR(255) NOT NULL, UNIQUE KEY `uq_bell_schedule_overrides_date` (`school_id`, `date`), FOREIGN KEY (`schedule_id`) REFERENCES `bell_schedules`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createTimetableVisibility := "CREATE TABLE IF NOT EXISTS `timetable_visibility` (`account_id` INT(11) NOT NULL, `friend_id` INT(11) NOT NULL, `visible` BOOL NOT NULL, PRIMARY KEY (`account_id`, `friend_id`), FOREIGN KEY (`account_id`) REFERENCES `accounts`(`id`) ON DELETE CASCADE, FOREIGN KEY (`friend_id`) REFERENCES `accounts`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createTerms := "CREATE TABLE IF NOT EXISTS `terms` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `school_id` VARCHAR(255) NOT NULL, `name` VARCHAR(255) NOT NULL, `start_date` DATE NOT NULL, `end_date` DATE NOT NULL, KEY `idx_terms_school` (`school_id`, `start_date`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createAllergyProfiles := "CREATE TABLE IF NOT EXISTS `allergy_profiles` (`account_id` INT(11) NOT NULL PRIMARY KEY, `allergens` TEXT NOT NULL, `ingredients` TEXT NOT NULL, `notify` BOOL NOT NULL DEFAULT FALSE, FOREIGN KEY (`account_id`) REFERENCES `accounts`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createNotifications := "CREATE TABLE IF NOT EXISTS `notifications` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `account_id` INT(11) NOT NULL, `kind` VARCHAR(32) NOT NULL, `dedup_key` VARCHAR(255) NOT NULL, `message` TEXT NOT NULL, `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, `read_at` DATETIME NULL, UNIQUE KEY `uq_notifications_dedup` (`account_id`, `dedup_key`), FOREIGN KEY (`account_id`) REFERENCES `accounts`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createMealRatings := "CREATE TABLE IF NOT EXISTS `meal_ratings` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `menu_id` INT(11) NOT NULL, `account_id` INT(11) NOT NULL, `dish` VARCHAR(255) NOT NULL DEFAULT '', `score` TINYINT NOT NULL, `comment` TEXT NOT NULL, `comment_status` VARCHAR(16) NOT NULL DEFAULT 'none', `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, UNIQUE KEY `uq_meal_ratings` (`menu_id`, `account_id`, `dish`), KEY `idx_meal_ratings_status` (`comment_status`), FOREIGN KEY (`menu_id`) REFERENCES `cafeteria_menus`(`id`) ON DELETE CASCADE, FOREIGN KEY (`account_id`) REFERENCES `accounts`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createMealSignupPolicies := "CREATE TABLE IF NOT EXISTS `meal_signup_policies` (`school_id` VARCHAR(255) NOT NULL PRIMARY KEY, `days_before` TINYINT NOT NULL, `cutoff` SMALLINT NOT NULL) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createMealSignups := "CREATE TABLE IF NOT EXISTS `meal_signups` (`menu_id` INT(11) NOT NULL, `account_id` INT(11) NOT NULL, `attending` BOOL NULL, `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, `checked_in_at` DATETIME NULL, PRIMARY KEY (`menu_id`, `account_id`), KEY `idx_meal_signups_account` (`account_id`), FOREIGN KEY (`menu_id`) REFERENCES `cafeteria_menus`(`id`) ON DELETE CASCADE, FOREIGN KEY (`account_id`) REFERENCES `accounts`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createMenuPhotos := "CREATE TABLE IF NOT EXISTS `menu_photos` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `menu_id` INT(11) NOT NULL, `position` INT NOT NULL, `content_type` VARCHAR(32) NOT NULL, `width` INT NOT NULL, `height` INT NOT NULL, `hidden` BOOL NOT NULL DEFAULT FALSE, `uploaded_by` INT(11) NULL, `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, KEY `idx_menu_photos_menu` (`menu_id`, `position`), FOREIGN KEY (`menu_id`) REFERENCES `cafeteria_menus`(`id`) ON DELETE CASCADE, FOREIGN KEY (`uploaded_by`) REFERENCES `accounts`(`id`) ON DELETE SET NULL) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createCalendarFeeds := "CREATE TABLE IF NOT EXISTS `calendar_feeds` (`account_id` INT(11) NOT NULL PRIMARY KEY, `token_hash` CHAR(64) NOT NULL, `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, UNIQUE KEY `uq_calendar_feeds_token` (`token_hash`), FOREIGN KEY (`account_id`) REFERENCES `accounts`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createRooms := "CREATE TABLE IF NOT EXISTS `rooms` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `school_id` VARCHAR(255) NOT NULL, `name` VARCHAR(255) NOT NULL, `building` VARCHAR(255) NOT NULL, `capacity` INT NOT NULL, UNIQUE KEY `uq_rooms_name` (`school_id`, `name`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createTimetableImports := "CREATE TABLE IF NOT EXISTS `timetable_imports` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `school_id` VARCHAR(255) NOT NULL, `grade` TINYINT NOT NULL, `class` TINYINT NOT NULL, `teacher_id` TINYBLOB NOT NULL, `last_run_at` DATETIME NULL, `last_error` VARCHAR(1024) NOT NULL DEFAULT '', UNIQUE KEY `uq_timetable_imports_class` (`school_id`, `grade`, `class`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createTimetableExceptions := "CREATE TABLE IF NOT EXISTS `timetable_exceptions` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `school_id` VARCHAR(255) NOT NULL, `entry_id` INT(11) NOT NULL, `date` DATE NOT NULL, `kind` VARCHAR(32) NOT NULL, `period` INT(11) NULL, `teacher_id` TINYBLOB NULL, `location` VARCHAR(255) NOT NULL, `note` VARCHAR(255) NOT NULL, `created_by` TINYBLOB NOT NULL, UNIQUE KEY `uq_timetable_exceptions_date` (`entry_id`, `date`), KEY `idx_timetable_exceptions_school` (`school_id`, `date`), FOREIGN KEY (`entry_id`) REFERENCES `timetables`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createElectiveGroups := "CREATE TABLE IF NOT EXISTS `elective_groups` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `school_id` VARCHAR(255) NOT NULL, `name` VARCHAR(255) NOT NULL, `grade` TINYINT NOT NULL, `mode` VARCHAR(32) NOT NULL DEFAULT '', `opens_at` DATETIME NULL, `closes_at` DATETIME NULL, `allocated_at` DATETIME NULL, `created_by` TINYBLOB NOT NULL, `version` INT NOT NULL DEFAULT 1, KEY `idx_elective_groups_school` (`school_id`), KEY `idx_elective_groups_closes` (`allocated_at`, `closes_at`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createElectiveOptions := "CREATE TABLE IF NOT EXISTS `elective_options` (`id` INT(11) NOT NULL AUTO_INCREMENT PRIMARY KEY, `group_id` INT(11) NOT NULL, `name` VARCHAR(255) NOT NULL, `capacity` INT NOT NULL, `entries` TEXT NOT NULL, FOREIGN KEY (`group_id`) REFERENCES `elective_groups`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	createElectiveChoices := "CREATE TABLE IF NOT EXISTS `elective_choices` (`group_id` INT(11) NOT NULL, `account_id` INT(11) NOT NULL, `option_id` INT(11) NOT NULL, `preference` TINYINT NOT NULL, `chosen_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6), `assigned` BOOL NOT NULL DEFAULT FALSE, PRIMARY KEY (`group_id`, `account_id`, `preference`), UNIQUE KEY `uq_elective_choices_option` (`group_id`, `account_id`, `option_id`), FOREIGN KEY (`group_id`) REFERENCES `elective_groups`(`id`) ON DELETE CASCADE, FOREIGN KEY (`account_id`) REFERENCES `accounts`(`id`) ON DELETE CASCADE, FOREIGN KEY (`option_id`) REFERENCES `elective_options`(`id`) ON DELETE CASCADE) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
 
 	// Execute query
 	queries := []string{createSchools,
@@ -30,7 +49,26 @@ func createTables() {
 		createTimeTables,
 		createCafeteria,
 		createChecklists,
-		createEvents}
+		createEvents,
+		createStudentTimetableEntries,
+		createFriendships,
+		createBellSchedules,
+		createBellScheduleOverrides,
+		createTimetableVisibility,
+		createTerms,
+		createCalendarFeeds,
+		createRooms,
+		createTimetableImports,
+		createTimetableExceptions,
+		createElectiveGroups,
+		createElectiveOptions,
+		createElectiveChoices,
+		createAllergyProfiles,
+		createNotifications,
+		createMealRatings,
+		createMealSignupPolicies,
+		createMealSignups,
+		createMenuPhotos}
 	for i := range queries {
 		_, err := db.Exec(queries[i])
 		if err != nil {
@@ -72,6 +110,11 @@ func Connect() {
 
 	// Create tables if they don't exist
 	createTables()
+
+	// Convert existing data to the current schema
+	migrate()
+
+	stores = newStores(context.Background(), db)
 }
 
 // Close closes the database connection
@@ -82,514 +125,6 @@ func Close() {
 	}
 }
 
-// GetAccountByEmail returns a user by Email
-func GetAccountByEmail(Email *string) (*models.Account, error) {
-	// Prepare query
-	query := "SELECT * FROM accounts WHERE email = ?"
-
-	// Execute query
-	row := db.QueryRow(query, Email)
-
-	// Scan row into flataccount object
-	var flataccount models.FlatAccount
-	err := row.Scan(&flataccount.DbId, &flataccount.UserId, &flataccount.Name, &flataccount.Email, &flataccount.Password, &flataccount.PermissionLevel, &flataccount.SchoolId, &flataccount.TimeTableEntries, &flataccount.TimeTableIsPublic, &flataccount.Grade, &flataccount.Class, &flataccount.Number, &flataccount.ChecklistId, &flataccount.Friends)
-	if err != nil {
-		return nil, err
-	}
-	user, err := flataccount.Restore()
-	if err != nil {
-		return nil, err
-	}
-
-	return &user, nil
-}
-
-// GetAccountById returns a student by ID
-func GetAccountById(id *uuid.UUID) (*models.Account, error) {
-	// Prepare query
-	query := "SELECT * FROM accounts WHERE user_id = ?"
-
-	// Execute query
-	row := db.QueryRow(query, id)
-
-	// Scan row into user object
-	var flataccount models.FlatAccount
-	err := row.Scan(&flataccount.DbId, &flataccount.UserId, &flataccount.Name, &flataccount.Email, &flataccount.Password, &flataccount.PermissionLevel, &flataccount.SchoolId, &flataccount.TimeTableEntries, &flataccount.TimeTableIsPublic, &flataccount.Grade, &flataccount.Class, &flataccount.Number, &flataccount.ChecklistId, &flataccount.Friends)
-	if err != nil {
-		return nil, err
-	}
-	user, err := flataccount.Restore()
-	if err != nil {
-		return nil, err
-	}
-
-	return &user, nil
-}
-
-// CreateAccount creates a new student
-func CreateAccount(account *models.Account) (models.DbId, error) {
-	// Prepare query
-	query := "INSERT INTO accounts (user_id, name, email, password, permission_level, school_id, timetable_list, timetable_is_public, grade, class, number, checklist_id, friends) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
-
-	var result sql.Result
-	var err error
-
-	flataccount, err := account.ToSql()
-	if err != nil {
-		return models.DbId(0), err
-	}
-
-	result, err = db.Exec(query, flataccount.UserId, flataccount.Name, flataccount.Email, flataccount.Password, flataccount.PermissionLevel, flataccount.SchoolId, flataccount.TimeTableEntries, flataccount.TimeTableIsPublic, flataccount.Grade, flataccount.Class, flataccount.Number, flataccount.ChecklistId, flataccount.Friends)
-
-	if err != nil {
-		return 0, err
-	}
-
-	// Get the ID of the newly created account
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-
-	account.DbId = models.DbId(id)
-
-	return account.DbId, nil
-}
-
-// UpdateAccount updates a student
-func UpdateAccount(account *models.Account) error {
-	// Prepare query
-	query := "UPDATE accounts SET user_id = ?, name = ?, email = ?, password = ?, permission_level = ?, school_id = ?, timetable_list = ?, timetable_is_public = ?, grade = ?, class = ?, number = ?, checklist_id = ?, friends = ? WHERE id = ?"
-
-	flataccount, err := account.ToSql()
-	if err != nil {
-		return err
-	}
-	// Execute query
-	_, err = db.Exec(query, flataccount.UserId, flataccount.Name, flataccount.Email, flataccount.Password, flataccount.PermissionLevel, flataccount.SchoolId, flataccount.TimeTableEntries, flataccount.TimeTableIsPublic, flataccount.Grade, flataccount.Class, flataccount.Number, flataccount.ChecklistId, flataccount.ChecklistId, flataccount.Friends, flataccount.DbId)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// DeleteAccount deletes a student by ID
-func DeleteAccount(id models.DbId) error {
-	// Prepare query
-	query := "DELETE FROM accounts WHERE id = ?"
-
-	// Execute query
-	_, err := db.Exec(query, id)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// GetTimeTableEntry returns a list of timetables for a student
-func GetTimeTableEntry(id models.DbId) (*models.TimetableEntry, error) {
-	// Prepare query
-	query := "SELECT * FROM timetables WHERE id = ?"
-
-	// Execute query
-	rows, err := db.Query(query, id)
-	if err != nil {
-		return nil, err
-	}
-
-	// Scan rows into entry objects
-	var entry models.TimetableEntry
-	err = rows.Scan(&entry.ID, &entry.TeacherId, &entry.Location, &entry.Day, &entry.Period, &entry.Subject)
-	if err != nil {
-		return nil, err
-	}
-
-	return &entry, nil
-}
-
-// CreateTimetable creates a new timetable
-func CreateTimetable(entry *models.TimetableEntry) (models.DbId, error) {
-	err := utils.ValidateTimeTableEntry(entry)
-	if err != nil {
-		return 0, err
-	}
-
-	// Prepare query
-	query := "INSERT INTO timetables (teacher_id, location, day, period, subject) VALUES (?, ?, ?, ?, ?)"
-
-	// Execute query
-	result, err := db.Exec(query, entry.TeacherId, entry.Location, entry.Day, entry.Period, entry.Subject)
-	if err != nil {
-		return 0, err
-	}
-
-	// Get the ID of the newly created entry
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-
-	entry.ID = models.DbId(id)
-
-	return entry.ID, nil
-}
-
-// UpdateTimetable updates a timetable
-func UpdateTimetable(entry *models.TimetableEntry) error {
-	err := utils.ValidateTimeTableEntry(entry)
-	if err != nil {
-		return err
-	}
-	// Prepare query
-	query := "UPDATE timetables SET id = ?, teacher_id = ?, location = ?, day = ?, period = ?, subject = ? WHERE id = ?"
-
-	// Execute query
-	_, err = db.Exec(query, entry.ID, entry.TeacherId, entry.Location, entry.Day, entry.Period, entry.Subject, entry.ID)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// DeleteTimetable deletes a timetable by ID
-func DeleteTimetable(id models.DbId) error {
-	// Prepare query
-	query := "DELETE FROM timetables WHERE id = ?"
-
-	// Execute query
-	_, err := db.Exec(query, id)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// GetMenuByID returns the cafeteria menu for a specific date by ID
-func GetMenuByID(id models.DbId) (*models.CafeteriaMenu, error) {
-	// Prepare query
-	query := "SELECT * FROM cafeteria_menus WHERE id = ?"
-
-	// Execute query
-	row := db.QueryRow(query, id)
-
-	// Scan row into cafeteria menu object
-	var menu models.CafeteriaMenu
-	err := row.Scan(&menu.ID, &menu.SchoolId, &menu.MealName, &menu.Date, &menu.Contents)
-	if err != nil {
-		return nil, err
-	}
-
-	return &menu, nil
-}
-
-// GetMenu returns the cafeteria menu for a specific date
-func GetMenu(date time.Time) (*models.CafeteriaMenu, error) {
-	// Prepare query
-	query := "SELECT * FROM cafeteria_menus WHERE date = ?"
-
-	// Execute query
-	row := db.QueryRow(query, date)
-
-	// Scan row into cafeteria menu object
-	var menu models.CafeteriaMenu
-	err := row.Scan(&menu.ID, &menu.SchoolId, &menu.MealName, &menu.Date, &menu.Contents)
-	if err != nil {
-		return nil, err
-	}
-
-	return &menu, nil
-}
-
-// CreateMenu creates a new cafeteria menu
-func CreateMenu(menu *models.CafeteriaMenu) (models.DbId, error) {
-	err := utils.ValidateCafeteriaMenu(menu)
-	if err != nil {
-		return 0, err
-	}
-
-	// Prepare query to insert menu
-	menuQuery := "INSERT INTO cafeteria_menus (school_id, meal_name, date, contents) VALUES (?, ?, ?, ?)"
-
-	// Execute query to insert menu
-	menuResult, err := db.Exec(menuQuery, menu.SchoolId, menu.MealName, menu.Date, menu.Contents)
-	if err != nil {
-		return 0, err
-	}
-
-	// Get the ID of the newly created menu
-	menuID, err := menuResult.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-	menu.ID = models.DbId(menuID)
-
-	return models.DbId(menuID), nil
-}
-
-// UpdateMenu updates a cafeteria menu
-func UpdateMenu(menu *models.CafeteriaMenu) error {
-	err := utils.ValidateCafeteriaMenu(menu)
-	if err != nil {
-		return err
-	}
-
-	// Prepare query to insert menu
-	menuQuery := "UPDATE cafeteria_menus SET school_id = ?, meal_name = ?, date = ?, contents = ? WHERE id = ?"
-
-	// Execute query to insert menu
-	_, err = db.Exec(menuQuery, menu.SchoolId, menu.MealName, menu.Date, menu.Contents, menu.ID)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// DeleteMenu deletes a cafeteria menu by ID
-func DeleteMenu(id models.DbId) error {
-	// Prepare query to delete menu
-	deleteMenuQuery := "DELETE FROM cafeteria_menus WHERE id = ?"
-
-	// Execute query to delete menu
-	_, err := db.Exec(deleteMenuQuery, id)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// GetChecklistsOfStudent returns a checklist
-func GetChecklistsOfStudent(studentID *uuid.UUID) (*models.Checklist, error) {
-	// Prepare query
-	query := "SELECT * FROM checklists WHERE student_id = ?"
-
-	// Execute query
-	row := db.QueryRow(query, studentID)
-
-	// Scan row into checklist object
-	var checklist models.Checklist
-	err := row.Scan(&checklist.ID, &checklist.Title, &checklist.Items, &checklist.Items)
-	if err != nil {
-		return nil, err
-	}
-
-	return &checklist, nil
-}
-
-func GetChecklistsById(id models.DbId) (*models.Checklist, error) {
-	// Prepare query
-	query := "SELECT * FROM checklists WHERE id = ?"
-
-	// Execute query
-	row := db.QueryRow(query, id)
-
-	// Scan row into checklist object
-	var checklist models.Checklist
-	err := row.Scan(&checklist.ID, &checklist.Title, &checklist.Items, &checklist.Items)
-	if err != nil {
-		return nil, err
-	}
-
-	return &checklist, nil
-}
-
-// CreateChecklist creates a new checklist
-func CreateChecklist(checklist *models.Checklist) (models.DbId, error) {
-	err := utils.ValidateChecklist(checklist)
-	if err != nil {
-		return 0, err
-	}
-
-	// Prepare query to insert menu
-	createQuery := "INSERT INTO checklists (student_id, title, items) VALUES (?, ?, ?)"
-
-	// Execute query to insert menu
-	result, err := db.Exec(createQuery, checklist.StudentId, checklist.Title, checklist.Items)
-	if err != nil {
-		return 0, err
-	}
-
-	// Get the ID of the newly created checklist
-	listId, err := result.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-	checklist.ID = models.DbId(listId)
-
-	return models.DbId(listId), nil
-}
-
-// UpdateChecklist updates a checklist
-func UpdateChecklist(checklist *models.Checklist) error {
-	err := utils.ValidateChecklist(checklist)
-	if err != nil {
-		return err
-	}
-
-	// Prepare query to insert checklist
-	update := "UPDATE checklists SET student_id = ?, title = ?, items = ? WHERE id = ?"
-
-	// Execute query to insert checklist
-	_, err = db.Exec(update, checklist.StudentId, checklist.Title, checklist.Items, checklist.ID)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// DeleteChecklist deletes a checklist by ID
-func DeleteChecklist(id models.DbId) error {
-	// Prepare query to deleteQuery checklist items
-	deleteQuery := "DELETE FROM checklists WHERE id = ?"
-
-	// Execute query to deleteQuery checklist items
-	_, err := db.Exec(deleteQuery, id)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-// GetAllEvents returns all events
-func GetAllEvents() (*models.Events, error) {
-	// Prepare query
-	query := "SELECT * FROM schoolevents"
-
-	// Execute query
-	row := db.QueryRow(query)
-
-	// Scan row into student object
-	var events models.Events
-	err := row.Scan(&events.ID, &events.SchoolId, &events.Month, &events.Events)
-	if err != nil {
-		return nil, err
-	}
-
-	return &events, nil
-}
-
-// GetEventsByMonth returns an event by Month
-func GetEventsByMonth(month int) (*models.Events, error) {
-	// Prepare query
-	query := "SELECT * FROM schoolevents WHERE month = ?"
-
-	// Execute query
-	row := db.QueryRow(query, month)
-
-	// Scan row into student object
-	var events models.Events
-	err := row.Scan(&events.ID, &events.SchoolId, &events.Month, &events.Events)
-	if err != nil {
-		return nil, err
-	}
-
-	return &events, nil
-}
-
-// CreateEvents creates a new event
-func CreateEvents(events *models.Events) (models.DbId, error) {
-	err := utils.ValidateEvents(events)
-	if err != nil {
-		return 0, err
-	}
-	// Prepare query
-	query := "INSERT INTO schoolevents (school_id, month, events) VALUES (?, ?, ?)"
-
-	// Execute query
-	result, err := db.Exec(query, events.SchoolId, events.Month, &events.Events)
-	if err != nil {
-		return 0, err
-	}
-
-	// Get the ID of the newly created student
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-
-	events.ID = models.DbId(id)
-
-	return events.ID, nil
-}
-
-func GetAllAccounts() ([]models.Account, error) {
-	// Prepare query
-	query := "SELECT * FROM accounts"
-
-	// Execute query
-	rows, err := db.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer func(rows *sql.Rows) {
-		_ = rows.Close()
-	}(rows)
-
-	// Iterate through rows and create Account objects
-	var accounts []models.Account
-	for rows.Next() {
-		var account models.Account
-		err := rows.Scan(&account.DbId, &account.UserId, &account.Name, &account.Email, &account.Password, &account.PermissionInfo)
-		if err != nil {
-			return nil, err
-		}
-		accounts = append(accounts, account)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return accounts, nil
-}
-
-func CreateSchool(school *models.School) (models.DbId, error) {
-	err := utils.ValidateSchool(school)
-	if err != nil {
-		return 0, err
-	}
-	// Prepare query
-	query := "INSERT INTO schools (school_id, region_id, school_name, region_name, school_email_only, school_email) VALUES (?, ?, ?, ?, ?, ?)"
-
-	// Execute query
-	result, err := db.Exec(query, school.SchoolId, school.RegionId, school.SchoolName, school.RegionName, school.SchoolEmailOnly, school.SchoolEmail)
-	if err != nil {
-		return 0, err
-	}
-
-	// Get the ID of the newly created school
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-
-	school.ID = models.DbId(id)
-
-	return school.ID, nil
-}
-
-func GetSchool(id models.SchoolId) (*models.School, error) {
-	// Prepare query
-	query := "SELECT * FROM schools WHERE school_id = ?"
-
-	// Execute query
-	row := db.QueryRow(query, id)
-
-	// Scan row into student object
-	var school models.School
-	err := row.Scan(&school.ID, &school.SchoolId, &school.RegionId, &school.SchoolName, &school.RegionName, &school.SchoolEmailOnly)
-	if err != nil {
-		return nil, err
-	}
-
-	return &school, nil
-}
-
 // ValidateNewAccount ValidateNewAccount와 동일하나 ID 검사는 안함
 func ValidateNewAccount(account *models.Account) error {
 	if account == nil {
@@ -0,0 +1,451 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"log"
+	"time"
+)
+
+const electiveGroupColumns = "id, school_id, name, grade, mode, opens_at, closes_at, allocated_at, created_by, version"
+
+type sqlElectiveStore struct {
+	q ctxQuerier
+}
+
+func scanElectiveGroup(row scanner) (*models.ElectiveGroup, error) {
+	var group models.ElectiveGroup
+	err := row.Scan(&group.ID, &group.SchoolId, &group.Name, &group.Grade, &group.Mode, &group.OpensAt, &group.ClosesAt, &group.AllocatedAt, &group.CreatedBy, &group.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// loadOptions 과목과 과목마다 자리가 정해진 학생 수를 채움
+func (s sqlElectiveStore) loadOptions(group *models.ElectiveGroup) error {
+	rows, err := s.q.Query("SELECT id, name, capacity, entries FROM elective_options WHERE group_id = ? ORDER BY id", group.ID)
+	if err != nil {
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	group.Options = make([]models.ElectiveOption, 0)
+	for rows.Next() {
+		var option models.ElectiveOption
+		var entries string
+		if err := rows.Scan(&option.ID, &option.Name, &option.Capacity, &entries); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(entries), &option.Entries); err != nil {
+			return err
+		}
+		group.Options = append(group.Options, option)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	taken, err := s.q.Query("SELECT option_id, COUNT(*) FROM elective_choices WHERE group_id = ? AND assigned GROUP BY option_id", group.ID)
+	if err != nil {
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(taken)
+
+	for taken.Next() {
+		var id models.DbId
+		var count int
+		if err := taken.Scan(&id, &count); err != nil {
+			return err
+		}
+		if option := group.Option(id); option != nil {
+			option.Taken = count
+		}
+	}
+	return taken.Err()
+}
+
+// GetElectiveGroups returns every elective group of a school, newest first
+func GetElectiveGroups(schoolId models.SchoolId) ([]models.ElectiveGroup, error) {
+	return stores.Electives.GetElectiveGroups(schoolId)
+}
+
+func (s sqlElectiveStore) GetElectiveGroups(schoolId models.SchoolId) ([]models.ElectiveGroup, error) {
+	rows, err := s.q.Query("SELECT "+electiveGroupColumns+" FROM elective_groups WHERE school_id = ? ORDER BY id DESC", schoolId)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]models.ElectiveGroup, 0)
+	for rows.Next() {
+		group, err := scanElectiveGroup(rows)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		groups = append(groups, *group)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 커넥션 하나로 도는 트랜잭션에서도 쓸 수 있도록 rows를 닫은 뒤에 과목을 불러옴
+	for i := range groups {
+		if err := s.loadOptions(&groups[i]); err != nil {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+// GetElectiveGroup returns an elective group with its options
+func GetElectiveGroup(id models.DbId) (*models.ElectiveGroup, error) {
+	return stores.Electives.GetElectiveGroup(id)
+}
+
+func (s sqlElectiveStore) GetElectiveGroup(id models.DbId) (*models.ElectiveGroup, error) {
+	return s.getElectiveGroup("SELECT "+electiveGroupColumns+" FROM elective_groups WHERE id = ?", id)
+}
+
+// LockElectiveGroup 같은 그룹에 대한 신청과 배정이 한번에 하나씩만 진행되도록 트랜잭션이 끝날 때까지 행을 잠금
+// WithTx 안에서만 의미가 있으므로 패키지 함수는 따로 두지 않음
+func (s sqlElectiveStore) LockElectiveGroup(id models.DbId) (*models.ElectiveGroup, error) {
+	return s.getElectiveGroup("SELECT "+electiveGroupColumns+" FROM elective_groups WHERE id = ? FOR UPDATE", id)
+}
+
+func (s sqlElectiveStore) getElectiveGroup(query string, id models.DbId) (*models.ElectiveGroup, error) {
+	group, err := scanElectiveGroup(s.q.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+	err = s.loadOptions(group)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// GetDueElectiveGroups returns the groups whose window has closed but that are not allocated yet
+func GetDueElectiveGroups(now time.Time) ([]models.DbId, error) {
+	return stores.Electives.GetDueElectiveGroups(now)
+}
+
+func (s sqlElectiveStore) GetDueElectiveGroups(now time.Time) ([]models.DbId, error) {
+	rows, err := s.q.Query("SELECT id FROM elective_groups WHERE allocated_at IS NULL AND closes_at <= ? ORDER BY closes_at", now)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	ids := make([]models.DbId, 0)
+	for rows.Next() {
+		var id models.DbId
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CreateElectiveGroup creates an elective group and its options
+// 신청 기간은 SetElectiveWindow로 따로 엶
+func CreateElectiveGroup(group *models.ElectiveGroup) (models.DbId, error) {
+	return stores.Electives.CreateElectiveGroup(group)
+}
+
+func (s sqlElectiveStore) CreateElectiveGroup(group *models.ElectiveGroup) (models.DbId, error) {
+	err := utils.ValidateElectiveGroup(group)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := s.q.Exec("INSERT INTO elective_groups (school_id, name, grade, created_by) VALUES (?, ?, ?, ?)", group.SchoolId, group.Name, group.Grade, group.CreatedBy[:])
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	group.ID = models.DbId(id)
+	group.Version = 1
+
+	for i := range group.Options {
+		option := &group.Options[i]
+		entries, err := json.Marshal(option.Entries)
+		if err != nil {
+			return 0, err
+		}
+		result, err := s.q.Exec("INSERT INTO elective_options (group_id, name, capacity, entries) VALUES (?, ?, ?, ?)", group.ID, option.Name, option.Capacity, string(entries))
+		if err != nil {
+			return 0, err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+		option.ID = models.DbId(id)
+		option.Taken = 0
+	}
+	return group.ID, nil
+}
+
+// SetElectiveWindow opens the group for choices between group.OpensAt and group.ClosesAt in group.Mode
+// group.Version이 DB에 저장된 값과 다르면 ErrVersionConflict를, 이미 배정이 끝났으면 ErrElectiveClosed를 반환함
+func SetElectiveWindow(group *models.ElectiveGroup) error {
+	return stores.Electives.SetElectiveWindow(group)
+}
+
+func (s sqlElectiveStore) SetElectiveWindow(group *models.ElectiveGroup) error {
+	err := utils.ValidateElectiveWindow(group)
+	if err != nil {
+		return err
+	}
+	if group.AllocatedAt != nil {
+		return ErrElectiveClosed
+	}
+
+	query := "UPDATE elective_groups SET mode = ?, opens_at = ?, closes_at = ?, version = version + 1 WHERE id = ? AND version = ? AND allocated_at IS NULL"
+	result, err := s.q.Exec(query, group.Mode, group.OpensAt, group.ClosesAt, group.ID, group.Version)
+	if err != nil {
+		return err
+	}
+	err = checkVersioned(result)
+	if err != nil {
+		return err
+	}
+	group.Version++
+	return nil
+}
+
+// DeleteElectiveGroup deletes a group with its options and choices
+// 이미 배정된 수업은 학생 시간표에 그대로 남음
+// version이 DB에 저장된 값과 다르면 ErrVersionConflict를 반환함
+func DeleteElectiveGroup(id models.DbId, version int64) error {
+	return stores.Electives.DeleteElectiveGroup(id, version)
+}
+
+func (s sqlElectiveStore) DeleteElectiveGroup(id models.DbId, version int64) error {
+	result, err := s.q.Exec("DELETE FROM elective_groups WHERE id = ? AND version = ?", id, version)
+	if err != nil {
+		return err
+	}
+	return checkVersioned(result)
+}
+
+// GetElectiveChoices returns every choice made in a group ordered by student and preference
+func GetElectiveChoices(groupId models.DbId) ([]models.ElectiveChoice, error) {
+	return stores.Electives.GetElectiveChoices(groupId)
+}
+
+func (s sqlElectiveStore) GetElectiveChoices(groupId models.DbId) ([]models.ElectiveChoice, error) {
+	query := "SELECT c.account_id, a.user_id, c.option_id, c.preference, c.chosen_at, c.assigned FROM elective_choices c " +
+		"JOIN accounts a ON a.id = c.account_id WHERE c.group_id = ? ORDER BY c.account_id, c.preference"
+
+	rows, err := s.q.Query(query, groupId)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	choices := make([]models.ElectiveChoice, 0)
+	for rows.Next() {
+		var choice models.ElectiveChoice
+		err := rows.Scan(&choice.AccountId, &choice.UserId, &choice.OptionId, &choice.Preference, &choice.ChosenAt, &choice.Assigned)
+		if err != nil {
+			return nil, err
+		}
+		choices = append(choices, choice)
+	}
+	return choices, rows.Err()
+}
+
+// ChooseElective replaces a student's choices in a group with optionIds, most wanted first
+// 선착순에서는 과목을 하나만 고를 수 있고, 정원이 찼으면 ErrElectiveFull을 반환함
+// 신청 기간이 아니면 ErrElectiveClosed를 반환함
+// 그룹 행을 잠그고 정원을 세므로 WithTx 안에서 불러야 함
+func ChooseElective(group *models.ElectiveGroup, account *models.Account, optionIds []models.DbId, now time.Time) error {
+	return stores.Electives.ChooseElective(group, account, optionIds, now)
+}
+
+func (s sqlElectiveStore) ChooseElective(group *models.ElectiveGroup, account *models.Account, optionIds []models.DbId, now time.Time) error {
+	locked, err := s.LockElectiveGroup(group.ID)
+	if err != nil {
+		return err
+	}
+	if !locked.IsOpen(now) {
+		return ErrElectiveClosed
+	}
+	err = utils.ValidateElectiveChoice(locked, optionIds)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.q.Exec("DELETE FROM elective_choices WHERE group_id = ? AND account_id = ?", group.ID, account.DbId)
+	if err != nil {
+		return err
+	}
+
+	if locked.Mode == models.FirstComeElective {
+		// 그룹 행을 잠갔으므로 지금 센 자리 수는 커밋할 때까지 바뀌지 않음
+		// 자기 예전 선택은 위에서 지웠으므로 같은 과목을 다시 골라도 자리를 두 번 세지 않음
+		option := locked.Option(optionIds[0])
+		var taken int
+		err = s.q.QueryRow("SELECT COUNT(*) FROM elective_choices WHERE option_id = ? AND assigned", option.ID).Scan(&taken)
+		if err != nil {
+			return err
+		}
+		if taken >= option.Capacity {
+			return ErrElectiveFull
+		}
+		_, err = s.q.Exec("INSERT INTO elective_choices (group_id, account_id, option_id, preference, assigned) VALUES (?, ?, ?, 1, TRUE)", group.ID, account.DbId, option.ID)
+		return err
+	}
+
+	for i, id := range optionIds {
+		_, err = s.q.Exec("INSERT INTO elective_choices (group_id, account_id, option_id, preference) VALUES (?, ?, ?, ?)", group.ID, account.DbId, id, i+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithdrawElective removes every choice a student made in a group
+// 신청 기간이 아니면 ErrElectiveClosed를 반환함
+func WithdrawElective(group *models.ElectiveGroup, account *models.Account, now time.Time) error {
+	return stores.Electives.WithdrawElective(group, account, now)
+}
+
+func (s sqlElectiveStore) WithdrawElective(group *models.ElectiveGroup, account *models.Account, now time.Time) error {
+	locked, err := s.LockElectiveGroup(group.ID)
+	if err != nil {
+		return err
+	}
+	if !locked.IsOpen(now) {
+		return ErrElectiveClosed
+	}
+
+	result, err := s.q.Exec("DELETE FROM elective_choices WHERE group_id = ? AND account_id = ?", group.ID, account.DbId)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkElectivesAllocated records the allocation and closes the group for good
+func MarkElectivesAllocated(group *models.ElectiveGroup, allocation models.ElectiveAllocation, now time.Time) error {
+	return stores.Electives.MarkElectivesAllocated(group, allocation, now)
+}
+
+func (s sqlElectiveStore) MarkElectivesAllocated(group *models.ElectiveGroup, allocation models.ElectiveAllocation, now time.Time) error {
+	_, err := s.q.Exec("UPDATE elective_choices SET assigned = FALSE WHERE group_id = ?", group.ID)
+	if err != nil {
+		return err
+	}
+	for _, assignment := range allocation.Assigned {
+		_, err = s.q.Exec("UPDATE elective_choices SET assigned = TRUE WHERE group_id = ? AND account_id = ? AND option_id = ?", group.ID, assignment.AccountId, assignment.OptionId)
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := s.q.Exec("UPDATE elective_groups SET allocated_at = ?, version = version + 1 WHERE id = ? AND allocated_at IS NULL", now, group.ID)
+	if err != nil {
+		return err
+	}
+	err = checkVersioned(result)
+	if err != nil {
+		return err
+	}
+	group.AllocatedAt = &now
+	group.Version++
+	return nil
+}
+
+// AllocateElectiveGroup closes a group, decides who takes which option and adds the lessons to their timetables
+// 이미 배정이 끝난 그룹이면 ErrElectiveClosed를 반환함
+func AllocateElectiveGroup(ctx context.Context, id models.DbId, now time.Time) (*models.ElectiveAllocation, error) {
+	var allocation models.ElectiveAllocation
+	err := WithTx(ctx, func(tx Stores) error {
+		group, err := tx.Electives.LockElectiveGroup(id)
+		if err != nil {
+			return err
+		}
+		if group.AllocatedAt != nil {
+			return ErrElectiveClosed
+		}
+
+		choices, err := tx.Electives.GetElectiveChoices(group.ID)
+		if err != nil {
+			return err
+		}
+		// 그룹마다 섞는 순서가 고정되어 있어서 같은 신청으로 다시 돌려도 결과가 같음
+		allocation = models.AllocateElectives(group, choices, int64(group.ID))
+
+		for _, assignment := range allocation.Assigned {
+			account, err := tx.Accounts.GetAccountByDbId(assignment.AccountId)
+			if err != nil {
+				return err
+			}
+			for _, entryId := range group.Option(assignment.OptionId).Entries {
+				err = tx.Accounts.AddTimetableEntry(account, entryId)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Electives.MarkElectivesAllocated(group, allocation, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &allocation, nil
+}
+
+// StartElectiveAllocator 신청 기간이 끝난 선택과목을 interval마다 찾아서 배정하는 고루틴을 시작함
+func StartElectiveAllocator(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			now := time.Now()
+			ids, err := GetDueElectiveGroups(now)
+			if err != nil {
+				log.Printf("Error finding elective groups to allocate: %s", err.Error())
+			}
+			for _, id := range ids {
+				allocation, err := AllocateElectiveGroup(context.Background(), id, now)
+				if err == ErrElectiveClosed {
+					continue
+				}
+				if err != nil {
+					log.Printf("Error allocating elective group %d: %s", id, err.Error())
+					continue
+				}
+				log.Printf("Allocated elective group %d: %d assigned, %d unassigned", id, len(allocation.Assigned), len(allocation.Unassigned))
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,176 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+)
+
+const eventColumns = "id, school_id, month, events, version, deleted_at"
+
+type sqlEventStore struct {
+	q ctxQuerier
+}
+
+func scanEvents(row scanner) (*models.Events, error) {
+	var events models.Events
+	var contents string
+	err := row.Scan(&events.ID, &events.SchoolId, &events.Month, &contents, &events.Version, &events.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(contents), &events.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	return &events, nil
+}
+
+// GetAllEvents returns all events
+func GetAllEvents() (*models.Events, error) {
+	return stores.Events.GetAllEvents()
+}
+
+func (s sqlEventStore) GetAllEvents() (*models.Events, error) {
+	// Prepare query
+	query := "SELECT " + eventColumns + " FROM schoolevents WHERE deleted_at IS NULL"
+
+	// Execute query
+	row := s.q.QueryRow(query)
+
+	// Scan row into events object
+	return scanEvents(row)
+}
+
+// GetEventsByMonth returns an event by Month
+func GetEventsByMonth(month int) (*models.Events, error) {
+	return stores.Events.GetEventsByMonth(month)
+}
+
+func (s sqlEventStore) GetEventsByMonth(month int) (*models.Events, error) {
+	// Prepare query
+	query := "SELECT " + eventColumns + " FROM schoolevents WHERE month = ? AND deleted_at IS NULL"
+
+	// Execute query
+	row := s.q.QueryRow(query, month)
+
+	// Scan row into events object
+	return scanEvents(row)
+}
+
+// GetSchoolEventsByMonth returns a month of events of one school
+func GetSchoolEventsByMonth(schoolId models.SchoolId, month int) (*models.Events, error) {
+	return stores.Events.GetSchoolEventsByMonth(schoolId, month)
+}
+
+func (s sqlEventStore) GetSchoolEventsByMonth(schoolId models.SchoolId, month int) (*models.Events, error) {
+	query := "SELECT " + eventColumns + " FROM schoolevents WHERE school_id = ? AND month = ? AND deleted_at IS NULL"
+	return scanEvents(s.q.QueryRow(query, schoolId, month))
+}
+
+// CreateEvents creates a new event
+func CreateEvents(events *models.Events) (models.DbId, error) {
+	return stores.Events.CreateEvents(events)
+}
+
+func (s sqlEventStore) CreateEvents(events *models.Events) (models.DbId, error) {
+	err := utils.ValidateEvents(events)
+	if err != nil {
+		return 0, err
+	}
+	contents, err := json.Marshal(events.Events)
+	if err != nil {
+		return 0, err
+	}
+
+	// Prepare query
+	query := "INSERT INTO schoolevents (school_id, month, events) VALUES (?, ?, ?)"
+
+	// Execute query
+	result, err := s.q.Exec(query, events.SchoolId, events.Month, string(contents))
+	if err != nil {
+		return 0, err
+	}
+
+	// Get the ID of the newly created events
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	events.ID = models.DbId(id)
+	events.Version = 1
+
+	return events.ID, nil
+}
+
+// DeleteEvents moves a month of events to the trash
+// version이 DB에 저장된 값과 다르면 ErrVersionConflict를 반환함
+func DeleteEvents(id models.DbId, version int64) error {
+	return stores.Events.DeleteEvents(id, version)
+}
+
+func (s sqlEventStore) DeleteEvents(id models.DbId, version int64) error {
+	// 실제로 지우지 않고 휴지통으로 옮김, 보관 기간이 지나면 PurgeTrash가 지움
+	query := "UPDATE schoolevents SET deleted_at = NOW(), version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL"
+
+	result, err := s.q.Exec(query, id, version)
+	if err != nil {
+		return err
+	}
+	return checkVersioned(result)
+}
+
+// GetEventsById returns a month of events by ID
+func GetEventsById(id models.DbId) (*models.Events, error) {
+	return stores.Events.GetEventsById(id)
+}
+
+func (s sqlEventStore) GetEventsById(id models.DbId) (*models.Events, error) {
+	query := "SELECT " + eventColumns + " FROM schoolevents WHERE id = ? AND deleted_at IS NULL"
+	return scanEvents(s.q.QueryRow(query, id))
+}
+
+// GetDeletedEvents returns every month of events in the trash
+func GetDeletedEvents() ([]models.Events, error) {
+	return stores.Events.GetDeletedEvents()
+}
+
+func (s sqlEventStore) GetDeletedEvents() ([]models.Events, error) {
+	query := "SELECT " + eventColumns + " FROM schoolevents WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+
+	rows, err := s.q.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	result := make([]models.Events, 0)
+	for rows.Next() {
+		events, err := scanEvents(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *events)
+	}
+	return result, rows.Err()
+}
+
+// RestoreEvents takes a month of events out of the trash
+func RestoreEvents(id models.DbId) error {
+	return stores.Events.RestoreEvents(id)
+}
+
+func (s sqlEventStore) RestoreEvents(id models.DbId) error {
+	query := "UPDATE schoolevents SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL"
+
+	result, err := s.q.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	return checkRestored(result)
+}
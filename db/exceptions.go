@@ -0,0 +1,128 @@
+package db
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"time"
+)
+
+const timetableExceptionColumns = "id, school_id, entry_id, date, kind, period, teacher_id, location, note, created_by"
+
+func scanTimetableException(row scanner) (*models.TimetableException, error) {
+	var exception models.TimetableException
+	var date time.Time
+	var period sql.NullInt64
+	var teacher []byte
+	err := row.Scan(&exception.ID, &exception.SchoolId, &exception.EntryId, &date, &exception.Kind, &period, &teacher, &exception.Location, &exception.Note, &exception.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	exception.Date = date.Format("2006-01-02")
+	if period.Valid {
+		p := models.Period(period.Int64)
+		exception.Period = &p
+	}
+	if teacher != nil {
+		id, err := uuid.FromBytes(teacher)
+		if err != nil {
+			return nil, err
+		}
+		exception.TeacherId = &id
+	}
+	return &exception, nil
+}
+
+// GetTimetableExceptions returns the exceptions of a school's lessons between from and to, inclusive
+// 휴지통에 있는 수업의 예외는 빠짐
+func GetTimetableExceptions(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.TimetableException, error) {
+	return stores.Timetables.GetTimetableExceptions(schoolId, from, to)
+}
+
+func (s sqlTimetableStore) GetTimetableExceptions(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.TimetableException, error) {
+	query := "SELECT " + timetableExceptionColumns + " FROM timetable_exceptions WHERE school_id = ? AND date BETWEEN ? AND ? " +
+		"AND entry_id IN (SELECT id FROM timetables WHERE deleted_at IS NULL) ORDER BY date, id"
+
+	rows, err := s.q.Query(query, schoolId, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	exceptions := make([]models.TimetableException, 0)
+	for rows.Next() {
+		exception, err := scanTimetableException(rows)
+		if err != nil {
+			return nil, err
+		}
+		exceptions = append(exceptions, *exception)
+	}
+	return exceptions, rows.Err()
+}
+
+// GetTimetableException returns an exception by ID
+func GetTimetableException(id models.DbId) (*models.TimetableException, error) {
+	return stores.Timetables.GetTimetableException(id)
+}
+
+func (s sqlTimetableStore) GetTimetableException(id models.DbId) (*models.TimetableException, error) {
+	query := "SELECT " + timetableExceptionColumns + " FROM timetable_exceptions WHERE id = ?"
+	return scanTimetableException(s.q.QueryRow(query, id))
+}
+
+// CreateTimetableException changes a lesson on one date
+// 그 날짜에 이미 예외가 있으면 ErrDuplicate를 반환함, 바꾸려면 먼저 지워야 함
+func CreateTimetableException(exception *models.TimetableException) (models.DbId, error) {
+	return stores.Timetables.CreateTimetableException(exception)
+}
+
+func (s sqlTimetableStore) CreateTimetableException(exception *models.TimetableException) (models.DbId, error) {
+	err := utils.ValidateTimetableException(exception)
+	if err != nil {
+		return 0, err
+	}
+
+	var teacher []byte
+	if exception.TeacherId != nil {
+		teacher = exception.TeacherId[:]
+	}
+	query := "INSERT INTO timetable_exceptions (school_id, entry_id, date, kind, period, teacher_id, location, note, created_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	result, err := s.q.Exec(query, exception.SchoolId, exception.EntryId, exception.Date, exception.Kind, exception.Period, teacher, exception.Location, exception.Note, exception.CreatedBy[:])
+	if isDuplicate(err) {
+		return 0, ErrDuplicate
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	exception.ID = models.DbId(id)
+	return exception.ID, nil
+}
+
+// DeleteTimetableException removes an exception so the lesson takes place as usual on that date
+// 없는 예외면 sql.ErrNoRows를 반환함
+func DeleteTimetableException(id models.DbId) error {
+	return stores.Timetables.DeleteTimetableException(id)
+}
+
+func (s sqlTimetableStore) DeleteTimetableException(id models.DbId) error {
+	result, err := s.q.Exec("DELETE FROM timetable_exceptions WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"github.com/username/schoolapp/models"
+)
+
+// 달력 구독 토큰은 원문 대신 SHA-256 해시만 저장함
+// Account에 들어가지 않으므로 계정 캐시를 무효화할 필요가 없음
+
+// GetAccountByFeedToken returns the account a calendar feed token belongs to
+func GetAccountByFeedToken(tokenHash string) (*models.Account, error) {
+	return stores.Accounts.GetAccountByFeedToken(tokenHash)
+}
+
+func (s sqlAccountStore) GetAccountByFeedToken(tokenHash string) (*models.Account, error) {
+	query := "SELECT " + accountColumns + " FROM accounts WHERE id = (SELECT account_id FROM calendar_feeds WHERE token_hash = ?)"
+
+	flat, err := scanFlatAccount(s.q.QueryRow(query, tokenHash))
+	if err != nil {
+		return nil, err
+	}
+	return s.restore(flat)
+}
+
+// SetFeedToken replaces the calendar feed token of an account, so the old feed URL stops working
+func SetFeedToken(account *models.Account, tokenHash string) error {
+	return stores.Accounts.SetFeedToken(account, tokenHash)
+}
+
+func (s sqlAccountStore) SetFeedToken(account *models.Account, tokenHash string) error {
+	query := "INSERT INTO calendar_feeds (account_id, token_hash) VALUES (?, ?) ON DUPLICATE KEY UPDATE token_hash = VALUES(token_hash), created_at = CURRENT_TIMESTAMP"
+
+	_, err := s.q.Exec(query, account.DbId, tokenHash)
+	return err
+}
+
+// DeleteFeedToken turns off the calendar feed of an account
+func DeleteFeedToken(account *models.Account) error {
+	return stores.Accounts.DeleteFeedToken(account)
+}
+
+func (s sqlAccountStore) DeleteFeedToken(account *models.Account) error {
+	query := "DELETE FROM calendar_feeds WHERE account_id = ?"
+
+	_, err := s.q.Exec(query, account.DbId)
+	return err
+}
@@ -0,0 +1,348 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"strings"
+	"time"
+)
+
+// ImportAction tells what ImportTimetableEntry did with an imported lesson
+type ImportAction int
+
+const (
+	ImportCreated   ImportAction = iota
+	ImportUpdated                // 과목이나 교실이 바뀌어서 고침
+	ImportUnchanged              // 이미 같은 값으로 있음
+	ImportSkipped                // 관리자가 휴지통으로 옮긴 수업이라 되살리지 않음
+)
+
+// ImportTimetableEntry creates or updates the lesson imported under externalKey
+// 같은 externalKey로 다시 가져오면 새로 만들지 않고 기존 수업을 고치므로 여러 번 실행해도 결과가 같음
+func ImportTimetableEntry(entry *models.TimetableEntry, externalKey string) (ImportAction, error) {
+	return stores.Timetables.ImportTimetableEntry(entry, externalKey)
+}
+
+func (s sqlTimetableStore) ImportTimetableEntry(entry *models.TimetableEntry, externalKey string) (ImportAction, error) {
+	err := utils.ValidateTimeTableEntry(entry)
+	if err != nil {
+		return 0, err
+	}
+
+	query := "SELECT " + timetableColumns + " FROM timetables WHERE external_key = ? FOR UPDATE"
+	existing, err := scanTimetableEntry(s.q.QueryRow(query, externalKey))
+	if errors.Is(err, sql.ErrNoRows) {
+		insert := "INSERT INTO timetables (school_id, teacher_id, location, day, period, subject, external_key) VALUES (?, ?, ?, ?, ?, ?, ?)"
+		result, err := s.q.Exec(insert, entry.SchoolId, entry.TeacherId[:], entry.Location, entry.Day, entry.Period, entry.Subject, externalKey)
+		if err != nil {
+			return 0, err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+		entry.ID = models.DbId(id)
+		entry.Version = 1
+		return ImportCreated, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	entry.ID = existing.ID
+	entry.Version = existing.Version
+	if existing.DeletedAt != nil {
+		return ImportSkipped, nil
+	}
+	// 가져온 뒤에 관리자가 담당 선생님을 바꿨을 수 있으므로 선생님은 덮어쓰지 않음
+	entry.TeacherId = existing.TeacherId
+	if existing.SchoolId == entry.SchoolId && existing.Location == entry.Location && existing.Day == entry.Day &&
+		existing.Period == entry.Period && existing.Subject == entry.Subject {
+		return ImportUnchanged, nil
+	}
+
+	err = s.UpdateTimetable(entry)
+	if err != nil {
+		return 0, err
+	}
+	return ImportUpdated, nil
+}
+
+// RetireImportedTimetables moves the lessons imported under keyPrefix that are not in keep to the trash
+// NEIS가 더 이상 주지 않는 수업이므로 external_key를 지워서, 나중에 같은 시간이 다시 생기면 관리자가 지운 수업처럼 건너뛰지 않고 새로 만들게 함
+// 휴지통으로 옮긴 수업의 ID를 반환함
+func RetireImportedTimetables(keyPrefix string, keep []string) ([]models.DbId, error) {
+	return stores.Timetables.RetireImportedTimetables(keyPrefix, keep)
+}
+
+func (s sqlTimetableStore) RetireImportedTimetables(keyPrefix string, keep []string) ([]models.DbId, error) {
+	query := "SELECT id, external_key FROM timetables WHERE external_key LIKE ? AND deleted_at IS NULL FOR UPDATE"
+	rows, err := s.q.Query(query, escapeLike(keyPrefix)+"%")
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make(map[string]bool, len(keep))
+	for _, key := range keep {
+		kept[key] = true
+	}
+	retired := make([]models.DbId, 0)
+	for rows.Next() {
+		var id models.DbId
+		var key string
+		if err := rows.Scan(&id, &key); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		if !kept[key] {
+			retired = append(retired, id)
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(retired) == 0 {
+		return retired, nil
+	}
+
+	placeholders := strings.Repeat(", ?", len(retired))[2:]
+	args := make([]interface{}, len(retired))
+	for i, id := range retired {
+		args[i] = id
+	}
+	update := "UPDATE timetables SET deleted_at = NOW(), external_key = NULL, version = version + 1 WHERE id IN (" + placeholders + ")"
+	_, err = s.q.Exec(update, args...)
+	if err != nil {
+		return nil, err
+	}
+	return retired, nil
+}
+
+// escapeLike LIKE 패턴에서 특별한 뜻을 가진 문자를 그대로 비교하도록 바꿈
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
+}
+
+// EnrollClass adds the given lessons to every student in a class
+// 실제로 수업이 추가된 학생의 ID를 반환함
+func EnrollClass(schoolId models.SchoolId, grade int, class int, entryIds []models.DbId) ([]uuid.UUID, error) {
+	return stores.Accounts.EnrollClass(schoolId, grade, class, entryIds)
+}
+
+func (s sqlAccountStore) EnrollClass(schoolId models.SchoolId, grade int, class int, entryIds []models.DbId) ([]uuid.UUID, error) {
+	enrolled := make([]uuid.UUID, 0)
+	if len(entryIds) == 0 {
+		return enrolled, nil
+	}
+
+	query := "SELECT id, user_id FROM accounts WHERE school_id = ? AND grade = ? AND class = ? AND permission_level = ?"
+	rows, err := s.q.Query(query, schoolId, grade, class, models.STUDENT)
+	if err != nil {
+		return nil, err
+	}
+	type student struct {
+		id     models.DbId
+		userId uuid.UUID
+	}
+	students := make([]student, 0)
+	for rows.Next() {
+		var st student
+		if err := rows.Scan(&st.id, &st.userId); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		students = append(students, st)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	placeholders := strings.Repeat(", ?", len(entryIds))[2:]
+	insert := "INSERT IGNORE INTO student_timetable_entries (account_id, entry_id) SELECT ?, id FROM timetables WHERE id IN (" + placeholders + ")"
+	for _, st := range students {
+		args := make([]interface{}, 0, len(entryIds)+1)
+		args = append(args, st.id)
+		for _, id := range entryIds {
+			args = append(args, id)
+		}
+		result, err := s.q.Exec(insert, args...)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if affected == 0 {
+			continue
+		}
+		_, err = s.q.Exec("UPDATE accounts SET version = version + 1 WHERE id = ?", st.id)
+		if err != nil {
+			return nil, err
+		}
+		enrolled = append(enrolled, st.userId)
+	}
+	return enrolled, nil
+}
+
+// UnenrollEntries drops the given lessons from every student's timetable
+// 수업이 빠진 학생의 ID를 반환함
+func UnenrollEntries(entryIds []models.DbId) ([]uuid.UUID, error) {
+	return stores.Accounts.UnenrollEntries(entryIds)
+}
+
+func (s sqlAccountStore) UnenrollEntries(entryIds []models.DbId) ([]uuid.UUID, error) {
+	unenrolled := make([]uuid.UUID, 0)
+	if len(entryIds) == 0 {
+		return unenrolled, nil
+	}
+
+	placeholders := strings.Repeat(", ?", len(entryIds))[2:]
+	args := make([]interface{}, len(entryIds))
+	for i, id := range entryIds {
+		args[i] = id
+	}
+
+	query := "SELECT DISTINCT a.id, a.user_id FROM student_timetable_entries e JOIN accounts a ON a.id = e.account_id WHERE e.entry_id IN (" + placeholders + ")"
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]models.DbId, 0)
+	for rows.Next() {
+		var id models.DbId
+		var userId uuid.UUID
+		if err := rows.Scan(&id, &userId); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+		unenrolled = append(unenrolled, userId)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	_, err = s.q.Exec("DELETE FROM student_timetable_entries WHERE entry_id IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		_, err = s.q.Exec("UPDATE accounts SET version = version + 1 WHERE id = ?", id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return unenrolled, nil
+}
+
+const timetableImportColumns = "id, school_id, grade, class, teacher_id, last_run_at, last_error"
+
+type sqlImportStore struct {
+	q ctxQuerier
+}
+
+func scanTimetableImport(row scanner) (*models.TimetableImport, error) {
+	var job models.TimetableImport
+	err := row.Scan(&job.ID, &job.SchoolId, &job.Grade, &job.Class, &job.TeacherId, &job.LastRunAt, &job.LastError)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetTimetableImports returns every class that is synced from NEIS on a schedule
+func GetTimetableImports() ([]models.TimetableImport, error) {
+	return stores.Imports.GetTimetableImports()
+}
+
+func (s sqlImportStore) GetTimetableImports() ([]models.TimetableImport, error) {
+	query := "SELECT " + timetableImportColumns + " FROM timetable_imports ORDER BY school_id, grade, class"
+	rows, err := s.q.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	jobs := make([]models.TimetableImport, 0)
+	for rows.Next() {
+		job, err := scanTimetableImport(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// SaveTimetableImport schedules a class for syncing, or changes its teacher if it is already scheduled
+func SaveTimetableImport(job *models.TimetableImport) error {
+	return stores.Imports.SaveTimetableImport(job)
+}
+
+func (s sqlImportStore) SaveTimetableImport(job *models.TimetableImport) error {
+	query := "INSERT INTO timetable_imports (school_id, grade, class, teacher_id) VALUES (?, ?, ?, ?) ON DUPLICATE KEY UPDATE teacher_id = VALUES(teacher_id)"
+	_, err := s.q.Exec(query, job.SchoolId, job.Grade, job.Class, job.TeacherId[:])
+	if err != nil {
+		return err
+	}
+
+	// ON DUPLICATE KEY UPDATE에서는 LastInsertId를 믿을 수 없으므로 다시 읽음
+	query = "SELECT " + timetableImportColumns + " FROM timetable_imports WHERE school_id = ? AND grade = ? AND class = ?"
+	saved, err := scanTimetableImport(s.q.QueryRow(query, job.SchoolId, job.Grade, job.Class))
+	if err != nil {
+		return err
+	}
+	*job = *saved
+	return nil
+}
+
+// RecordTimetableImport stores when a scheduled import last ran and why it failed, if it did
+func RecordTimetableImport(id models.DbId, runAt time.Time, runErr error) error {
+	return stores.Imports.RecordTimetableImport(id, runAt, runErr)
+}
+
+// maxImportErrorLength timetable_imports.last_error 열의 길이(글자 수)
+const maxImportErrorLength = 1024
+
+func (s sqlImportStore) RecordTimetableImport(id models.DbId, runAt time.Time, runErr error) error {
+	message := ""
+	if runErr != nil {
+		message = runErr.Error()
+	}
+	// NEIS 에러 메시지가 열보다 길면 저장이 실패하므로 글자 단위로 자름
+	if runes := []rune(message); len(runes) > maxImportErrorLength {
+		message = string(runes[:maxImportErrorLength-3]) + "..."
+	}
+	_, err := s.q.Exec("UPDATE timetable_imports SET last_run_at = ?, last_error = ? WHERE id = ?", runAt, message, id)
+	return err
+}
+
+// DeleteTimetableImport stops syncing a class, lessons that were already imported stay
+// 없는 항목이면 sql.ErrNoRows를 반환함
+func DeleteTimetableImport(id models.DbId) error {
+	return stores.Imports.DeleteTimetableImport(id)
+}
+
+func (s sqlImportStore) DeleteTimetableImport(id models.DbId) error {
+	result, err := s.q.Exec("DELETE FROM timetable_imports WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"time"
+)
+
+const menuColumns = "id, school_id, meal_name, date, contents, dishes, calories, nutrients, origins, version, deleted_at"
+
+type sqlMenuStore struct {
+	q ctxQuerier
+}
+
+func scanMenu(row scanner) (*models.CafeteriaMenu, error) {
+	var menu models.CafeteriaMenu
+	var date time.Time
+	var dishes, nutrients, origins sql.NullString
+	err := row.Scan(&menu.ID, &menu.SchoolId, &menu.MealName, &date, &menu.Contents, &dishes, &menu.Calories, &nutrients, &origins, &menu.Version, &menu.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// DATE 열은 자정으로 읽히므로 입력할 때와 같은 YYYY-MM-DD로 돌려줌
+	menu.Date = date.Format("2006-01-02")
+
+	// 직접 입력한 예전 급식은 상세 정보가 NULL이므로 빈 목록으로 채움
+	menu.Dishes = make([]models.Dish, 0)
+	menu.Nutrients = make([]models.Nutrient, 0)
+	menu.Origins = make([]models.Origin, 0)
+	for _, field := range []struct {
+		column sql.NullString
+		target interface{}
+	}{{dishes, &menu.Dishes}, {nutrients, &menu.Nutrients}, {origins, &menu.Origins}} {
+		if !field.column.Valid {
+			continue
+		}
+		if err := json.Unmarshal([]byte(field.column.String), field.target); err != nil {
+			return nil, err
+		}
+	}
+	return &menu, nil
+}
+
+// menuDetails 상세 정보를 JSON 문자열로 바꿈, 비어 있으면 NULL로 저장함
+func menuDetails(menu *models.CafeteriaMenu) (dishes interface{}, nutrients interface{}, origins interface{}, err error) {
+	encode := func(value interface{}, empty bool) (interface{}, error) {
+		if empty {
+			return nil, nil
+		}
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	}
+	if dishes, err = encode(menu.Dishes, len(menu.Dishes) == 0); err != nil {
+		return
+	}
+	if nutrients, err = encode(menu.Nutrients, len(menu.Nutrients) == 0); err != nil {
+		return
+	}
+	origins, err = encode(menu.Origins, len(menu.Origins) == 0)
+	return
+}
+
+func (s sqlMenuStore) queryMenus(query string, args ...interface{}) ([]models.CafeteriaMenu, error) {
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	menus := make([]models.CafeteriaMenu, 0)
+	for rows.Next() {
+		menu, err := scanMenu(rows)
+		if err != nil {
+			return nil, err
+		}
+		menus = append(menus, *menu)
+	}
+	return menus, rows.Err()
+}
+
+// GetMenuByID returns the cafeteria menu for a specific date by ID
+func GetMenuByID(id models.DbId) (*models.CafeteriaMenu, error) {
+	return stores.Menus.GetMenuByID(id)
+}
+
+func (s sqlMenuStore) GetMenuByID(id models.DbId) (*models.CafeteriaMenu, error) {
+	// Prepare query
+	query := "SELECT " + menuColumns + " FROM cafeteria_menus WHERE id = ? AND deleted_at IS NULL"
+
+	// Execute query
+	row := s.q.QueryRow(query, id)
+
+	// Scan row into cafeteria menu object
+	return scanMenu(row)
+}
+
+// LockMenu 같은 급식에 대한 사진 추가와 순서 변경이 한번에 하나씩만 진행되도록 트랜잭션이 끝날 때까지 행을 잠금
+// WithTx 안에서만 의미가 있으므로 패키지 함수는 따로 두지 않음
+func (s sqlMenuStore) LockMenu(id models.DbId) (*models.CafeteriaMenu, error) {
+	query := "SELECT " + menuColumns + " FROM cafeteria_menus WHERE id = ? AND deleted_at IS NULL FOR UPDATE"
+	return scanMenu(s.q.QueryRow(query, id))
+}
+
+// GetMenus returns every meal of a school from from to to, both dates included
+// mealName이 비어 있지 않으면 그 식사(예: 중식)만 반환함
+func GetMenus(schoolId models.SchoolId, from time.Time, to time.Time, mealName string) ([]models.CafeteriaMenu, error) {
+	return stores.Menus.GetMenus(schoolId, from, to, mealName)
+}
+
+func (s sqlMenuStore) GetMenus(schoolId models.SchoolId, from time.Time, to time.Time, mealName string) ([]models.CafeteriaMenu, error) {
+	query := "SELECT " + menuColumns + " FROM cafeteria_menus WHERE school_id = ? AND date BETWEEN ? AND ? AND deleted_at IS NULL"
+	args := []interface{}{schoolId, from.Format("2006-01-02"), to.Format("2006-01-02")}
+	if mealName != "" {
+		query += " AND meal_name = ?"
+		args = append(args, mealName)
+	}
+	query += " ORDER BY date, id"
+
+	menus, err := s.queryMenus(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	models.SortMenus(menus)
+	return menus, nil
+}
+
+// CreateMenu creates a new cafeteria menu
+func CreateMenu(menu *models.CafeteriaMenu) (models.DbId, error) {
+	return stores.Menus.CreateMenu(menu)
+}
+
+func (s sqlMenuStore) CreateMenu(menu *models.CafeteriaMenu) (models.DbId, error) {
+	menu.SyncContents()
+	err := utils.ValidateCafeteriaMenu(menu)
+	if err != nil {
+		return 0, err
+	}
+
+	dishes, nutrients, origins, err := menuDetails(menu)
+	if err != nil {
+		return 0, err
+	}
+
+	// Prepare query to insert menu
+	menuQuery := "INSERT INTO cafeteria_menus (school_id, meal_name, date, contents, dishes, calories, nutrients, origins) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+
+	// Execute query to insert menu
+	menuResult, err := s.q.Exec(menuQuery, menu.SchoolId, menu.MealName, menu.Date, menu.Contents, dishes, menu.Calories, nutrients, origins)
+	if err != nil {
+		return 0, err
+	}
+
+	// Get the ID of the newly created menu
+	menuID, err := menuResult.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	menu.ID = models.DbId(menuID)
+	menu.Version = 1
+
+	return models.DbId(menuID), nil
+}
+
+// UpdateMenu updates a cafeteria menu
+// menu.Version이 DB에 저장된 값과 다르면 ErrVersionConflict를 반환함
+func UpdateMenu(menu *models.CafeteriaMenu) error {
+	return stores.Menus.UpdateMenu(menu)
+}
+
+func (s sqlMenuStore) UpdateMenu(menu *models.CafeteriaMenu) error {
+	menu.SyncContents()
+	err := utils.ValidateCafeteriaMenu(menu)
+	if err != nil {
+		return err
+	}
+
+	dishes, nutrients, origins, err := menuDetails(menu)
+	if err != nil {
+		return err
+	}
+
+	// Prepare query to update menu
+	menuQuery := "UPDATE cafeteria_menus SET school_id = ?, meal_name = ?, date = ?, contents = ?, dishes = ?, calories = ?, nutrients = ?, origins = ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL"
+
+	// Execute query to update menu
+	result, err := s.q.Exec(menuQuery, menu.SchoolId, menu.MealName, menu.Date, menu.Contents, dishes, menu.Calories, nutrients, origins, menu.ID, menu.Version)
+	if err != nil {
+		return err
+	}
+	err = checkVersioned(result)
+	if err != nil {
+		return err
+	}
+	menu.Version++
+
+	return nil
+}
+
+// DeleteMenu moves a cafeteria menu to the trash
+// version이 DB에 저장된 값과 다르면 ErrVersionConflict를 반환함
+func DeleteMenu(id models.DbId, version int64) error {
+	return stores.Menus.DeleteMenu(id, version)
+}
+
+func (s sqlMenuStore) DeleteMenu(id models.DbId, version int64) error {
+	// Prepare query to delete menu
+	// 실제로 지우지 않고 휴지통으로 옮김, 보관 기간이 지나면 PurgeTrash가 지움
+	deleteMenuQuery := "UPDATE cafeteria_menus SET deleted_at = NOW(), version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL"
+
+	// Execute query to delete menu
+	result, err := s.q.Exec(deleteMenuQuery, id, version)
+	if err != nil {
+		return err
+	}
+
+	return checkVersioned(result)
+}
+
+// GetDeletedMenus returns every cafeteria menu in the trash
+func GetDeletedMenus() ([]models.CafeteriaMenu, error) {
+	return stores.Menus.GetDeletedMenus()
+}
+
+func (s sqlMenuStore) GetDeletedMenus() ([]models.CafeteriaMenu, error) {
+	query := "SELECT " + menuColumns + " FROM cafeteria_menus WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	return s.queryMenus(query)
+}
+
+// RestoreMenu takes a cafeteria menu out of the trash
+func RestoreMenu(id models.DbId) error {
+	return stores.Menus.RestoreMenu(id)
+}
+
+func (s sqlMenuStore) RestoreMenu(id models.DbId) error {
+	query := "UPDATE cafeteria_menus SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL"
+
+	result, err := s.q.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	return checkRestored(result)
+}
+
+// ImportMenu creates or updates the menu of menu.SchoolId, menu.Date and menu.MealName
+// 같은 급식을 다시 가져오면 새로 만들지 않고 바뀐 내용만 고치므로 여러 번 실행해도 결과가 같음
+func ImportMenu(menu *models.CafeteriaMenu) (ImportAction, error) {
+	return stores.Menus.ImportMenu(menu)
+}
+
+func (s sqlMenuStore) ImportMenu(menu *models.CafeteriaMenu) (ImportAction, error) {
+	err := utils.ValidateCafeteriaMenu(menu)
+	if err != nil {
+		return 0, err
+	}
+
+	// 휴지통에 있는 급식도 찾아서, 관리자가 지운 급식은 되살리지 않음
+	query := "SELECT " + menuColumns + " FROM cafeteria_menus WHERE school_id = ? AND date = ? AND meal_name = ? ORDER BY deleted_at IS NULL DESC, id LIMIT 1 FOR UPDATE"
+	existing, err := scanMenu(s.q.QueryRow(query, menu.SchoolId, menu.Date, menu.MealName))
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err = s.CreateMenu(menu)
+		if err != nil {
+			return 0, err
+		}
+		return ImportCreated, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	menu.ID = existing.ID
+	menu.Version = existing.Version
+	if existing.DeletedAt != nil {
+		return ImportSkipped, nil
+	}
+	if sameMenu(existing, menu) {
+		return ImportUnchanged, nil
+	}
+
+	err = s.UpdateMenu(menu)
+	if err != nil {
+		return 0, err
+	}
+	return ImportUpdated, nil
+}
+
+func sameMenu(a *models.CafeteriaMenu, b *models.CafeteriaMenu) bool {
+	if a.Contents != b.Contents || (a.Calories == nil) != (b.Calories == nil) || (a.Calories != nil && *a.Calories != *b.Calories) {
+		return false
+	}
+	aDishes, aNutrients, aOrigins, errA := menuDetails(a)
+	bDishes, bNutrients, bOrigins, errB := menuDetails(b)
+	return errA == nil && errB == nil && aDishes == bDishes && aNutrients == bNutrients && aOrigins == bOrigins
+}
@@ -0,0 +1,345 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/username/schoolapp/models"
+	"log"
+)
+
+// migration 기존 데이터를 새 스키마에 맞게 바꾸는 작업
+// createTables는 새로 설치할 때의 스키마만 만들기 때문에, 이미 돌아가는 DB는 여기서 옮겨야 함
+// 한번 적용된 migration은 schema_migrations에 이름이 기록되어 다시 실행되지 않음
+//
+// migration은 트랜잭션 안에서 돌지만 MySQL에서 ALTER TABLE 같은 DDL은 그 자리에서 암묵적으로 커밋되므로
+// 중간에 실패하면 앞의 DDL은 되돌려지지 않음. 그래서 up은 어느 단계에서 멈췄더라도 다시 실행할 수 있어야 하고,
+// ALTER 전마다 열이나 테이블이 이미 바뀌었는지 확인해야 함
+type migration struct {
+	name string
+	up   func(tx *sql.Tx) error
+}
+
+// 순서대로 적용됨, 이미 배포된 항목의 이름이나 순서는 절대 바꾸지 말 것!
+var migrations = []migration{
+	{"0001_normalize_account_blobs", normalizeAccountBlobs},
+	{"0002_add_version_columns", addVersionColumns},
+	{"0003_add_deleted_at_columns", addDeletedAtColumns},
+	{"0004_timetable_period_numbers", timetablePeriodNumbers},
+	{"0005_add_timetable_school_id", addTimetableSchoolId},
+	{"0006_add_timetable_external_key", addTimetableExternalKey},
+	{"0007_add_menu_details", addMenuDetails},
+	{"0008_migrate_menu_contents", migrateMenuContents},
+}
+
+func migrate() {
+	createMigrations := "CREATE TABLE IF NOT EXISTS `schema_migrations` (`name` VARCHAR(255) NOT NULL PRIMARY KEY, `applied_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;"
+	_, err := db.Exec(createMigrations)
+	if err != nil {
+		log.Fatalf("Error creating migration table: %s", err.Error())
+	}
+
+	for _, m := range migrations {
+		var applied int
+		err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE name = ?", m.name).Scan(&applied)
+		if err != nil {
+			log.Fatalf("Error checking migration %s: %s", m.name, err.Error())
+		}
+		if applied > 0 {
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			log.Fatalf("Error starting migration %s: %s", m.name, err.Error())
+		}
+		err = m.up(tx)
+		if err == nil {
+			_, err = tx.Exec("INSERT INTO schema_migrations (name) VALUES (?)", m.name)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			log.Fatalf("Error applying migration %s: %s", m.name, err.Error())
+		}
+		err = tx.Commit()
+		if err != nil {
+			log.Fatalf("Error committing migration %s: %s", m.name, err.Error())
+		}
+		log.Printf("Applied migration %s", m.name)
+	}
+}
+
+// columnType returns the data type of a column in lower case, e.g. "int" or "time"
+// 열이 없으면 sql.ErrNoRows를 반환함
+func columnType(tx *sql.Tx, table string, column string) (string, error) {
+	query := "SELECT LOWER(DATA_TYPE) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?"
+
+	var dataType string
+	err := tx.QueryRow(query, table, column).Scan(&dataType)
+	if err != nil {
+		return "", err
+	}
+	return dataType, nil
+}
+
+// columnExists MySQL에서 ALTER TABLE은 IF EXISTS를 지원하지 않기 때문에 직접 확인함
+func columnExists(tx *sql.Tx, table string, column string) (bool, error) {
+	query := "SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?"
+
+	var count int
+	err := tx.QueryRow(query, table, column).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// normalizeAccountBlobs accounts.timetable_list와 accounts.friends에 바이트 배열로 저장하던 값을
+// student_timetable_entries, friendships 테이블로 옮기고 기존 열은 삭제함
+func normalizeAccountBlobs(tx *sql.Tx) error {
+	exists, err := columnExists(tx, "accounts", "timetable_list")
+	if err != nil || !exists {
+		return err
+	}
+
+	type legacyRow struct {
+		id        models.DbId
+		timetable []byte
+		friends   []byte
+	}
+
+	rows, err := tx.Query("SELECT id, timetable_list, friends FROM accounts")
+	if err != nil {
+		return err
+	}
+	var legacy []legacyRow
+	for rows.Next() {
+		var row legacyRow
+		err = rows.Scan(&row.id, &row.timetable, &row.friends)
+		if err != nil {
+			_ = rows.Close()
+			return err
+		}
+		legacy = append(legacy, row)
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	// 이미 삭제된 시간표나 계정을 가리키는 값은 외래 키 때문에 넣을 수 없으니 건너뜀
+	insertEntry := "INSERT IGNORE INTO student_timetable_entries (account_id, entry_id) SELECT ?, id FROM timetables WHERE id = ?"
+	insertFriend := "INSERT IGNORE INTO friendships (account_id, friend_id) SELECT ?, id FROM accounts WHERE user_id = ?"
+	for _, row := range legacy {
+		for _, entry := range models.Int64ArrayToDbIdArray(models.BytesToInt64Array(row.timetable)) {
+			_, err = tx.Exec(insertEntry, row.id, entry)
+			if err != nil {
+				return err
+			}
+		}
+		for _, friend := range models.BytesToUUIDArray(row.friends) {
+			_, err = tx.Exec(insertFriend, row.id, friend[:])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.Exec("ALTER TABLE accounts DROP COLUMN timetable_list, DROP COLUMN friends")
+	return err
+}
+
+// addVersionColumns 낙관적 동시성 제어에 쓰이는 version 열을 수정 가능한 테이블마다 추가함
+func addVersionColumns(tx *sql.Tx) error {
+	tables := []string{"accounts", "timetables", "cafeteria_menus", "checklists", "schoolevents"}
+	for _, table := range tables {
+		exists, err := columnExists(tx, table, "version")
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		_, err = tx.Exec("ALTER TABLE `" + table + "` ADD COLUMN `version` INT NOT NULL DEFAULT 1")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addDeletedAtColumns 휴지통 기능에 쓰이는 deleted_at 열을 추가함
+func addDeletedAtColumns(tx *sql.Tx) error {
+	for _, table := range trashTables {
+		exists, err := columnExists(tx, table, "deleted_at")
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		_, err = tx.Exec("ALTER TABLE `" + table + "` ADD COLUMN `deleted_at` DATETIME NULL, ADD KEY `idx_deleted_at` (`deleted_at`)")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// timetablePeriodNumbers timetables.period를 TIME에서 교시 번호(INT)로 바꿈
+// 예전 클라이언트가 "3"처럼 보낸 값은 MySQL이 00:00:03으로 저장했으므로 초를 그대로 교시로 씀
+// "09:00"처럼 시각으로 저장된 값은 9시를 1교시로 보고 변환함
+// period_number에 변환한 뒤 period를 지우고 이름을 바꾸므로, 어느 단계 뒤에 멈췄는지 보고 남은 단계만 함
+func timetablePeriodNumbers(tx *sql.Tx) error {
+	dataType, err := columnType(tx, "timetables", "period")
+	if err == sql.ErrNoRows {
+		// period를 지운 뒤 이름을 바꾸기 전에 멈춤
+		dataType, err = "", nil
+	}
+	if err != nil {
+		return err
+	}
+	converting, err := columnExists(tx, "timetables", "period_number")
+	if err != nil {
+		return err
+	}
+
+	if dataType == "time" {
+		if !converting {
+			_, err = tx.Exec("ALTER TABLE timetables ADD COLUMN `period_number` INT(11) NOT NULL DEFAULT 1")
+			if err != nil {
+				return err
+			}
+			converting = true
+		}
+		// 원래 period에서 다시 계산하므로 여러 번 실행해도 결과가 같음
+		_, err = tx.Exec("UPDATE timetables SET period_number = IF(TIME_TO_SEC(period) < 60, GREATEST(TIME_TO_SEC(period), 1), GREATEST(HOUR(period) - 8, 1))")
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("ALTER TABLE timetables DROP COLUMN `period`")
+		if err != nil {
+			return err
+		}
+		dataType = ""
+	}
+	if dataType != "" || !converting {
+		// 이미 교시 번호임
+		return nil
+	}
+
+	_, err = tx.Exec("ALTER TABLE timetables CHANGE COLUMN `period_number` `period` INT(11) NOT NULL")
+	return err
+}
+
+// addTimetableSchoolId 수업이 어느 학교 것인지 알 수 있도록 timetables에 school_id를 추가함
+// 기존 수업은 담당 선생님 계정의 학교로 채움
+// teacher_id는 지금까지 UUID 문자열로 저장되었으므로 accounts.user_id처럼 16바이트로 바꿔서 JOIN할 수 있게 함
+// 열을 추가한 뒤에 멈췄어도 다시 실행하면 남은 UPDATE를 마저 함
+func addTimetableSchoolId(tx *sql.Tx) error {
+	exists, err := columnExists(tx, "timetables", "school_id")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = tx.Exec("ALTER TABLE timetables ADD COLUMN `school_id` VARCHAR(255) NOT NULL DEFAULT '' AFTER `id`, ADD KEY `idx_timetables_slot` (`school_id`, `day`, `period`)")
+		if err != nil {
+			return err
+		}
+	}
+
+	// 36자 UUID 문자열만 바꾸므로 이미 16바이트인 값은 건드리지 않음
+	_, err = tx.Exec("UPDATE timetables SET teacher_id = UNHEX(REPLACE(teacher_id, '-', '')) WHERE LENGTH(teacher_id) = 36")
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("UPDATE timetables t JOIN accounts a ON a.user_id = t.teacher_id SET t.school_id = COALESCE(a.school_id, '') WHERE t.school_id = ''")
+	return err
+}
+
+// addTimetableExternalKey NEIS 같은 외부에서 가져온 수업을 다시 가져올 때 찾을 수 있도록 키를 저장함
+// 직접 만든 수업은 NULL이고, UNIQUE KEY는 NULL끼리는 겹쳐도 괜찮음
+func addTimetableExternalKey(tx *sql.Tx) error {
+	exists, err := columnExists(tx, "timetables", "external_key")
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	_, err = tx.Exec("ALTER TABLE timetables ADD COLUMN `external_key` VARCHAR(255) NULL AFTER `deleted_at`, ADD UNIQUE KEY `uq_timetables_external_key` (`external_key`)")
+	return err
+}
+
+// addMenuDetails NEIS에서 가져온 요리별 알레르기, 열량, 영양 정보, 원산지를 저장할 열을 추가함
+// 예전 급식은 NULL로 남고 contents만 있음
+func addMenuDetails(tx *sql.Tx) error {
+	exists, err := columnExists(tx, "cafeteria_menus", "dishes")
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	_, err = tx.Exec("ALTER TABLE cafeteria_menus ADD COLUMN `dishes` TEXT NULL AFTER `contents`, ADD COLUMN `calories` DOUBLE NULL AFTER `dishes`, " +
+		"ADD COLUMN `nutrients` TEXT NULL AFTER `calories`, ADD COLUMN `origins` TEXT NULL AFTER `nutrients`, " +
+		"ADD KEY `idx_cafeteria_menus_meal` (`school_id`, `date`, `meal_name`)")
+	return err
+}
+
+// migrateMenuContents 직접 입력한 예전 급식의 contents를 줄마다 요리로 나눠 dishes에 넣음
+// 알레르기 번호를 읽으려면 models.InitAllergies가 먼저 불려야 함, 그래서 main에서 db.Connect보다 먼저 부름
+// dishes가 NULL인 급식만 바꾸므로 다시 실행해도 됨
+func migrateMenuContents(tx *sql.Tx) error {
+	// InitAllergies 없이 돌면 알레르기 번호가 요리 이름에 남은 채로 저장되고 다시 고쳐지지 않음
+	if models.Allergies.Size() == 0 {
+		return errors.New("models.InitAllergies must be called before db.Connect")
+	}
+	exists, err := columnExists(tx, "cafeteria_menus", "dishes")
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("cafeteria_menus.dishes is missing, 0007_add_menu_details should run first")
+	}
+
+	rows, err := tx.Query("SELECT id, contents FROM cafeteria_menus WHERE dishes IS NULL")
+	if err != nil {
+		return err
+	}
+
+	dishes := make(map[models.DbId]string)
+	for rows.Next() {
+		var menu models.CafeteriaMenu
+		err := rows.Scan(&menu.ID, &menu.Contents)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		menu.SyncContents()
+		if len(menu.Dishes) == 0 {
+			continue
+		}
+		encoded, err := json.Marshal(menu.Dishes)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		dishes[menu.ID] = string(encoded)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// contents는 그대로 두어 예전 클라이언트가 보던 문자열이 바뀌지 않게 함
+	for id, encoded := range dishes {
+		_, err := tx.Exec("UPDATE cafeteria_menus SET dishes = ? WHERE id = ?", encoded, id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package db
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/models"
+	"strings"
+)
+
+const photoColumns = "p.id, p.menu_id, m.school_id, p.position, p.content_type, p.width, p.height, p.hidden, a.user_id, p.created_at"
+const photoTables = "menu_photos p JOIN cafeteria_menus m ON m.id = p.menu_id LEFT JOIN accounts a ON a.id = p.uploaded_by"
+
+type sqlPhotoStore struct {
+	q ctxQuerier
+}
+
+func scanPhoto(row scanner) (*models.MenuPhoto, error) {
+	var photo models.MenuPhoto
+	var uploadedBy []byte
+	err := row.Scan(&photo.ID, &photo.MenuId, &photo.SchoolId, &photo.Position, &photo.ContentType, &photo.Width, &photo.Height, &photo.Hidden, &uploadedBy, &photo.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	// 올린 계정이 지워졌으면 NULL임
+	if uploadedBy != nil {
+		id, err := uuid.FromBytes(uploadedBy)
+		if err != nil {
+			return nil, err
+		}
+		photo.UploadedBy = &id
+	}
+	photo.SetURLs()
+	return &photo, nil
+}
+
+// GetMenuPhotos returns the photos of the given menus ordered by menu and position
+// includeHidden이 false면 숨긴 사진은 빼고 반환함
+func GetMenuPhotos(menuIds []models.DbId, includeHidden bool) ([]models.MenuPhoto, error) {
+	return stores.Photos.GetMenuPhotos(menuIds, includeHidden)
+}
+
+func (s sqlPhotoStore) GetMenuPhotos(menuIds []models.DbId, includeHidden bool) ([]models.MenuPhoto, error) {
+	photos := make([]models.MenuPhoto, 0)
+	if len(menuIds) == 0 {
+		return photos, nil
+	}
+
+	placeholders := strings.Repeat(", ?", len(menuIds))[2:]
+	query := "SELECT " + photoColumns + " FROM " + photoTables + " WHERE p.menu_id IN (" + placeholders + ")"
+	if !includeHidden {
+		query += " AND NOT p.hidden"
+	}
+	query += " ORDER BY p.menu_id, p.position, p.id"
+
+	args := make([]interface{}, len(menuIds))
+	for i, id := range menuIds {
+		args[i] = id
+	}
+	return s.queryPhotos(query, args...)
+}
+
+func (s sqlPhotoStore) queryPhotos(query string, args ...interface{}) ([]models.MenuPhoto, error) {
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	photos := make([]models.MenuPhoto, 0)
+	for rows.Next() {
+		photo, err := scanPhoto(rows)
+		if err != nil {
+			return nil, err
+		}
+		photos = append(photos, *photo)
+	}
+	return photos, rows.Err()
+}
+
+// GetMenuPhoto returns a photo by ID
+func GetMenuPhoto(id models.DbId) (*models.MenuPhoto, error) {
+	return stores.Photos.GetMenuPhoto(id)
+}
+
+func (s sqlPhotoStore) GetMenuPhoto(id models.DbId) (*models.MenuPhoto, error) {
+	return scanPhoto(s.q.QueryRow("SELECT "+photoColumns+" FROM "+photoTables+" WHERE p.id = ?", id))
+}
+
+// CountMenuPhotos returns how many photos a menu has, hidden ones included
+func CountMenuPhotos(menuId models.DbId) (int, error) {
+	return stores.Photos.CountMenuPhotos(menuId)
+}
+
+func (s sqlPhotoStore) CountMenuPhotos(menuId models.DbId) (int, error) {
+	var count int
+	err := s.q.QueryRow("SELECT COUNT(*) FROM menu_photos WHERE menu_id = ?", menuId).Scan(&count)
+	return count, err
+}
+
+// CreateMenuPhoto adds a photo after the menu's other photos
+func CreateMenuPhoto(photo *models.MenuPhoto, uploader *models.Account) (models.DbId, error) {
+	return stores.Photos.CreateMenuPhoto(photo, uploader)
+}
+
+func (s sqlPhotoStore) CreateMenuPhoto(photo *models.MenuPhoto, uploader *models.Account) (models.DbId, error) {
+	query := "INSERT INTO menu_photos (menu_id, position, content_type, width, height, uploaded_by) " +
+		"SELECT ?, COALESCE(MAX(position), 0) + 1, ?, ?, ?, ? FROM menu_photos WHERE menu_id = ?"
+	result, err := s.q.Exec(query, photo.MenuId, photo.ContentType, photo.Width, photo.Height, uploader.DbId, photo.MenuId)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	saved, err := s.GetMenuPhoto(models.DbId(id))
+	if err != nil {
+		return 0, err
+	}
+	*photo = *saved
+	return photo.ID, nil
+}
+
+// SetMenuPhotoHidden hides a photo from students or shows it again
+func SetMenuPhotoHidden(id models.DbId, hidden bool) error {
+	return stores.Photos.SetMenuPhotoHidden(id, hidden)
+}
+
+func (s sqlPhotoStore) SetMenuPhotoHidden(id models.DbId, hidden bool) error {
+	_, err := s.q.Exec("UPDATE menu_photos SET hidden = ? WHERE id = ?", hidden, id)
+	return err
+}
+
+// ReorderMenuPhotos puts a menu's photos in the order of ids, which must list every photo of the menu
+// 트랜잭션 안에서 불러야 다른 요청이 사진을 더하는 사이에 순서가 섞이지 않음
+func (s sqlPhotoStore) ReorderMenuPhotos(menuId models.DbId, ids []models.DbId) error {
+	rows, err := s.q.Query("SELECT id FROM menu_photos WHERE menu_id = ? FOR UPDATE", menuId)
+	if err != nil {
+		return err
+	}
+	existing := make(map[models.DbId]bool)
+	for rows.Next() {
+		var id models.DbId
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		existing[id] = true
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(ids) != len(existing) {
+		return ErrPhotoOrder
+	}
+	for _, id := range ids {
+		if !existing[id] {
+			return ErrPhotoOrder
+		}
+		delete(existing, id)
+	}
+
+	for i, id := range ids {
+		_, err := s.q.Exec("UPDATE menu_photos SET position = ? WHERE id = ?", i+1, id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteMenuPhoto removes a photo's row, the caller removes its files
+func DeleteMenuPhoto(id models.DbId) error {
+	return stores.Photos.DeleteMenuPhoto(id)
+}
+
+func (s sqlPhotoStore) DeleteMenuPhoto(id models.DbId) error {
+	result, err := s.q.Exec("DELETE FROM menu_photos WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
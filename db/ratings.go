@@ -0,0 +1,234 @@
+package db
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/models"
+	"time"
+)
+
+const ratingColumns = "r.id, r.menu_id, m.school_id, a.user_id, r.dish, r.score, r.comment, r.comment_status, r.updated_at"
+const ratingTables = "meal_ratings r JOIN cafeteria_menus m ON m.id = r.menu_id JOIN accounts a ON a.id = r.account_id"
+
+type sqlRatingStore struct {
+	q ctxQuerier
+}
+
+func scanRating(row scanner) (*models.MealRating, error) {
+	var rating models.MealRating
+	var userId uuid.UUID
+	err := row.Scan(&rating.ID, &rating.MenuId, &rating.SchoolId, &userId, &rating.Dish, &rating.Score, &rating.Comment, &rating.CommentStatus, &rating.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	rating.UserId = &userId
+	return &rating, nil
+}
+
+func (s sqlRatingStore) queryRatings(query string, args ...interface{}) ([]models.MealRating, error) {
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	ratings := make([]models.MealRating, 0)
+	for rows.Next() {
+		rating, err := scanRating(rows)
+		if err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, *rating)
+	}
+	return ratings, rows.Err()
+}
+
+func (s sqlRatingStore) queryScores(query string, args ...interface{}) ([]models.DishScore, error) {
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	scores := make([]models.DishScore, 0)
+	for rows.Next() {
+		var score models.DishScore
+		if err := rows.Scan(&score.Dish, &score.Average, &score.Count); err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+	return scores, rows.Err()
+}
+
+// RateMeal saves a student's rating of a meal or dish, replacing their earlier rating of it
+// 코멘트가 바뀌면 다시 검토를 기다리고, 점수만 바뀌면 검토 상태를 그대로 둠
+func RateMeal(account *models.Account, rating *models.MealRating) error {
+	return stores.Ratings.RateMeal(account, rating)
+}
+
+func (s sqlRatingStore) RateMeal(account *models.Account, rating *models.MealRating) error {
+	status := models.CommentPending
+	if rating.Comment == "" {
+		status = models.CommentNone
+	}
+
+	// comment_status를 comment보다 먼저 고쳐야 예전 코멘트와 비교할 수 있음
+	query := "INSERT INTO meal_ratings (menu_id, account_id, dish, score, comment, comment_status) VALUES (?, ?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE score = VALUES(score), comment_status = IF(comment = VALUES(comment), comment_status, VALUES(comment_status)), comment = VALUES(comment)"
+	_, err := s.q.Exec(query, rating.MenuId, account.DbId, rating.Dish, rating.Score, rating.Comment, status)
+	if err != nil {
+		return err
+	}
+
+	saved, err := scanRating(s.q.QueryRow("SELECT "+ratingColumns+" FROM "+ratingTables+" WHERE r.menu_id = ? AND r.account_id = ? AND r.dish = ?", rating.MenuId, account.DbId, rating.Dish))
+	if err != nil {
+		return err
+	}
+	*rating = *saved
+	return nil
+}
+
+// GetMyRatings returns a student's ratings of one meal
+func GetMyRatings(account *models.Account, menuId models.DbId) ([]models.MealRating, error) {
+	return stores.Ratings.GetMyRatings(account, menuId)
+}
+
+func (s sqlRatingStore) GetMyRatings(account *models.Account, menuId models.DbId) ([]models.MealRating, error) {
+	query := "SELECT " + ratingColumns + " FROM " + ratingTables + " WHERE r.menu_id = ? AND r.account_id = ? ORDER BY r.dish"
+	return s.queryRatings(query, menuId, account.DbId)
+}
+
+// DeleteMealRating removes a student's rating of a meal, or of one dish when dish is not empty
+func DeleteMealRating(account *models.Account, menuId models.DbId, dish string) error {
+	return stores.Ratings.DeleteMealRating(account, menuId, dish)
+}
+
+func (s sqlRatingStore) DeleteMealRating(account *models.Account, menuId models.DbId, dish string) error {
+	result, err := s.q.Exec("DELETE FROM meal_ratings WHERE menu_id = ? AND account_id = ? AND dish = ?", menuId, account.DbId, dish)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetRating returns a rating by ID
+func GetRating(id models.DbId) (*models.MealRating, error) {
+	return stores.Ratings.GetRating(id)
+}
+
+func (s sqlRatingStore) GetRating(id models.DbId) (*models.MealRating, error) {
+	return scanRating(s.q.QueryRow("SELECT "+ratingColumns+" FROM "+ratingTables+" WHERE r.id = ?", id))
+}
+
+// GetMenuScores returns the average score of a meal and of each rated dish, the meal itself first
+func GetMenuScores(menuId models.DbId) ([]models.DishScore, error) {
+	return stores.Ratings.GetMenuScores(menuId)
+}
+
+func (s sqlRatingStore) GetMenuScores(menuId models.DbId) ([]models.DishScore, error) {
+	query := "SELECT dish, AVG(score), COUNT(*) FROM meal_ratings WHERE menu_id = ? GROUP BY dish ORDER BY dish"
+	return s.queryScores(query, menuId)
+}
+
+// GetRatingComments returns the comments on a meal with the given moderation status, oldest first
+func GetRatingComments(menuId models.DbId, status models.CommentStatus) ([]models.MealRating, error) {
+	return stores.Ratings.GetRatingComments(menuId, status)
+}
+
+func (s sqlRatingStore) GetRatingComments(menuId models.DbId, status models.CommentStatus) ([]models.MealRating, error) {
+	query := "SELECT " + ratingColumns + " FROM " + ratingTables + " WHERE r.menu_id = ? AND r.comment_status = ? ORDER BY r.updated_at, r.id"
+	return s.queryRatings(query, menuId, status)
+}
+
+// GetSchoolComments returns up to 100 comments of a school with the given moderation status, oldest first
+func GetSchoolComments(schoolId models.SchoolId, status models.CommentStatus) ([]models.MealRating, error) {
+	return stores.Ratings.GetSchoolComments(schoolId, status)
+}
+
+func (s sqlRatingStore) GetSchoolComments(schoolId models.SchoolId, status models.CommentStatus) ([]models.MealRating, error) {
+	query := "SELECT " + ratingColumns + " FROM " + ratingTables + " WHERE m.school_id = ? AND r.comment_status = ? ORDER BY r.updated_at, r.id LIMIT 100"
+	return s.queryRatings(query, schoolId, status)
+}
+
+// ModerateComment approves or rejects the comment of a rating
+func ModerateComment(id models.DbId, status models.CommentStatus) error {
+	return stores.Ratings.ModerateComment(id, status)
+}
+
+func (s sqlRatingStore) ModerateComment(id models.DbId, status models.CommentStatus) error {
+	// updated_at은 학생이 평가를 고친 시각이므로 검토할 때는 그대로 둠
+	result, err := s.q.Exec("UPDATE meal_ratings SET comment_status = ?, updated_at = updated_at WHERE id = ? AND comment_status <> ?", status, id, models.CommentNone)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		// 같은 상태로 다시 검토하면 0이 나오므로 코멘트가 있는지 한번 더 확인함
+		var count int
+		err = s.q.QueryRow("SELECT COUNT(*) FROM meal_ratings WHERE id = ? AND comment_status <> ?", id, models.CommentNone).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return sql.ErrNoRows
+		}
+	}
+	return nil
+}
+
+// GetDishScores returns the average score of every dish served at a school from from to to
+// 같은 이름의 요리는 날짜가 달라도 하나로 셈, 급식 전체에 대한 평가는 Dish가 빈 항목으로 들어감
+func GetDishScores(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.DishScore, error) {
+	return stores.Ratings.GetDishScores(schoolId, from, to)
+}
+
+func (s sqlRatingStore) GetDishScores(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.DishScore, error) {
+	query := "SELECT r.dish, AVG(r.score), COUNT(*) FROM meal_ratings r JOIN cafeteria_menus m ON m.id = r.menu_id " +
+		"WHERE m.school_id = ? AND m.date BETWEEN ? AND ? AND m.deleted_at IS NULL GROUP BY r.dish ORDER BY r.dish"
+	return s.queryScores(query, schoolId, from.Format("2006-01-02"), to.Format("2006-01-02"))
+}
+
+// GetRatingTrends returns the average score of each week's meals at a school from from to to
+func GetRatingTrends(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.RatingTrend, error) {
+	return stores.Ratings.GetRatingTrends(schoolId, from, to)
+}
+
+func (s sqlRatingStore) GetRatingTrends(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.RatingTrend, error) {
+	query := "SELECT DATE_SUB(m.date, INTERVAL WEEKDAY(m.date) DAY) AS week, AVG(r.score), COUNT(*) FROM meal_ratings r JOIN cafeteria_menus m ON m.id = r.menu_id " +
+		"WHERE m.school_id = ? AND m.date BETWEEN ? AND ? AND m.deleted_at IS NULL GROUP BY week ORDER BY week"
+
+	rows, err := s.q.Query(query, schoolId, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	trends := make([]models.RatingTrend, 0)
+	for rows.Next() {
+		var trend models.RatingTrend
+		var week time.Time
+		if err := rows.Scan(&week, &trend.Average, &trend.Count); err != nil {
+			return nil, err
+		}
+		trend.Week = week.Format("2006-01-02")
+		trends = append(trends, trend)
+	}
+	return trends, rows.Err()
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"database/sql"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+)
+
+const roomColumns = "id, school_id, name, building, capacity"
+
+type sqlRoomStore struct {
+	q ctxQuerier
+}
+
+func scanRoom(row scanner) (*models.Room, error) {
+	var room models.Room
+	err := row.Scan(&room.ID, &room.SchoolId, &room.Name, &room.Building, &room.Capacity)
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (s sqlRoomStore) queryRooms(query string, args ...interface{}) ([]models.Room, error) {
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	rooms := make([]models.Room, 0)
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, *room)
+	}
+	return rooms, rows.Err()
+}
+
+// GetRooms returns every room of a school ordered by name
+func GetRooms(schoolId models.SchoolId) ([]models.Room, error) {
+	return stores.Rooms.GetRooms(schoolId)
+}
+
+func (s sqlRoomStore) GetRooms(schoolId models.SchoolId) ([]models.Room, error) {
+	query := "SELECT " + roomColumns + " FROM rooms WHERE school_id = ? ORDER BY name"
+	return s.queryRooms(query, schoolId)
+}
+
+// GetRoom returns a room by ID
+func GetRoom(id models.DbId) (*models.Room, error) {
+	return stores.Rooms.GetRoom(id)
+}
+
+func (s sqlRoomStore) GetRoom(id models.DbId) (*models.Room, error) {
+	query := "SELECT " + roomColumns + " FROM rooms WHERE id = ?"
+	return scanRoom(s.q.QueryRow(query, id))
+}
+
+// GetAvailableRooms returns the rooms of a school with no lesson in the given day and period
+func GetAvailableRooms(schoolId models.SchoolId, day models.Weekday, period models.Period) ([]models.Room, error) {
+	return stores.Rooms.GetAvailableRooms(schoolId, day, period)
+}
+
+func (s sqlRoomStore) GetAvailableRooms(schoolId models.SchoolId, day models.Weekday, period models.Period) ([]models.Room, error) {
+	query := "SELECT " + roomColumns + " FROM rooms r WHERE r.school_id = ? AND NOT EXISTS " +
+		"(SELECT 1 FROM timetables t WHERE t.school_id = r.school_id AND t.location = r.name AND t.day = ? AND t.period = ? AND t.deleted_at IS NULL) " +
+		"ORDER BY r.name"
+	return s.queryRooms(query, schoolId, day, period)
+}
+
+// GetRoomEntries returns the lessons held in a room ordered by day and period
+func GetRoomEntries(room *models.Room) ([]models.TimetableEntry, error) {
+	return stores.Rooms.GetRoomEntries(room)
+}
+
+func (s sqlRoomStore) GetRoomEntries(room *models.Room) ([]models.TimetableEntry, error) {
+	query := "SELECT " + timetableColumns + " FROM timetables WHERE school_id = ? AND location = ? AND deleted_at IS NULL ORDER BY day, period, id"
+	return sqlTimetableStore{s.q}.queryTimetableEntries(query, room.SchoolId, room.Name)
+}
+
+// CreateRoom registers a room
+// 같은 학교에 같은 이름의 교실이 있으면 ErrDuplicate를 반환함
+func CreateRoom(room *models.Room) (models.DbId, error) {
+	return stores.Rooms.CreateRoom(room)
+}
+
+func (s sqlRoomStore) CreateRoom(room *models.Room) (models.DbId, error) {
+	err := utils.ValidateRoom(room)
+	if err != nil {
+		return 0, err
+	}
+
+	query := "INSERT INTO rooms (school_id, name, building, capacity) VALUES (?, ?, ?, ?)"
+
+	result, err := s.q.Exec(query, room.SchoolId, room.Name, room.Building, room.Capacity)
+	if isDuplicate(err) {
+		return 0, ErrDuplicate
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	room.ID = models.DbId(id)
+
+	return room.ID, nil
+}
+
+// DeleteRoom removes a room from the registry, lessons in it are left as they are
+func DeleteRoom(id models.DbId) error {
+	return stores.Rooms.DeleteRoom(id)
+}
+
+func (s sqlRoomStore) DeleteRoom(id models.DbId) error {
+	query := "DELETE FROM rooms WHERE id = ?"
+
+	_, err := s.q.Exec(query, id)
+	return err
+}
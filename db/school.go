@@ -0,0 +1,91 @@
+package db
+
+import (
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+)
+
+type sqlSchoolStore struct {
+	q ctxQuerier
+}
+
+// CreateSchool creates a new school
+func CreateSchool(school *models.School) (models.DbId, error) {
+	return stores.Schools.CreateSchool(school)
+}
+
+func (s sqlSchoolStore) CreateSchool(school *models.School) (models.DbId, error) {
+	err := utils.ValidateSchool(school)
+	if err != nil {
+		return 0, err
+	}
+	// Prepare query
+	query := "INSERT INTO schools (school_id, region_id, school_name, region_name, school_email_only, school_email) VALUES (?, ?, ?, ?, ?, ?)"
+
+	// Execute query
+	result, err := s.q.Exec(query, school.SchoolId, school.RegionId, school.SchoolName, school.RegionName, school.SchoolEmailOnly, school.SchoolEmail)
+	if err != nil {
+		return 0, err
+	}
+
+	// Get the ID of the newly created school
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	school.ID = models.DbId(id)
+
+	return school.ID, nil
+}
+
+// GetSchool returns a school by its NEIS school ID with its bell schedules
+func GetSchool(id models.SchoolId) (*models.School, error) {
+	return stores.Schools.GetSchool(id)
+}
+
+// GetSchools returns every registered school without its bell schedules
+func GetSchools() ([]models.School, error) {
+	return stores.Schools.GetSchools()
+}
+
+func (s sqlSchoolStore) GetSchools() ([]models.School, error) {
+	rows, err := s.q.Query("SELECT id, school_id, region_id, school_name, region_name, school_email_only, school_email FROM schools ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schools := make([]models.School, 0)
+	for rows.Next() {
+		var school models.School
+		err := rows.Scan(&school.ID, &school.SchoolId, &school.RegionId, &school.SchoolName, &school.RegionName, &school.SchoolEmailOnly, &school.SchoolEmail)
+		if err != nil {
+			return nil, err
+		}
+		schools = append(schools, school)
+	}
+	return schools, rows.Err()
+}
+
+func (s sqlSchoolStore) GetSchool(id models.SchoolId) (*models.School, error) {
+	// Prepare query
+	query := "SELECT * FROM schools WHERE school_id = ?"
+
+	// Execute query
+	row := s.q.QueryRow(query, id)
+
+	// Scan row into school object
+	var school models.School
+	err := row.Scan(&school.ID, &school.SchoolId, &school.RegionId, &school.SchoolName, &school.RegionName, &school.SchoolEmailOnly, &school.SchoolEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	school.BellSchedules, err = s.GetBellSchedules(school.SchoolId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &school, nil
+}
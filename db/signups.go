@@ -0,0 +1,146 @@
+package db
+
+import (
+	"database/sql"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"time"
+)
+
+const signupColumns = "s.menu_id, a.user_id, a.name, COALESCE(a.grade, 0), COALESCE(a.class, 0), COALESCE(a.number, 0), s.attending, s.updated_at, s.checked_in_at"
+const signupTables = "meal_signups s JOIN accounts a ON a.id = s.account_id"
+
+type sqlSignupStore struct {
+	q ctxQuerier
+}
+
+func scanSignup(row scanner) (*models.MealSignup, error) {
+	var signup models.MealSignup
+	var attending sql.NullBool
+	err := row.Scan(&signup.MenuId, &signup.UserId, &signup.Name, &signup.Grade, &signup.Class, &signup.Number, &attending, &signup.UpdatedAt, &signup.CheckedInAt)
+	if err != nil {
+		return nil, err
+	}
+	if attending.Valid {
+		signup.Attending = &attending.Bool
+	}
+	return &signup, nil
+}
+
+func (s sqlSignupStore) querySignups(query string, args ...interface{}) ([]models.MealSignup, error) {
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	signups := make([]models.MealSignup, 0)
+	for rows.Next() {
+		signup, err := scanSignup(rows)
+		if err != nil {
+			return nil, err
+		}
+		signups = append(signups, *signup)
+	}
+	return signups, rows.Err()
+}
+
+func (s sqlSignupStore) getSignup(menuId models.DbId, accountId models.DbId) (*models.MealSignup, error) {
+	query := "SELECT " + signupColumns + " FROM " + signupTables + " WHERE s.menu_id = ? AND s.account_id = ?"
+	return scanSignup(s.q.QueryRow(query, menuId, accountId))
+}
+
+// GetSignupPolicy returns the sign-up deadline of a school, or the default if it never set one
+func GetSignupPolicy(schoolId models.SchoolId) (*models.MealSignupPolicy, error) {
+	return stores.Signups.GetSignupPolicy(schoolId)
+}
+
+func (s sqlSignupStore) GetSignupPolicy(schoolId models.SchoolId) (*models.MealSignupPolicy, error) {
+	policy := models.DefaultMealSignupPolicy(schoolId)
+	err := s.q.QueryRow("SELECT days_before, cutoff FROM meal_signup_policies WHERE school_id = ?", schoolId).Scan(&policy.DaysBefore, &policy.Cutoff)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// SetSignupPolicy changes the sign-up deadline of a school
+func SetSignupPolicy(policy *models.MealSignupPolicy) error {
+	return stores.Signups.SetSignupPolicy(policy)
+}
+
+func (s sqlSignupStore) SetSignupPolicy(policy *models.MealSignupPolicy) error {
+	err := utils.ValidateMealSignupPolicy(policy)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO meal_signup_policies (school_id, days_before, cutoff) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE days_before = VALUES(days_before), cutoff = VALUES(cutoff)"
+	_, err = s.q.Exec(query, policy.SchoolId, policy.DaysBefore, policy.Cutoff)
+	return err
+}
+
+// SetMealSignup signs a student up for a meal, or opts them out when attending is false
+func SetMealSignup(account *models.Account, menuId models.DbId, attending bool) (*models.MealSignup, error) {
+	return stores.Signups.SetMealSignup(account, menuId, attending)
+}
+
+func (s sqlSignupStore) SetMealSignup(account *models.Account, menuId models.DbId, attending bool) (*models.MealSignup, error) {
+	query := "INSERT INTO meal_signups (menu_id, account_id, attending) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE attending = VALUES(attending), updated_at = CURRENT_TIMESTAMP"
+	_, err := s.q.Exec(query, menuId, account.DbId, attending)
+	if err != nil {
+		return nil, err
+	}
+	return s.getSignup(menuId, account.DbId)
+}
+
+// GetMySignups returns a student's sign-ups for the meals served from from to to
+func GetMySignups(account *models.Account, from time.Time, to time.Time) ([]models.MealSignup, error) {
+	return stores.Signups.GetMySignups(account, from, to)
+}
+
+func (s sqlSignupStore) GetMySignups(account *models.Account, from time.Time, to time.Time) ([]models.MealSignup, error) {
+	query := "SELECT " + signupColumns + " FROM " + signupTables + " JOIN cafeteria_menus m ON m.id = s.menu_id " +
+		"WHERE s.account_id = ? AND m.date BETWEEN ? AND ? AND m.deleted_at IS NULL ORDER BY m.date, s.menu_id"
+	return s.querySignups(query, account.DbId, from.Format("2006-01-02"), to.Format("2006-01-02"))
+}
+
+// GetSchoolSignups returns every sign-up and check-in for a school's meals from from to to
+// ordered by menu, then grade, class and number
+func GetSchoolSignups(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.MealSignup, error) {
+	return stores.Signups.GetSchoolSignups(schoolId, from, to)
+}
+
+func (s sqlSignupStore) GetSchoolSignups(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.MealSignup, error) {
+	query := "SELECT " + signupColumns + " FROM " + signupTables + " JOIN cafeteria_menus m ON m.id = s.menu_id " +
+		"WHERE m.school_id = ? AND m.date BETWEEN ? AND ? AND m.deleted_at IS NULL ORDER BY s.menu_id, a.grade, a.class, a.number"
+	return s.querySignups(query, schoolId, from.Format("2006-01-02"), to.Format("2006-01-02"))
+}
+
+// CheckInMeal records that a student ate a meal, whether or not they signed up
+// 이미 확인된 학생이면 처음 확인한 시각을 그대로 두고 false를 반환함
+func CheckInMeal(account *models.Account, menuId models.DbId, now time.Time) (*models.MealSignup, bool, error) {
+	return stores.Signups.CheckInMeal(account, menuId, now)
+}
+
+func (s sqlSignupStore) CheckInMeal(account *models.Account, menuId models.DbId, now time.Time) (*models.MealSignup, bool, error) {
+	query := "INSERT INTO meal_signups (menu_id, account_id, attending, checked_in_at) VALUES (?, ?, NULL, ?) " +
+		"ON DUPLICATE KEY UPDATE checked_in_at = COALESCE(checked_in_at, VALUES(checked_in_at))"
+	result, err := s.q.Exec(query, menuId, account.DbId, now)
+	if err != nil {
+		return nil, false, err
+	}
+	// 이미 확인된 학생이면 값이 바뀌지 않아 0이 나옴
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, false, err
+	}
+
+	signup, err := s.getSignup(menuId, account.DbId)
+	if err != nil {
+		return nil, false, err
+	}
+	return signup, affected > 0, nil
+}
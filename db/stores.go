@@ -0,0 +1,298 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/models"
+	"time"
+)
+
+// ErrVersionConflict 수정하려는 행의 version이 그 사이에 바뀌었음
+// 다른 요청이 먼저 수정했다는 뜻이므로 최신 값을 다시 읽어야 함
+var ErrVersionConflict = errors.New("version conflict")
+
+// ErrElectiveFull 선착순 선택과목의 정원이 이미 찼음
+var ErrElectiveFull = errors.New("elective option is full")
+
+// ErrElectiveClosed 선택과목 신청 기간이 아니거나 이미 배정이 끝났음
+var ErrElectiveClosed = errors.New("elective window is not open")
+
+// ErrPhotoOrder 사진 순서에 급식의 사진이 모두 한번씩 들어 있지 않음
+var ErrPhotoOrder = errors.New("photo order should list every photo of the menu once")
+
+// ErrDuplicate 같은 값을 가진 행이 이미 있어서 만들 수 없음
+var ErrDuplicate = errors.New("already exists")
+
+// querier *sql.DB와 *sql.Tx 모두 구현하는 인터페이스
+// 스토어는 이 인터페이스에만 의존하기 때문에 트랜잭션 안에서도 그대로 쓸 수 있음
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// ctxQuerier 스토어가 쓰는 querier, 만들 때 받은 ctx를 모든 쿼리에 넘김
+// WithTx 안에서는 요청의 ctx이므로 요청이 취소되면 진행 중인 쿼리도 멈춤
+type ctxQuerier struct {
+	ctx context.Context
+	q   querier
+}
+
+func (c ctxQuerier) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.q.ExecContext(c.ctx, query, args...)
+}
+
+func (c ctxQuerier) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.q.QueryContext(c.ctx, query, args...)
+}
+
+func (c ctxQuerier) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.q.QueryRowContext(c.ctx, query, args...)
+}
+
+// scanner *sql.Row와 *sql.Rows 모두 구현하는 인터페이스
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// AccountStore reads and writes accounts
+type AccountStore interface {
+	GetAccountByEmail(email *string) (*models.Account, error)
+	GetAccountById(id *uuid.UUID) (*models.Account, error)
+	GetAccountByDbId(id models.DbId) (*models.Account, error)
+	LockAccount(id *uuid.UUID) (*models.Account, error)
+	GetAllAccounts() ([]models.Account, error)
+	GetStudentsOfTimetableEntry(id models.DbId) ([]models.Account, error)
+	GetAccountsWithFriend(id *uuid.UUID) ([]models.Account, error)
+	GetAccountNames(ids []uuid.UUID) (map[uuid.UUID]string, error)
+	CreateAccount(account *models.Account) (models.DbId, error)
+	UpdateAccount(account *models.Account) error
+	AddTimetableEntry(account *models.Account, entryId models.DbId) error
+	RemoveTimetableEntry(account *models.Account, entryId models.DbId) error
+	EnrollClass(schoolId models.SchoolId, grade int, class int, entryIds []models.DbId) ([]uuid.UUID, error)
+	UnenrollEntries(entryIds []models.DbId) ([]uuid.UUID, error)
+	SetTimetableVisibility(account *models.Account, isPublic bool) error
+	GetVisibilityOverrides(account *models.Account) (map[uuid.UUID]bool, error)
+	SetVisibilityOverride(account *models.Account, friendId *uuid.UUID, visible bool) error
+	DeleteVisibilityOverride(account *models.Account, friendId *uuid.UUID) error
+	GetAccountByFeedToken(tokenHash string) (*models.Account, error)
+	SetFeedToken(account *models.Account, tokenHash string) error
+	DeleteFeedToken(account *models.Account) error
+	GetAllergyProfile(account *models.Account) (*models.AllergyProfile, error)
+	SetAllergyProfile(account *models.Account, profile *models.AllergyProfile) error
+	GetAllergySubscribers() ([]AllergySubscriber, error)
+	GetNotifications(account *models.Account) ([]models.Notification, error)
+	AddNotification(accountId models.DbId, dedupKey string, notification *models.Notification) error
+	ReadNotification(account *models.Account, id models.DbId) error
+	DeleteAccount(id models.DbId) error
+}
+
+// TimetableStore reads and writes timetable entries
+type TimetableStore interface {
+	GetTimeTableEntry(id models.DbId) (*models.TimetableEntry, error)
+	GetTimeTableEntries(ids []models.DbId) ([]models.TimetableEntry, error)
+	GetTeacherEntries(teacherId *uuid.UUID) ([]models.TimetableEntry, error)
+	GetEnrollmentCounts(ids []models.DbId) (map[models.DbId]int, error)
+	GetSlotConflicts(entry *models.TimetableEntry) ([]models.TimetableConflict, error)
+	GetSchoolConflicts(schoolId models.SchoolId) ([]models.TimetableConflict, error)
+	CreateTimetable(entry *models.TimetableEntry) (models.DbId, error)
+	ImportTimetableEntry(entry *models.TimetableEntry, externalKey string) (ImportAction, error)
+	RetireImportedTimetables(keyPrefix string, keep []string) ([]models.DbId, error)
+	UpdateTimetable(entry *models.TimetableEntry) error
+	DeleteTimetable(id models.DbId, version int64) error
+	GetTimetableExceptions(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.TimetableException, error)
+	GetTimetableException(id models.DbId) (*models.TimetableException, error)
+	CreateTimetableException(exception *models.TimetableException) (models.DbId, error)
+	DeleteTimetableException(id models.DbId) error
+	GetDeletedTimetables() ([]models.TimetableEntry, error)
+	RestoreTimetable(id models.DbId) error
+}
+
+// MenuStore reads and writes cafeteria menus
+type MenuStore interface {
+	GetMenuByID(id models.DbId) (*models.CafeteriaMenu, error)
+	LockMenu(id models.DbId) (*models.CafeteriaMenu, error)
+	GetMenus(schoolId models.SchoolId, from time.Time, to time.Time, mealName string) ([]models.CafeteriaMenu, error)
+	CreateMenu(menu *models.CafeteriaMenu) (models.DbId, error)
+	UpdateMenu(menu *models.CafeteriaMenu) error
+	ImportMenu(menu *models.CafeteriaMenu) (ImportAction, error)
+	DeleteMenu(id models.DbId, version int64) error
+	GetDeletedMenus() ([]models.CafeteriaMenu, error)
+	RestoreMenu(id models.DbId) error
+}
+
+// ChecklistStore reads and writes checklists
+type ChecklistStore interface {
+	GetChecklistsOfStudent(studentID *uuid.UUID) (*models.Checklist, error)
+	GetChecklistsById(id models.DbId) (*models.Checklist, error)
+	CreateChecklist(checklist *models.Checklist) (models.DbId, error)
+	UpdateChecklist(checklist *models.Checklist) error
+	DeleteChecklist(id models.DbId) error
+	GetDeletedChecklists() ([]models.Checklist, error)
+	GetDeletedChecklistById(id models.DbId) (*models.Checklist, error)
+	RestoreChecklist(id models.DbId) error
+}
+
+// EventStore reads and writes school events
+type EventStore interface {
+	GetAllEvents() (*models.Events, error)
+	GetEventsByMonth(month int) (*models.Events, error)
+	GetSchoolEventsByMonth(schoolId models.SchoolId, month int) (*models.Events, error)
+	GetEventsById(id models.DbId) (*models.Events, error)
+	CreateEvents(events *models.Events) (models.DbId, error)
+	DeleteEvents(id models.DbId, version int64) error
+	GetDeletedEvents() ([]models.Events, error)
+	RestoreEvents(id models.DbId) error
+}
+
+// SchoolStore reads and writes schools
+type SchoolStore interface {
+	CreateSchool(school *models.School) (models.DbId, error)
+	GetSchool(id models.SchoolId) (*models.School, error)
+	GetSchools() ([]models.School, error)
+	GetBellSchedules(schoolId models.SchoolId) ([]models.BellSchedule, error)
+	GetBellSchedule(id models.DbId) (*models.BellSchedule, error)
+	CreateBellSchedule(schedule *models.BellSchedule) (models.DbId, error)
+	UpdateBellSchedule(schedule *models.BellSchedule) error
+	DeleteBellSchedule(id models.DbId) error
+	GetBellOverrides(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.BellScheduleOverride, error)
+	SetBellOverride(override *models.BellScheduleOverride) error
+	DeleteBellOverride(schoolId models.SchoolId, date time.Time) error
+	GetTerms(schoolId models.SchoolId) ([]models.Term, error)
+	GetTermOn(schoolId models.SchoolId, date time.Time) (*models.Term, error)
+	CreateTerm(term *models.Term) (models.DbId, error)
+	DeleteTerm(schoolId models.SchoolId, id models.DbId) error
+}
+
+// RoomStore reads and writes classrooms
+type RoomStore interface {
+	GetRooms(schoolId models.SchoolId) ([]models.Room, error)
+	GetRoom(id models.DbId) (*models.Room, error)
+	GetAvailableRooms(schoolId models.SchoolId, day models.Weekday, period models.Period) ([]models.Room, error)
+	GetRoomEntries(room *models.Room) ([]models.TimetableEntry, error)
+	CreateRoom(room *models.Room) (models.DbId, error)
+	DeleteRoom(id models.DbId) error
+}
+
+// RatingStore reads and writes students' ratings of meals
+type RatingStore interface {
+	RateMeal(account *models.Account, rating *models.MealRating) error
+	GetMyRatings(account *models.Account, menuId models.DbId) ([]models.MealRating, error)
+	DeleteMealRating(account *models.Account, menuId models.DbId, dish string) error
+	GetRating(id models.DbId) (*models.MealRating, error)
+	GetMenuScores(menuId models.DbId) ([]models.DishScore, error)
+	GetRatingComments(menuId models.DbId, status models.CommentStatus) ([]models.MealRating, error)
+	GetSchoolComments(schoolId models.SchoolId, status models.CommentStatus) ([]models.MealRating, error)
+	ModerateComment(id models.DbId, status models.CommentStatus) error
+	GetDishScores(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.DishScore, error)
+	GetRatingTrends(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.RatingTrend, error)
+}
+
+// SignupStore reads and writes meal sign-ups and check-ins
+type SignupStore interface {
+	GetSignupPolicy(schoolId models.SchoolId) (*models.MealSignupPolicy, error)
+	SetSignupPolicy(policy *models.MealSignupPolicy) error
+	SetMealSignup(account *models.Account, menuId models.DbId, attending bool) (*models.MealSignup, error)
+	GetMySignups(account *models.Account, from time.Time, to time.Time) ([]models.MealSignup, error)
+	GetSchoolSignups(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.MealSignup, error)
+	CheckInMeal(account *models.Account, menuId models.DbId, now time.Time) (*models.MealSignup, bool, error)
+}
+
+// PhotoStore reads and writes the photos of cafeteria menus
+type PhotoStore interface {
+	GetMenuPhotos(menuIds []models.DbId, includeHidden bool) ([]models.MenuPhoto, error)
+	GetMenuPhoto(id models.DbId) (*models.MenuPhoto, error)
+	CountMenuPhotos(menuId models.DbId) (int, error)
+	CreateMenuPhoto(photo *models.MenuPhoto, uploader *models.Account) (models.DbId, error)
+	SetMenuPhotoHidden(id models.DbId, hidden bool) error
+	ReorderMenuPhotos(menuId models.DbId, ids []models.DbId) error
+	DeleteMenuPhoto(id models.DbId) error
+}
+
+// ImportStore reads and writes the classes synced from NEIS
+type ImportStore interface {
+	GetTimetableImports() ([]models.TimetableImport, error)
+	SaveTimetableImport(job *models.TimetableImport) error
+	RecordTimetableImport(id models.DbId, runAt time.Time, runErr error) error
+	DeleteTimetableImport(id models.DbId) error
+}
+
+// ElectiveStore reads and writes elective groups and students' choices
+type ElectiveStore interface {
+	GetElectiveGroups(schoolId models.SchoolId) ([]models.ElectiveGroup, error)
+	GetElectiveGroup(id models.DbId) (*models.ElectiveGroup, error)
+	LockElectiveGroup(id models.DbId) (*models.ElectiveGroup, error)
+	GetDueElectiveGroups(now time.Time) ([]models.DbId, error)
+	CreateElectiveGroup(group *models.ElectiveGroup) (models.DbId, error)
+	SetElectiveWindow(group *models.ElectiveGroup) error
+	DeleteElectiveGroup(id models.DbId, version int64) error
+	GetElectiveChoices(groupId models.DbId) ([]models.ElectiveChoice, error)
+	ChooseElective(group *models.ElectiveGroup, account *models.Account, optionIds []models.DbId, now time.Time) error
+	WithdrawElective(group *models.ElectiveGroup, account *models.Account, now time.Time) error
+	MarkElectivesAllocated(group *models.ElectiveGroup, allocation models.ElectiveAllocation, now time.Time) error
+}
+
+// Stores 모든 스토어를 하나로 묶음
+// WithTx 안에서는 모든 스토어가 같은 트랜잭션을 공유함
+type Stores struct {
+	Accounts   AccountStore
+	Timetables TimetableStore
+	Menus      MenuStore
+	Checklists ChecklistStore
+	Events     EventStore
+	Schools    SchoolStore
+	Rooms      RoomStore
+	Imports    ImportStore
+	Electives  ElectiveStore
+	Ratings    RatingStore
+	Signups    SignupStore
+	Photos     PhotoStore
+}
+
+// stores 트랜잭션 밖에서 쓰는 기본 스토어, Connect에서 초기화됨
+var stores Stores
+
+// newStores 모든 스토어가 q로 쿼리를 보내고, 쿼리마다 ctx를 넘기도록 묶음
+func newStores(ctx context.Context, q querier) Stores {
+	b := ctxQuerier{ctx, q}
+	return Stores{
+		Accounts:   sqlAccountStore{b},
+		Timetables: sqlTimetableStore{b},
+		Menus:      sqlMenuStore{b},
+		Checklists: sqlChecklistStore{b},
+		Events:     sqlEventStore{b},
+		Schools:    sqlSchoolStore{b},
+		Rooms:      sqlRoomStore{b},
+		Imports:    sqlImportStore{b},
+		Electives:  sqlElectiveStore{b},
+		Ratings:    sqlRatingStore{b},
+		Signups:    sqlSignupStore{b},
+		Photos:     sqlPhotoStore{b},
+	}
+}
+
+// checkVersioned version 조건을 건 UPDATE가 실제로 행을 바꿨는지 확인함
+func checkVersioned(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// checkRestored 휴지통에 없는 행을 복구하려고 하면 sql.ErrNoRows를 반환함
+func checkRestored(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
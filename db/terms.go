@@ -0,0 +1,109 @@
+package db
+
+import (
+	"database/sql"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"time"
+)
+
+const termColumns = "id, school_id, name, start_date, end_date"
+
+func scanTerm(row scanner) (*models.Term, error) {
+	var term models.Term
+	var start, end time.Time
+	err := row.Scan(&term.ID, &term.SchoolId, &term.Name, &start, &end)
+	if err != nil {
+		return nil, err
+	}
+	term.StartDate = start.Format("2006-01-02")
+	term.EndDate = end.Format("2006-01-02")
+	return &term, nil
+}
+
+// GetTerms returns the terms of a school ordered by start date
+func GetTerms(schoolId models.SchoolId) ([]models.Term, error) {
+	return stores.Schools.GetTerms(schoolId)
+}
+
+func (s sqlSchoolStore) GetTerms(schoolId models.SchoolId) ([]models.Term, error) {
+	query := "SELECT " + termColumns + " FROM terms WHERE school_id = ? ORDER BY start_date"
+
+	rows, err := s.q.Query(query, schoolId)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	terms := make([]models.Term, 0)
+	for rows.Next() {
+		term, err := scanTerm(rows)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, *term)
+	}
+	return terms, rows.Err()
+}
+
+// GetTermOn returns the term in progress on date, or the next one if date is between terms
+// 남은 학기가 없으면 sql.ErrNoRows를 반환함
+func GetTermOn(schoolId models.SchoolId, date time.Time) (*models.Term, error) {
+	return stores.Schools.GetTermOn(schoolId, date)
+}
+
+func (s sqlSchoolStore) GetTermOn(schoolId models.SchoolId, date time.Time) (*models.Term, error) {
+	query := "SELECT " + termColumns + " FROM terms WHERE school_id = ? AND end_date >= ? ORDER BY start_date LIMIT 1"
+	return scanTerm(s.q.QueryRow(query, schoolId, date.Format("2006-01-02")))
+}
+
+// CreateTerm creates a new term
+func CreateTerm(term *models.Term) (models.DbId, error) {
+	return stores.Schools.CreateTerm(term)
+}
+
+func (s sqlSchoolStore) CreateTerm(term *models.Term) (models.DbId, error) {
+	err := utils.ValidateTerm(term)
+	if err != nil {
+		return 0, err
+	}
+
+	query := "INSERT INTO terms (school_id, name, start_date, end_date) VALUES (?, ?, ?, ?)"
+
+	result, err := s.q.Exec(query, term.SchoolId, term.Name, term.StartDate, term.EndDate)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	term.ID = models.DbId(id)
+
+	return term.ID, nil
+}
+
+// DeleteTerm deletes a term of a school
+func DeleteTerm(schoolId models.SchoolId, id models.DbId) error {
+	return stores.Schools.DeleteTerm(schoolId, id)
+}
+
+func (s sqlSchoolStore) DeleteTerm(schoolId models.SchoolId, id models.DbId) error {
+	query := "DELETE FROM terms WHERE school_id = ? AND id = ?"
+
+	result, err := s.q.Exec(query, schoolId, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package db
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"strings"
+)
+
+const timetableColumns = "id, school_id, teacher_id, location, day, period, subject, version, deleted_at"
+
+type sqlTimetableStore struct {
+	q ctxQuerier
+}
+
+func scanTimetableEntry(row scanner) (*models.TimetableEntry, error) {
+	var entry models.TimetableEntry
+	err := row.Scan(&entry.ID, &entry.SchoolId, &entry.TeacherId, &entry.Location, &entry.Day, &entry.Period, &entry.Subject, &entry.Version, &entry.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (s sqlTimetableStore) queryTimetableEntries(query string, args ...interface{}) ([]models.TimetableEntry, error) {
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	entries := make([]models.TimetableEntry, 0)
+	for rows.Next() {
+		entry, err := scanTimetableEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// GetTimeTableEntry returns a list of timetables for a student
+func GetTimeTableEntry(id models.DbId) (*models.TimetableEntry, error) {
+	return stores.Timetables.GetTimeTableEntry(id)
+}
+
+func (s sqlTimetableStore) GetTimeTableEntry(id models.DbId) (*models.TimetableEntry, error) {
+	// Prepare query
+	query := "SELECT " + timetableColumns + " FROM timetables WHERE id = ? AND deleted_at IS NULL"
+
+	// Execute query
+	row := s.q.QueryRow(query, id)
+
+	// Scan row into entry object
+	return scanTimetableEntry(row)
+}
+
+// GetTimeTableEntries returns the lessons with the given IDs ordered by day and period
+// 휴지통에 있는 수업은 빠짐
+func GetTimeTableEntries(ids []models.DbId) ([]models.TimetableEntry, error) {
+	return stores.Timetables.GetTimeTableEntries(ids)
+}
+
+func (s sqlTimetableStore) GetTimeTableEntries(ids []models.DbId) ([]models.TimetableEntry, error) {
+	if len(ids) == 0 {
+		return make([]models.TimetableEntry, 0), nil
+	}
+
+	placeholders := strings.Repeat(", ?", len(ids))[2:]
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	query := "SELECT " + timetableColumns + " FROM timetables WHERE id IN (" + placeholders + ") AND deleted_at IS NULL ORDER BY day, period"
+	return s.queryTimetableEntries(query, args...)
+}
+
+// GetTeacherEntries returns the lessons a teacher teaches ordered by day and period
+func GetTeacherEntries(teacherId *uuid.UUID) ([]models.TimetableEntry, error) {
+	return stores.Timetables.GetTeacherEntries(teacherId)
+}
+
+func (s sqlTimetableStore) GetTeacherEntries(teacherId *uuid.UUID) ([]models.TimetableEntry, error) {
+	query := "SELECT " + timetableColumns + " FROM timetables WHERE teacher_id = ? AND deleted_at IS NULL ORDER BY day, period"
+	return s.queryTimetableEntries(query, teacherId[:])
+}
+
+// GetEnrollmentCounts returns how many students take each of the given lessons
+// 듣는 학생이 없는 수업도 0으로 들어감
+func GetEnrollmentCounts(ids []models.DbId) (map[models.DbId]int, error) {
+	return stores.Timetables.GetEnrollmentCounts(ids)
+}
+
+func (s sqlTimetableStore) GetEnrollmentCounts(ids []models.DbId) (map[models.DbId]int, error) {
+	counts := make(map[models.DbId]int, len(ids))
+	if len(ids) == 0 {
+		return counts, nil
+	}
+
+	placeholders := strings.Repeat(", ?", len(ids))[2:]
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+		counts[id] = 0
+	}
+
+	query := "SELECT entry_id, COUNT(*) FROM student_timetable_entries WHERE entry_id IN (" + placeholders + ") GROUP BY entry_id"
+	rows, err := s.q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	for rows.Next() {
+		var id models.DbId
+		var count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, err
+		}
+		counts[id] = count
+	}
+	return counts, rows.Err()
+}
+
+// CreateTimetable creates a new timetable
+func CreateTimetable(entry *models.TimetableEntry) (models.DbId, error) {
+	return stores.Timetables.CreateTimetable(entry)
+}
+
+func (s sqlTimetableStore) CreateTimetable(entry *models.TimetableEntry) (models.DbId, error) {
+	err := utils.ValidateTimeTableEntry(entry)
+	if err != nil {
+		return 0, err
+	}
+
+	// Prepare query
+	query := "INSERT INTO timetables (school_id, teacher_id, location, day, period, subject) VALUES (?, ?, ?, ?, ?, ?)"
+
+	// Execute query
+	result, err := s.q.Exec(query, entry.SchoolId, entry.TeacherId[:], entry.Location, entry.Day, entry.Period, entry.Subject)
+	if err != nil {
+		return 0, err
+	}
+
+	// Get the ID of the newly created entry
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	entry.ID = models.DbId(id)
+	entry.Version = 1
+
+	return entry.ID, nil
+}
+
+// UpdateTimetable updates a timetable
+// entry.Version이 DB에 저장된 값과 다르면 ErrVersionConflict를 반환함
+func UpdateTimetable(entry *models.TimetableEntry) error {
+	return stores.Timetables.UpdateTimetable(entry)
+}
+
+func (s sqlTimetableStore) UpdateTimetable(entry *models.TimetableEntry) error {
+	err := utils.ValidateTimeTableEntry(entry)
+	if err != nil {
+		return err
+	}
+	// Prepare query
+	query := "UPDATE timetables SET school_id = ?, teacher_id = ?, location = ?, day = ?, period = ?, subject = ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL"
+
+	// Execute query
+	result, err := s.q.Exec(query, entry.SchoolId, entry.TeacherId[:], entry.Location, entry.Day, entry.Period, entry.Subject, entry.ID, entry.Version)
+	if err != nil {
+		return err
+	}
+	err = checkVersioned(result)
+	if err != nil {
+		return err
+	}
+	entry.Version++
+
+	return nil
+}
+
+// DeleteTimetable moves a timetable to the trash
+// version이 DB에 저장된 값과 다르면 ErrVersionConflict를 반환함
+func DeleteTimetable(id models.DbId, version int64) error {
+	return stores.Timetables.DeleteTimetable(id, version)
+}
+
+func (s sqlTimetableStore) DeleteTimetable(id models.DbId, version int64) error {
+	// Prepare query
+	// 실제로 지우지 않고 휴지통으로 옮김, 보관 기간이 지나면 PurgeTrash가 지움
+	query := "UPDATE timetables SET deleted_at = NOW(), version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL"
+
+	// Execute query
+	result, err := s.q.Exec(query, id, version)
+	if err != nil {
+		return err
+	}
+
+	return checkVersioned(result)
+}
+
+// GetDeletedTimetables returns every timetable in the trash
+func GetDeletedTimetables() ([]models.TimetableEntry, error) {
+	return stores.Timetables.GetDeletedTimetables()
+}
+
+func (s sqlTimetableStore) GetDeletedTimetables() ([]models.TimetableEntry, error) {
+	query := "SELECT " + timetableColumns + " FROM timetables WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	return s.queryTimetableEntries(query)
+}
+
+// RestoreTimetable takes a timetable out of the trash
+func RestoreTimetable(id models.DbId) error {
+	return stores.Timetables.RestoreTimetable(id)
+}
+
+func (s sqlTimetableStore) RestoreTimetable(id models.DbId) error {
+	query := "UPDATE timetables SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL"
+
+	result, err := s.q.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	return checkRestored(result)
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/username/schoolapp/models"
+	"log"
+	"time"
+)
+
+// trashTables 휴지통을 지원하는 테이블
+var trashTables = []string{"cafeteria_menus", "checklists", "timetables", "schoolevents"}
+
+// PurgeTrash permanently deletes every trashed row that was deleted before the given time
+// 급식을 지우면 사진 행도 같이 지워지므로, 디스크에서 지울 수 있도록 그 사진들을 반환함
+func PurgeTrash(before time.Time) (int64, []models.MenuPhoto, error) {
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	// 같은 트랜잭션에서 읽어야 읽은 뒤에 올라온 사진까지 지워지는 일이 없음
+	photos, err := sqlPhotoStore{ctxQuerier{context.Background(), tx}}.queryPhotos(
+		"SELECT "+photoColumns+" FROM "+photoTables+" WHERE m.deleted_at IS NOT NULL AND m.deleted_at < ? FOR UPDATE OF p, m", before)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var purged int64
+	for _, table := range trashTables {
+		result, err := tx.Exec("DELETE FROM `"+table+"` WHERE deleted_at IS NOT NULL AND deleted_at < ?", before)
+		if err != nil {
+			return 0, nil, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, nil, err
+		}
+		purged += affected
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, nil, err
+	}
+	return purged, photos, nil
+}
+
+// StartTrashPurger 보관 기간이 지난 휴지통 항목을 interval마다 지우는 고루틴을 시작함
+// 커밋한 뒤에 지워진 급식의 사진마다 removePhoto를 호출해서 파일을 지움
+func StartTrashPurger(retention time.Duration, interval time.Duration, removePhoto func(photo *models.MenuPhoto)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			purged, photos, err := PurgeTrash(time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("Error purging trash: %s", err.Error())
+			} else if purged > 0 {
+				log.Printf("Purged %d trashed rows", purged)
+			}
+			for i := range photos {
+				removePhoto(&photos[i])
+			}
+			<-ticker.C
+		}
+	}()
+}
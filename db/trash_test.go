@@ -0,0 +1,72 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/username/schoolapp/models"
+)
+
+func TestPurgeTrashReturnsPhotosOfPurgedMenus(t *testing.T) {
+	mock, _ := useMockDB(t)
+	before := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	uploaded := time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .+ FROM menu_photos p JOIN cafeteria_menus m .+ WHERE m.deleted_at IS NOT NULL AND m.deleted_at < \\? FOR UPDATE").
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "menu_id", "school_id", "position", "content_type", "width", "height", "hidden", "user_id", "created_at"}).
+			AddRow(4, 1, "7430310", 1, "image/jpeg", 640, 480, false, nil, uploaded).
+			AddRow(5, 1, "7430310", 2, "image/png", 640, 480, true, nil, uploaded))
+	for _, table := range trashTables {
+		affected := int64(0)
+		if table == "cafeteria_menus" {
+			affected = 1
+		}
+		mock.ExpectExec("DELETE FROM `" + table + "`").WithArgs(before).WillReturnResult(sqlmock.NewResult(0, affected))
+	}
+	mock.ExpectCommit()
+
+	purged, photos, err := PurgeTrash(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 1 {
+		t.Errorf("purged = %d, want 1", purged)
+	}
+	ids := make([]models.DbId, len(photos))
+	for i, photo := range photos {
+		ids[i] = photo.ID
+	}
+	if len(ids) != 2 || ids[0] != 4 || ids[1] != 5 {
+		t.Errorf("photos = %v, want [4 5]", ids)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPurgeTrashKeepsPhotosWhenDeleteFails(t *testing.T) {
+	mock, _ := useMockDB(t)
+	before := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM menu_photos p").WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "menu_id", "school_id", "position", "content_type", "width", "height", "hidden", "user_id", "created_at"}).
+			AddRow(4, 1, "7430310", 1, "image/jpeg", 640, 480, false, nil, before))
+	mock.ExpectExec("DELETE FROM `cafeteria_menus`").WithArgs(before).WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
+
+	// 급식이 지워지지 않았으면 사진 파일도 남겨야 함
+	_, photos, err := PurgeTrash(before)
+	if err == nil {
+		t.Fatal("PurgeTrash should fail")
+	}
+	if len(photos) != 0 {
+		t.Errorf("photos = %+v, want none", photos)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
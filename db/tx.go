@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"log"
+	"time"
+)
+
+const (
+	// MySQL 에러 번호, https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+	errLockDeadlock    = 1213
+	errLockWaitTimeout = 1205
+	errDuplicateEntry  = 1062
+
+	maxTxAttempts = 3
+)
+
+// WithTx runs fn with every store bound to a single transaction
+// fn이 에러를 반환하면 롤백하고, 아니면 커밋함
+// 데드락으로 실패하면 트랜잭션 전체를 처음부터 다시 시도하므로 fn은 여러 번 호출될 수 있음
+func WithTx(ctx context.Context, fn func(tx Stores) error) error {
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = runTx(ctx, fn)
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+		log.Printf("transaction attempt %d/%d failed, retrying: %s", attempt, maxTxAttempts, err.Error())
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * 50 * time.Millisecond):
+		}
+	}
+	return err
+}
+
+func runTx(ctx context.Context, fn func(tx Stores) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	txStores := newStores(ctx, tx)
+	var pending *pendingInvalidations
+	if storeCache != nil {
+		pending = &pendingInvalidations{}
+		txStores = cachedStores(txStores, cacheLayer{cache: storeCache, invalidator: pending, reads: false})
+	}
+
+	err = fn(txStores)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	if pending != nil {
+		pending.apply(storeCache)
+	}
+	return nil
+}
+
+func isRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == errLockDeadlock || mysqlErr.Number == errLockWaitTimeout
+}
+
+// isDuplicate UNIQUE 키에 걸려서 INSERT가 실패했는지 확인함
+func isDuplicate(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/models"
+)
+
+// 친구별 시간표 공개 설정은 Account에 들어가지 않으므로 계정 캐시를 무효화할 필요가 없음
+
+// GetVisibilityOverrides returns the per-friend visibility settings of a student's timetable keyed by the friend's UUID
+func GetVisibilityOverrides(account *models.Account) (map[uuid.UUID]bool, error) {
+	return stores.Accounts.GetVisibilityOverrides(account)
+}
+
+func (s sqlAccountStore) GetVisibilityOverrides(account *models.Account) (map[uuid.UUID]bool, error) {
+	query := "SELECT a.user_id, v.visible FROM timetable_visibility v JOIN accounts a ON a.id = v.friend_id WHERE v.account_id = ?"
+
+	rows, err := s.q.Query(query, account.DbId)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	overrides := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var friend uuid.UUID
+		var visible bool
+		if err := rows.Scan(&friend, &visible); err != nil {
+			return nil, err
+		}
+		overrides[friend] = visible
+	}
+	return overrides, rows.Err()
+}
+
+// SetVisibilityOverride shows or hides a student's timetable from one friend regardless of Timetable.IsPublic
+func SetVisibilityOverride(account *models.Account, friendId *uuid.UUID, visible bool) error {
+	return stores.Accounts.SetVisibilityOverride(account, friendId, visible)
+}
+
+func (s sqlAccountStore) SetVisibilityOverride(account *models.Account, friendId *uuid.UUID, visible bool) error {
+	query := "INSERT INTO timetable_visibility (account_id, friend_id, visible) SELECT ?, id, ? FROM accounts WHERE user_id = ? ON DUPLICATE KEY UPDATE visible = VALUES(visible)"
+
+	result, err := s.q.Exec(query, account.DbId, visible, friendId[:])
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		// 같은 값으로 다시 설정해도 0이 나오므로 친구 계정이 있는지 한번 더 확인함
+		var count int
+		err = s.q.QueryRow("SELECT COUNT(*) FROM accounts WHERE user_id = ?", friendId[:]).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return sql.ErrNoRows
+		}
+	}
+	return nil
+}
+
+// DeleteVisibilityOverride makes a friend follow Timetable.IsPublic again
+func DeleteVisibilityOverride(account *models.Account, friendId *uuid.UUID) error {
+	return stores.Accounts.DeleteVisibilityOverride(account, friendId)
+}
+
+func (s sqlAccountStore) DeleteVisibilityOverride(account *models.Account, friendId *uuid.UUID) error {
+	query := "DELETE v FROM timetable_visibility v JOIN accounts a ON a.id = v.friend_id WHERE v.account_id = ? AND a.user_id = ?"
+
+	result, err := s.q.Exec(query, account.DbId, friendId[:])
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
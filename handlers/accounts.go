@@ -46,6 +46,7 @@ func GetAccountById(c *gin.Context) {
 		return
 	}
 
+	setETag(c, account.Version)
 	c.JSON(http.StatusOK, account)
 }
 
@@ -213,13 +214,40 @@ func UpdateAccount(c *gin.Context) {
 		return
 	}
 
-	if err := db.UpdateAccount(&account); err != nil {
+	current, err := db.GetAccountById(&account.UserId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "account not found",
+		})
+		return
+	}
+	if !ifMatches(c, current.Version) {
+		preconditionFailed(c, current.Version, withoutPassword(current))
+		return
+	}
+	account.DbId = current.DbId
+	account.Version = current.Version
+
+	err = db.UpdateAccount(&account)
+	if err == db.ErrVersionConflict {
+		current, err = db.GetAccountById(&account.UserId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		preconditionFailed(c, current.Version, withoutPassword(current))
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
+	setETag(c, account.Version)
 	c.String(http.StatusOK, "Account updated")
 }
 
@@ -244,3 +272,15 @@ func DeleteAccount(c *gin.Context) {
 
 	c.String(http.StatusOK, "Account deleted")
 }
+
+// GetCacheStats handles the GET /admins/cache/stats endpoint
+func GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, db.CacheStats())
+}
+
+// withoutPassword 응답에 비밀번호 해시가 나가지 않도록 지운 사본을 만듦
+func withoutPassword(account *models.Account) models.Account {
+	safe := *account
+	safe.Password = nil
+	return safe
+}
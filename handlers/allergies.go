@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+	"strconv"
+)
+
+// GetAllergens handles the GET /cafeteria_menus/allergens endpoint
+// 알레르기 정보를 만들 때 고를 수 있는 표준 알레르기 번호와 이름
+func GetAllergens(c *gin.Context) {
+	c.JSON(http.StatusOK, models.AllergenList())
+}
+
+// GetAllergyProfile handles the GET /students/allergies endpoint
+func GetAllergyProfile(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	profile, err := db.GetAllergyProfile(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get allergy profile from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// SetAllergyProfile handles the PUT /students/allergies endpoint
+func SetAllergyProfile(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	var profile models.AllergyProfile
+	err := c.BindJSON(&profile)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if profile.Allergens == nil {
+		profile.Allergens = make([]models.AllergyType, 0)
+	}
+	if profile.Ingredients == nil {
+		profile.Ingredients = make([]string, 0)
+	}
+
+	err = db.SetAllergyProfile(user, &profile)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// GetNotifications handles the GET /students/notifications endpoint
+func GetNotifications(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	notifications, err := db.GetNotifications(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// ReadNotification handles the PUT /students/notifications/:id/read endpoint
+func ReadNotification(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	err = db.ReadNotification(user, models.DbId(id))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// warnAllergies 학생이 요청했으면 알레르기 정보와 겹치는 요리를 menus의 Warnings에 채움
+// 알레르기 정보가 없거나 학생이 아니면 아무것도 하지 않음
+func warnAllergies(c *gin.Context, menus ...*models.CafeteriaMenu) error {
+	user := c.MustGet("account").(*models.Account)
+	if user.GetLevel() != models.STUDENT {
+		return nil
+	}
+
+	profile, err := db.GetAllergyProfile(user)
+	if err != nil {
+		return err
+	}
+	if profile.Empty() {
+		return nil
+	}
+	for _, menu := range menus {
+		menu.Warnings = profile.Check(menu.Dishes)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// GetBellSchedules handles the GET /schools/:schoolId/bell_schedules endpoint
+func GetBellSchedules(c *gin.Context) {
+	schedules, err := db.GetBellSchedules(models.SchoolId(c.Param("schoolId")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bell schedules from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// CreateBellSchedule handles the POST /schools/:schoolId/bell_schedules endpoint
+func CreateBellSchedule(c *gin.Context) {
+	var schedule models.BellSchedule
+	err := c.BindJSON(&schedule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	schedule.SchoolId = models.SchoolId(c.Param("schoolId"))
+
+	// 기본 시간표를 바꾸면 다른 시간표도 같이 수정되므로 한 트랜잭션에서 처리함
+	err = db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		_, err := tx.Schools.CreateBellSchedule(&schedule)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, schedule.Version)
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// getSchoolBellSchedule URL의 학교에 속한 종 시간표만 돌려주고, 아니면 응답을 쓰고 nil을 반환함
+func getSchoolBellSchedule(c *gin.Context) *models.BellSchedule {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bell schedule ID"})
+		return nil
+	}
+
+	schedule, err := db.GetBellSchedule(models.DbId(id))
+	if err == sql.ErrNoRows || (err == nil && schedule.SchoolId != models.SchoolId(c.Param("schoolId"))) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bell schedule not found"})
+		return nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bell schedule from database"})
+		return nil
+	}
+	return schedule
+}
+
+// UpdateBellSchedule handles the PUT /schools/:schoolId/bell_schedules/:id endpoint
+func UpdateBellSchedule(c *gin.Context) {
+	schedule := getSchoolBellSchedule(c)
+	if schedule == nil {
+		return
+	}
+	if !ifMatches(c, schedule.Version) {
+		preconditionFailed(c, schedule.Version, schedule)
+		return
+	}
+
+	var updated models.BellSchedule
+	err := c.BindJSON(&updated)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	updated.ID = schedule.ID
+	updated.SchoolId = schedule.SchoolId
+	updated.Version = schedule.Version
+
+	err = db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		return tx.Schools.UpdateBellSchedule(&updated)
+	})
+	if err == db.ErrVersionConflict {
+		current, err := db.GetBellSchedule(schedule.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bell schedule"})
+			return
+		}
+		preconditionFailed(c, current.Version, current)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, updated.Version)
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteBellSchedule handles the DELETE /schools/:schoolId/bell_schedules/:id endpoint
+// 이 시간표를 쓰던 날짜별 변경도 같이 지워짐
+func DeleteBellSchedule(c *gin.Context) {
+	schedule := getSchoolBellSchedule(c)
+	if schedule == nil {
+		return
+	}
+	if !ifMatches(c, schedule.Version) {
+		preconditionFailed(c, schedule.Version, schedule)
+		return
+	}
+
+	err := db.DeleteBellSchedule(schedule.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete bell schedule"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetBellOverrides handles the GET /schools/:schoolId/bell_overrides endpoint
+// from, to(YYYY-MM-DD)가 없으면 오늘부터 30일 동안의 변경을 반환함
+func GetBellOverrides(c *gin.Context) {
+	from := time.Now()
+	to := from.AddDate(0, 0, 30)
+
+	var err error
+	if param := c.Query("from"); param != "" {
+		from, err = time.Parse("2006-01-02", param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date format"})
+			return
+		}
+	}
+	if param := c.Query("to"); param != "" {
+		to, err = time.Parse("2006-01-02", param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date format"})
+			return
+		}
+	}
+
+	overrides, err := db.GetBellOverrides(models.SchoolId(c.Param("schoolId")), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bell overrides from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, overrides)
+}
+
+// SetBellOverride handles the PUT /schools/:schoolId/bell_overrides/:date endpoint
+func SetBellOverride(c *gin.Context) {
+	date, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	var override models.BellScheduleOverride
+	err = c.BindJSON(&override)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	override.SchoolId = models.SchoolId(c.Param("schoolId"))
+	override.Date = date.Format("2006-01-02")
+
+	// 다른 학교의 시간표로 바꾸지 못하게 함
+	schedule, err := db.GetBellSchedule(override.ScheduleId)
+	if err == sql.ErrNoRows || (err == nil && schedule.SchoolId != override.SchoolId) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bell schedule not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bell schedule from database"})
+		return
+	}
+
+	err = db.SetBellOverride(&override)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save bell override"})
+		return
+	}
+
+	c.JSON(http.StatusOK, override)
+}
+
+// DeleteBellOverride handles the DELETE /schools/:schoolId/bell_overrides/:date endpoint
+func DeleteBellOverride(c *gin.Context) {
+	date, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	err = db.DeleteBellOverride(models.SchoolId(c.Param("schoolId")), date)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bell override not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete bell override"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
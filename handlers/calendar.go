@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/ical"
+	"github.com/username/schoolapp/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errNoTerm = errors.New("no term is set up for this school")
+
+// GetTimetableICS handles the GET /students/timetable.ics endpoint
+func GetTimetableICS(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	writeTimetableCalendar(c, user)
+}
+
+// GetTimetableFeed handles the GET /feeds/:token/timetable.ics endpoint
+// 달력 앱은 Authorization 헤더를 보낼 수 없으므로 URL의 토큰으로 계정을 찾음
+func GetTimetableFeed(c *gin.Context) {
+	user, err := db.GetAccountByFeedToken(hashFeedToken(c.Param("token")))
+	if err == nil && user.GetLevel() != models.STUDENT {
+		err = sql.ErrNoRows
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feed not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get feed from database"})
+		return
+	}
+
+	writeTimetableCalendar(c, user)
+}
+
+// CreateTimetableFeed handles the POST /students/timetable/feed endpoint
+// 새 토큰을 만들면 예전 구독 주소는 더 이상 쓸 수 없음, 토큰은 이때 한번만 보여줌
+func CreateTimetableFeed(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create feed token"})
+		return
+	}
+	token := hex.EncodeToString(raw)
+
+	err = db.SetFeedToken(user, hashFeedToken(token))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save feed token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token": token,
+		"url":   "/feeds/" + token + "/timetable.ics",
+	})
+}
+
+// DeleteTimetableFeed handles the DELETE /students/timetable/feed endpoint
+func DeleteTimetableFeed(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	err := db.DeleteFeedToken(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete feed token"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeTimetableCalendar(c *gin.Context, user *models.Account) {
+	calendar, err := buildTimetableCalendar(user, time.Now())
+	if err == errNoTerm {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build calendar"})
+		return
+	}
+
+	c.Header("Content-Disposition", "inline; filename=\"timetable.ics\"")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", calendar.Bytes(time.Now()))
+}
+
+// buildTimetableCalendar 지금 학기 동안 매주 반복되는 수업 일정을 만듦
+// 휴업일, 공휴일은 EXDATE로 빼고, 종 시간표에 없는 교시의 수업은 시각을 알 수 없어서 뺌
+func buildTimetableCalendar(user *models.Account, now time.Time) (ical.Calendar, error) {
+	info := user.PermissionInfo.(models.StudentInfo)
+
+	term, err := db.GetTermOn(info.SchoolId, now)
+	if err == sql.ErrNoRows {
+		return ical.Calendar{}, errNoTerm
+	}
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	termStart, termEnd, err := term.Dates(time.Local)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+
+	entries, err := db.GetTimeTableEntries(info.Timetable.Entries)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	names, err := teacherNamesOf(entries)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	school, err := getSchoolOrNil(info.SchoolId)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	events, err := schoolEventsBetween(info.SchoolId, termStart, termEnd)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+
+	exceptions, err := db.GetTimetableExceptions(info.SchoolId, termStart, termEnd)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	substitutes := make([]uuid.UUID, 0)
+	for _, exception := range exceptions {
+		if exception.TeacherId != nil {
+			substitutes = append(substitutes, *exception.TeacherId)
+		}
+	}
+	substituteNames, err := db.GetAccountNames(substitutes)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	for id, name := range substituteNames {
+		names[id] = name
+	}
+
+	return timetableCalendar(user.Name+" "+term.Name, term.ID, termStart, termEnd, school, entries, names, events, exceptions), nil
+}
+
+// timetableCalendar buildTimetableCalendar가 읽어 온 것으로 일정을 만듦, 날짜와 시각은 termStart의 시간대를 따름
+func timetableCalendar(name string, termId models.DbId, termStart time.Time, termEnd time.Time, school *models.School, entries []models.TimetableEntry, names map[uuid.UUID]string, events []models.EventEntry, exceptions []models.TimetableException) ical.Calendar {
+	loc := termStart.Location()
+	until := time.Date(termEnd.Year(), termEnd.Month(), termEnd.Day(), 23, 59, 59, 0, loc)
+
+	holidays := make([]time.Time, 0)
+	for _, event := range events {
+		if !event.CancelsClasses() {
+			continue
+		}
+		date, err := event.ParseDate(loc)
+		if err != nil || date.Before(termStart) || date.After(termEnd) {
+			continue
+		}
+		holidays = append(holidays, date)
+	}
+
+	calendar := ical.Calendar{Name: name, Events: make([]ical.Event, 0, len(entries))}
+	if school == nil {
+		return calendar
+	}
+	for _, entry := range entries {
+		bell, ok := school.ScheduleFor(entry.Day).Times(entry.Period)
+		if !ok {
+			continue
+		}
+		offset := (int(entry.Day) - int(models.WeekdayOf(termStart)) + 7) % 7
+		first := termStart.AddDate(0, 0, offset)
+		if first.After(termEnd) {
+			continue
+		}
+
+		event := ical.Event{
+			UID:      fmt.Sprintf("timetable-%d-term-%d@schoolapp", entry.ID, termId),
+			Summary:  entry.Subject,
+			Location: entry.Location,
+			Start:    bell.Start.On(first),
+			End:      bell.End.On(first),
+			Until:    until,
+		}
+		if name := names[entry.TeacherId]; name != "" {
+			event.Description = name + " 선생님, " + strconv.Itoa(int(entry.Period)) + "교시"
+		}
+		for _, holiday := range holidays {
+			if models.WeekdayOf(holiday) == entry.Day {
+				event.ExDates = append(event.ExDates, bell.Start.On(holiday))
+			}
+		}
+
+		// 예외가 있는 날은 반복 일정에서 빼고, 취소가 아니면 바뀐 수업을 그날 하루짜리 일정으로 넣음
+		for _, exception := range exceptions {
+			if exception.EntryId != entry.ID {
+				continue
+			}
+			date, err := exception.ParseDate(loc)
+			if err != nil {
+				continue
+			}
+			event.ExDates = append(event.ExDates, bell.Start.On(date))
+
+			changed, held := exception.Apply(entry)
+			if !held {
+				continue
+			}
+			changedBell, ok := school.ScheduleFor(changed.Day).Times(changed.Period)
+			if !ok {
+				continue
+			}
+			single := ical.Event{
+				UID:      fmt.Sprintf("timetable-%d-exception-%d@schoolapp", entry.ID, exception.ID),
+				Summary:  changed.Subject,
+				Location: changed.Location,
+				Start:    changedBell.Start.On(date),
+				End:      changedBell.End.On(date),
+			}
+			if name := names[changed.TeacherId]; name != "" {
+				single.Description = name + " 선생님, " + strconv.Itoa(int(changed.Period)) + "교시"
+			}
+			if exception.Note != "" {
+				single.Description = strings.TrimPrefix(single.Description+"\n"+exception.Note, "\n")
+			}
+			calendar.Events = append(calendar.Events, single)
+		}
+		calendar.Events = append(calendar.Events, event)
+	}
+	return calendar
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var kst = time.FixedZone("KST", 9*60*60)
+
+func bellPeriod(period models.Period, start string, end string) models.BellPeriod {
+	startTime, _ := models.ParseClockTime(start)
+	endTime, _ := models.ParseClockTime(end)
+	return models.BellPeriod{Period: period, Start: startTime, End: endTime}
+}
+
+func TestTimetableCalendar(t *testing.T) {
+	teacher := uuid.UUID{15: 2}
+	substitute := uuid.UUID{15: 3}
+	names := map[uuid.UUID]string{teacher: "박선생", substitute: "이선생"}
+
+	// 수요일은 단축 수업이라 같은 교시라도 시각이 다름
+	school := &models.School{SchoolId: "7430310", BellSchedules: []models.BellSchedule{
+		{Name: "기본", IsDefault: true, Periods: []models.BellPeriod{
+			bellPeriod(1, "08:40", "09:30"),
+			bellPeriod(2, "09:40", "10:30"),
+		}},
+		{Name: "단축", Weekdays: []models.Weekday{models.Wednesday}, Periods: []models.BellPeriod{
+			bellPeriod(1, "08:40", "09:20"),
+			bellPeriod(2, "09:30", "10:10"),
+		}},
+	}}
+	entries := []models.TimetableEntry{
+		{ID: 1, SchoolId: "7430310", TeacherId: teacher, Location: "1-3", Day: models.Monday, Period: 1, Subject: "국어"},
+		{ID: 2, SchoolId: "7430310", TeacherId: teacher, Location: "과학실", Day: models.Wednesday, Period: 2, Subject: "물리학Ⅰ"},
+		// 종 시간표에 없는 교시는 시각을 알 수 없어서 빠짐
+		{ID: 3, SchoolId: "7430310", TeacherId: teacher, Location: "1-3", Day: models.Friday, Period: 6, Subject: "자율"},
+	}
+	events := []models.EventEntry{
+		{Date: "20240313", DateKind: "휴업일", EventName: "재량휴업일"},
+		{Date: "20240311", DateKind: "해당 없음", EventName: "학부모 상담"},
+	}
+	moveTo := models.Period(1)
+	exceptions := []models.TimetableException{
+		{ID: 7, EntryId: 1, Date: "2024-03-18", Kind: models.SubstituteException, TeacherId: &substitute, Location: "어학실", Note: "자습, 교과서 지참"},
+		{ID: 8, EntryId: 1, Date: "2024-03-25", Kind: models.CancelException},
+		{ID: 9, EntryId: 2, Date: "2024-03-20", Kind: models.MoveException, Period: &moveTo},
+	}
+
+	// 학기가 화요일에 시작하므로 월요일 수업은 다음 주부터 반복됨
+	termStart := time.Date(2024, 3, 5, 0, 0, 0, 0, kst)
+	termEnd := time.Date(2024, 3, 29, 0, 0, 0, 0, kst)
+	calendar := timetableCalendar("김학생 1학기", 2, termStart, termEnd, school, entries, names, events, exceptions)
+	got := calendar.Bytes(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	if *update {
+		if err := os.WriteFile("testdata/timetable.ics", got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile("testdata/timetable.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("timetableCalendar() differs from testdata/timetable.ics:\n%s", got)
+	}
+}
+
+func TestTimetableCalendarWithoutSchool(t *testing.T) {
+	termStart := time.Date(2024, 3, 4, 0, 0, 0, 0, kst)
+	entries := []models.TimetableEntry{{ID: 1, Day: models.Monday, Period: 1, Subject: "국어"}}
+	calendar := timetableCalendar("김학생 1학기", 2, termStart, termStart.AddDate(0, 0, 7), nil, entries, nil, nil, nil)
+	if calendar.Name != "김학생 1학기" || len(calendar.Events) != 0 {
+		t.Errorf("timetableCalendar() = %+v, want an empty calendar", calendar)
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"database/sql"
 	"github.com/google/uuid"
 	"net/http"
 	"strconv"
@@ -79,6 +80,7 @@ func GetChecklist(c *gin.Context) {
 		return
 	}
 
+	setETag(c, items.Version)
 	c.JSON(http.StatusOK, items)
 }
 
@@ -105,23 +107,40 @@ func CreateChecklist(c *gin.Context) {
 		return
 	}
 
-	// Execute query to insert checklist items
-	for _, items := range checklist.Items {
-		items.Complete = false
-		checklist.Items = append(checklist.Items, items)
+	// New checklist items always start incomplete
+	for i := range checklist.Items {
+		checklist.Items[i].Complete = false
 	}
 
 	// Set user ID and completed status for new checklist
-	checklist.StudentId = userID.(uuid.UUID)
+	checklist.StudentId = temp
 	checklist.Title = "checklist"
 
-	// Create checklist checklist in database
-	_, err = db.CreateChecklist(&checklist)
+	// 체크리스트 생성과 StudentInfo.ChecklistId 갱신은 한 트랜잭션에서 처리
+	err = db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		_, err := tx.Checklists.CreateChecklist(&checklist)
+		if err != nil {
+			return err
+		}
+
+		account, err := tx.Accounts.GetAccountById(&temp)
+		if err != nil {
+			return err
+		}
+		info, ok := account.PermissionInfo.(models.StudentInfo)
+		if !ok {
+			return nil
+		}
+		info.ChecklistId = checklist.ID
+		account.PermissionInfo = info
+		return tx.Accounts.UpdateAccount(account)
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create checklist checklist"})
 		return
 	}
 
+	setETag(c, checklist.Version)
 	c.JSON(http.StatusCreated, checklist)
 }
 
@@ -140,6 +159,10 @@ func UpdateChecklist(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "MenuEntry not found"})
 		return
 	}
+	if !ifMatches(c, toUpdate.Version) {
+		preconditionFailed(c, toUpdate.Version, toUpdate)
+		return
+	}
 
 	// Parse request body
 	var updatedItem models.Checklist
@@ -155,11 +178,21 @@ func UpdateChecklist(c *gin.Context) {
 
 	// Update toUpdate in database
 	err = db.UpdateChecklist(toUpdate)
+	if err == db.ErrVersionConflict {
+		current, err := db.GetChecklistsById(toUpdate.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checklist toUpdate"})
+			return
+		}
+		preconditionFailed(c, current.Version, current)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checklist toUpdate"})
 		return
 	}
 
+	setETag(c, toUpdate.Version)
 	c.JSON(http.StatusOK, toUpdate)
 }
 
@@ -172,8 +205,45 @@ func DeleteChecklistItem(c *gin.Context) {
 		return
 	}
 
-	// Delete item from database
-	err = db.DeleteChecklist(models.DbId(id))
+	// 체크리스트 삭제와 StudentInfo.ChecklistId 초기화는 한 트랜잭션에서 처리
+	var current *models.Checklist
+	err = db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		checklist, err := tx.Checklists.GetChecklistsById(models.DbId(id))
+		if err != nil {
+			return err
+		}
+		if !ifMatches(c, checklist.Version) {
+			current = checklist
+			return db.ErrVersionConflict
+		}
+		err = tx.Checklists.DeleteChecklist(checklist.ID)
+		if err != nil {
+			return err
+		}
+
+		account, err := tx.Accounts.GetAccountById(&checklist.StudentId)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		info, ok := account.PermissionInfo.(models.StudentInfo)
+		if !ok || info.ChecklistId != checklist.ID {
+			return nil
+		}
+		info.ChecklistId = 0
+		account.PermissionInfo = info
+		return tx.Accounts.UpdateAccount(account)
+	})
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Checklist not found"})
+		return
+	}
+	if err == db.ErrVersionConflict && current != nil {
+		preconditionFailed(c, current.Version, current)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete checklist item"})
 		return
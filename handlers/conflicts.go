@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+)
+
+var errTimetableConflict = errors.New("lesson clashes with another lesson")
+
+// timetableConflict 어떤 수업끼리 겹치는지 클라이언트가 보여줄 수 있도록 충돌 목록을 같이 돌려줌
+func timetableConflict(c *gin.Context, conflicts []models.TimetableConflict) {
+	c.JSON(http.StatusConflict, gin.H{
+		"error":     errTimetableConflict.Error(),
+		"conflicts": conflicts,
+	})
+}
+
+// GetTimetableConflicts handles the GET /admins/timetable_conflicts endpoint
+// school_id 학교의 수업 중 학생, 선생님, 교실이 겹치는 것을 모두 반환함
+func GetTimetableConflicts(c *gin.Context) {
+	schoolId := models.SchoolId(c.Query("school_id"))
+	if schoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required"})
+		return
+	}
+
+	conflicts, err := db.GetSchoolConflicts(schoolId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable conflicts from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, conflicts)
+}
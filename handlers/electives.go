@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// GetElectiveGroups handles the GET /electives endpoint
+// 학생은 자기 학년의 선택과목만 보임
+func GetElectiveGroups(c *gin.Context) {
+	schoolId := callerSchool(c)
+	if schoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required"})
+		return
+	}
+
+	groups, err := db.GetElectiveGroups(schoolId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get electives from database"})
+		return
+	}
+
+	user := c.MustGet("account").(*models.Account)
+	if info, ok := user.PermissionInfo.(models.StudentInfo); ok {
+		visible := make([]models.ElectiveGroup, 0, len(groups))
+		for _, group := range groups {
+			if group.Grade == info.Grade {
+				visible = append(visible, group)
+			}
+		}
+		groups = visible
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetElectiveGroup handles the GET /electives/:id endpoint
+// 학생에게는 자기가 고른 과목도 같이 보여줌
+func GetElectiveGroup(c *gin.Context) {
+	group := getCallerElective(c)
+	if group == nil {
+		return
+	}
+
+	user := c.MustGet("account").(*models.Account)
+	if user.GetLevel() != models.STUDENT {
+		setETag(c, group.Version)
+		c.JSON(http.StatusOK, group)
+		return
+	}
+
+	choices, err := studentElectiveChoices(group, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get elective choices from database"})
+		return
+	}
+
+	setETag(c, group.Version)
+	c.JSON(http.StatusOK, gin.H{"group": group, "choices": choices, "open": group.IsOpen(time.Now())})
+}
+
+// CreateElectiveGroup handles the POST /electives endpoint
+// 과목마다 묶은 수업은 모두 같은 학교의 수업이어야 함
+func CreateElectiveGroup(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	var group models.ElectiveGroup
+	err := c.BindJSON(&group)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if info, ok := user.PermissionInfo.(models.TeacherInfo); ok {
+		group.SchoolId = info.SchoolId
+	}
+	group.CreatedBy = user.UserId
+	group.Mode = ""
+	group.OpensAt = nil
+	group.ClosesAt = nil
+	group.AllocatedAt = nil
+
+	ids := make([]models.DbId, 0)
+	for _, option := range group.Options {
+		ids = append(ids, option.Entries...)
+	}
+	entries, err := db.GetTimeTableEntries(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+	found := make(map[models.DbId]bool, len(entries))
+	for _, entry := range entries {
+		if entry.SchoolId == group.SchoolId {
+			found[entry.ID] = true
+		}
+	}
+	for _, id := range ids {
+		if !found[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Lesson " + strconv.FormatInt(int64(id), 10) + " is not a lesson of this school"})
+			return
+		}
+	}
+
+	_, err = db.CreateElectiveGroup(&group)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, group.Version)
+	c.JSON(http.StatusCreated, group)
+}
+
+type electiveWindowRequest struct {
+	Mode     models.ElectiveMode `json:"mode"`
+	OpensAt  *time.Time          `json:"opens_at"`
+	ClosesAt *time.Time          `json:"closes_at"`
+}
+
+// SetElectiveWindow handles the PUT /electives/:id/window endpoint
+// 신청 기간과 방식(first_come, ranked)을 정함, 배정이 끝난 뒤에는 바꿀 수 없음
+func SetElectiveWindow(c *gin.Context) {
+	group := getCallerElective(c)
+	if group == nil {
+		return
+	}
+	if !ifMatches(c, group.Version) {
+		preconditionFailed(c, group.Version, group)
+		return
+	}
+
+	var request electiveWindowRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	// 이미 선택이 있는데 방식을 바꾸면 선착순 자리와 희망 순위가 섞이므로 막음
+	if group.Mode != "" && request.Mode != group.Mode {
+		choices, err := db.GetElectiveChoices(group.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get elective choices from database"})
+			return
+		}
+		if len(choices) > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Mode cannot be changed after students have chosen"})
+			return
+		}
+	}
+	group.Mode = request.Mode
+	group.OpensAt = request.OpensAt
+	group.ClosesAt = request.ClosesAt
+
+	err = db.SetElectiveWindow(group)
+	if err == db.ErrVersionConflict {
+		current, err := db.GetElectiveGroup(group.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update elective window"})
+			return
+		}
+		preconditionFailed(c, current.Version, current)
+		return
+	}
+	if err == db.ErrElectiveClosed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Electives have already been allocated"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, group.Version)
+	c.JSON(http.StatusOK, group)
+}
+
+// AllocateElectiveGroup handles the POST /electives/:id/allocate endpoint
+// 마감 시각을 기다리지 않고 지금 배정함, 배정된 수업은 학생 시간표에 바로 들어감
+func AllocateElectiveGroup(c *gin.Context) {
+	group := getCallerElective(c)
+	if group == nil {
+		return
+	}
+
+	allocation, err := db.AllocateElectiveGroup(c.Request.Context(), group.ID, time.Now())
+	if err == db.ErrElectiveClosed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Electives have already been allocated"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to allocate electives"})
+		return
+	}
+
+	c.JSON(http.StatusOK, allocation)
+}
+
+// DeleteElectiveGroup handles the DELETE /electives/:id endpoint
+func DeleteElectiveGroup(c *gin.Context) {
+	group := getCallerElective(c)
+	if group == nil {
+		return
+	}
+	if !ifMatches(c, group.Version) {
+		preconditionFailed(c, group.Version, group)
+		return
+	}
+
+	// 읽은 뒤에 다른 요청이 수정했으면 지우지 않음
+	err := db.DeleteElectiveGroup(group.ID, group.Version)
+	if err == db.ErrVersionConflict {
+		current, err := db.GetElectiveGroup(group.ID)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Elective group not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete elective group"})
+			return
+		}
+		preconditionFailed(c, current.Version, current)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete elective group"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetElectiveChoices handles the GET /electives/:id/choices endpoint
+func GetElectiveChoices(c *gin.Context) {
+	group := getCallerElective(c)
+	if group == nil {
+		return
+	}
+
+	choices, err := db.GetElectiveChoices(group.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get elective choices from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, choices)
+}
+
+type electiveChoiceRequest struct {
+	// 원하는 순서대로, 선착순이면 하나만
+	Options []models.DbId `json:"options"`
+}
+
+// ChooseElective handles the PUT /electives/:id/choice endpoint
+// 신청 기간 안에서는 몇 번이든 바꿀 수 있고, 바꾸면 예전 선택은 사라짐
+func ChooseElective(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	group := getCallerElective(c)
+	if group == nil {
+		return
+	}
+
+	var request electiveChoiceRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	err = db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		return tx.Electives.ChooseElective(group, user, request.Options, time.Now())
+	})
+	if err == db.ErrElectiveClosed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Elective window is not open"})
+		return
+	}
+	if err == db.ErrElectiveFull {
+		c.JSON(http.StatusConflict, gin.H{"error": "This option is full"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	choices, err := studentElectiveChoices(group, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get elective choices from database"})
+		return
+	}
+	c.JSON(http.StatusOK, choices)
+}
+
+// WithdrawElective handles the DELETE /electives/:id/choice endpoint
+func WithdrawElective(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	group := getCallerElective(c)
+	if group == nil {
+		return
+	}
+
+	err := db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		return tx.Electives.WithdrawElective(group, user, time.Now())
+	})
+	if err == db.ErrElectiveClosed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Elective window is not open"})
+		return
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "You have not chosen an option"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw elective choice"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// studentElectiveChoices 학생 한 명의 선택만 희망 순서대로 골라냄
+func studentElectiveChoices(group *models.ElectiveGroup, user *models.Account) ([]models.ElectiveChoice, error) {
+	choices, err := db.GetElectiveChoices(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	mine := make([]models.ElectiveChoice, 0)
+	for _, choice := range choices {
+		if choice.UserId == user.UserId {
+			mine = append(mine, choice)
+		}
+	}
+	return mine, nil
+}
+
+// getCallerElective 다른 학교나 다른 학년의 선택과목은 없는 것으로 보고, 찾지 못하면 응답을 쓰고 nil을 반환함
+func getCallerElective(c *gin.Context) *models.ElectiveGroup {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid elective group ID"})
+		return nil
+	}
+
+	group, err := db.GetElectiveGroup(models.DbId(id))
+	user := c.MustGet("account").(*models.Account)
+	if err == nil && user.GetLevel() != models.ADMIN && group.SchoolId != callerSchool(c) {
+		err = sql.ErrNoRows
+	}
+	if info, ok := user.PermissionInfo.(models.StudentInfo); ok && err == nil && group.Grade != info.Grade {
+		err = sql.ErrNoRows
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Elective group not found"})
+		return nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get elective group from database"})
+		return nil
+	}
+	return group
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etagOf 엔티티의 version으로 ETag 값을 만듦
+// ETag는 URL마다 따로 비교되므로 version만으로 충분함
+func etagOf(version int64) string {
+	return "\"" + strconv.FormatInt(version, 10) + "\""
+}
+
+// setETag 응답에 현재 version을 ETag 헤더로 붙임
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", etagOf(version))
+}
+
+// ifMatches If-Match 헤더가 현재 version과 맞는지 확인함
+// 헤더가 없으면 예전 클라이언트를 위해 통과시킴
+// RFC 7232에 따라 If-Match는 강한 비교만 하므로 W/로 시작하는 약한 ETag는 맞지 않는 것으로 봄
+func ifMatches(c *gin.Context, version int64) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return true
+	}
+
+	current := etagOf(version)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == current {
+			return true
+		}
+	}
+	return false
+}
+
+// preconditionFailed 클라이언트가 다시 시도할 수 있도록 현재 값과 ETag를 같이 돌려줌
+func preconditionFailed(c *gin.Context, version int64, current interface{}) {
+	setETag(c, version)
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error":   "Resource was modified by another request",
+		"current": current,
+	})
+}
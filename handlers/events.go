@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"database/sql"
 	"github.com/gin-gonic/gin"
 	"github.com/username/schoolapp/db"
 	"github.com/username/schoolapp/models"
@@ -37,6 +38,7 @@ func GetEventsOfOneMonth(c *gin.Context) {
 		return
 	}
 
+	setETag(c, events.Version)
 	c.JSON(http.StatusOK, events)
 }
 
@@ -63,3 +65,53 @@ func CreateEvents(c *gin.Context) {
 		"id": id,
 	})
 }
+
+// DeleteEvents handles the DELETE /events/:id endpoint
+func DeleteEvents(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Events ID"})
+		return
+	}
+
+	// 관리자가 아니면 자기 학교의 일정만 지울 수 있음, 다른 학교의 일정은 없는 것으로 봄
+	events, err := db.GetEventsById(models.DbId(id))
+	user := c.MustGet("account").(*models.Account)
+	if err == nil && user.GetLevel() != models.ADMIN && events.SchoolId != callerSchool(c) {
+		err = sql.ErrNoRows
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Events not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get events from database"})
+		return
+	}
+	if !ifMatches(c, events.Version) {
+		preconditionFailed(c, events.Version, events)
+		return
+	}
+
+	// 읽은 뒤에 다른 요청이 수정했으면 지우지 않음
+	err = db.DeleteEvents(events.ID, events.Version)
+	if err == db.ErrVersionConflict {
+		current, err := db.GetEventsById(events.ID)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Events not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete events"})
+			return
+		}
+		preconditionFailed(c, current.Version, current)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete events"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// GetTimetableExceptions handles the GET /teachers/timetable/exceptions endpoint
+// from부터 to(YYYY-MM-DD)까지 학교의 예외를 반환하고, 없으면 이번 주를 보여줌
+func GetTimetableExceptions(c *gin.Context) {
+	schoolId := callerSchool(c)
+	if schoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required"})
+		return
+	}
+
+	now := time.Now()
+	from := now.AddDate(0, 0, -int(models.WeekdayOf(now)-models.Monday))
+	to := from.AddDate(0, 0, 6)
+	if c.Query("from") != "" || c.Query("to") != "" {
+		var err error
+		from, err = time.ParseInLocation("2006-01-02", c.Query("from"), time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		to, err = time.ParseInLocation("2006-01-02", c.Query("to"), time.Local)
+		if err != nil || to.Before(from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD on or after from"})
+			return
+		}
+	}
+
+	exceptions, err := db.GetTimetableExceptions(schoolId, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable exceptions from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, exceptions)
+}
+
+// CreateTimetableException handles the POST /teachers/timetable/:id/exceptions endpoint
+// 같은 학교 선생님은 누구나 수업을 취소하거나 옮기고 대신 들어갈 선생님이나 교실을 정할 수 있음
+// 바뀐 수업이 그날 다른 수업과 겹치면 409와 함께 충돌 목록을 반환함
+func CreateTimetableException(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+	lesson, err := db.GetTimeTableEntry(models.DbId(id))
+	if err == nil && user.GetLevel() != models.ADMIN && lesson.SchoolId != callerSchool(c) {
+		err = sql.ErrNoRows
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+
+	var exception models.TimetableException
+	err = c.BindJSON(&exception)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	exception.SchoolId = lesson.SchoolId
+	exception.EntryId = lesson.ID
+	exception.CreatedBy = user.UserId
+
+	// DB 에러와 구분할 수 있도록 저장하기 전에 확인함
+	err = utils.ValidateTimetableException(&exception)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	date, err := exception.ParseDate(time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date should be in YYYY-MM-DD format"})
+		return
+	}
+	if models.WeekdayOf(date) != lesson.Day {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The lesson does not take place on this date"})
+		return
+	}
+
+	if exception.Kind == models.SubstituteException && exception.TeacherId != nil {
+		substitute, err := db.GetAccountById(exception.TeacherId)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "teacher should be a teacher of the school"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get teacher from database"})
+			return
+		}
+		info, ok := substitute.PermissionInfo.(models.TeacherInfo)
+		if !ok || info.SchoolId != lesson.SchoolId {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "teacher should be a teacher of the school"})
+			return
+		}
+	}
+
+	// 충돌 확인과 저장 사이에 다른 예외가 들어오지 않도록 한 트랜잭션에서 함
+	var conflicts []models.TimetableConflict
+	err = db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		if effective, held := exception.Apply(*lesson); held {
+			found, err := conflictsOnDate(tx.Timetables, effective, date)
+			if err != nil {
+				return err
+			}
+			if len(found) > 0 {
+				conflicts = found
+				return errTimetableConflict
+			}
+		}
+		_, err := tx.Timetables.CreateTimetableException(&exception)
+		return err
+	})
+	if err == errTimetableConflict {
+		timetableConflict(c, conflicts)
+		return
+	}
+	if err == db.ErrDuplicate {
+		c.JSON(http.StatusConflict, gin.H{"error": "This lesson already has an exception on this date"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create timetable exception"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, exception)
+}
+
+// DeleteTimetableException handles the DELETE /teachers/timetable/exceptions/:id endpoint
+func DeleteTimetableException(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exception ID"})
+		return
+	}
+
+	exception, err := db.GetTimetableException(models.DbId(id))
+	if err == nil && user.GetLevel() != models.ADMIN && exception.SchoolId != callerSchool(c) {
+		err = sql.ErrNoRows
+	}
+	if err == nil {
+		err = db.DeleteTimetableException(exception.ID)
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Timetable exception not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete timetable exception"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// conflictsOnDate 주간 시간표 기준 충돌 중 그날 예외 때문에 없어지는 것은 빼고, 예외 때문에 새로 생기는 것은 더함
+func conflictsOnDate(timetables db.TimetableStore, lesson models.TimetableEntry, date time.Time) ([]models.TimetableConflict, error) {
+	template, err := timetables.GetSlotConflicts(&lesson)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := timetables.GetTimetableExceptions(lesson.SchoolId, date, date)
+	if err != nil {
+		return nil, err
+	}
+	others, err := withExceptionEntries(timetables.GetTimeTableEntries, nil, exceptions, func(exception models.TimetableException) bool {
+		return exception.EntryId != lesson.ID
+	})
+	if err != nil {
+		return nil, err
+	}
+	return models.ConflictsOn(date, lesson, template, others, exceptions), nil
+}
+
+// withExceptionEntries include에 맞는 예외가 걸린 수업 중 entries에 없는 것을 load로 더 불러와서 붙임
+// 대신 들어가는 선생님이나 바뀐 교실 쪽에서 보면 원래 시간표에 없던 수업이 생기기 때문임
+func withExceptionEntries(load func([]models.DbId) ([]models.TimetableEntry, error), entries []models.TimetableEntry, exceptions []models.TimetableException, include func(models.TimetableException) bool) ([]models.TimetableEntry, error) {
+	have := make(map[models.DbId]bool, len(entries))
+	for _, entry := range entries {
+		have[entry.ID] = true
+	}
+	missing := make([]models.DbId, 0)
+	for _, exception := range exceptions {
+		if include(exception) && !have[exception.EntryId] {
+			have[exception.EntryId] = true
+			missing = append(missing, exception.EntryId)
+		}
+	}
+	if len(missing) == 0 {
+		return append([]models.TimetableEntry{}, entries...), nil
+	}
+
+	extra, err := load(missing)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]models.TimetableEntry{}, entries...), extra...), nil
+}
+
+// queryDate date(YYYY-MM-DD) 쿼리가 있으면 그 날짜를 반환함, 형식이 틀리면 응답을 쓰고 ok가 false임
+func queryDate(c *gin.Context) (date *time.Time, ok bool) {
+	dateParam := c.Query("date")
+	if dateParam == "" {
+		return nil, true
+	}
+	parsed, err := time.ParseInLocation("2006-01-02", dateParam, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return nil, false
+	}
+	return &parsed, true
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxFreePeriodMembers 한번에 비교할 수 있는 친구 수
+const maxFreePeriodMembers = 20
+
+// GetFreePeriods handles the GET /students/free_periods endpoint
+// friends(쉼표로 구분한 UUID)와 요청한 학생이 모두 수업이 없는 교시를 date(YYYY-MM-DD)가 속한 주에서 찾음
+// 시간표를 볼 수 없는 친구가 있으면 403과 함께 그 친구들을 알려줌
+func GetFreePeriods(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	info := user.PermissionInfo.(models.StudentInfo)
+
+	friendIds := make([]uuid.UUID, 0)
+	for _, param := range c.QueryArray("friends") {
+		for _, part := range strings.Split(param, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			id, err := uuid.Parse(part)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID: " + part})
+				return
+			}
+			friendIds = append(friendIds, id)
+		}
+	}
+	if len(friendIds) == 0 || len(friendIds) > maxFreePeriodMembers {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Between 1 and 20 friends are required"})
+		return
+	}
+
+	date := time.Now()
+	if dateParam := c.Query("date"); dateParam != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateParam, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+			return
+		}
+		date = parsed
+	}
+	monday := date.AddDate(0, 0, -int(models.WeekdayOf(date)-models.Monday))
+	friday := monday.AddDate(0, 0, len(models.SchoolDays)-1)
+
+	// 요청한 학생의 수업도 같이 피해야 함
+	busyIds := append([]models.DbId{}, info.Timetable.Entries...)
+	hidden := make([]uuid.UUID, 0)
+	for _, id := range friendIds {
+		id := id
+		friend, err := db.GetAccountById(&id)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Student not found: " + id.String()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get student from database"})
+			return
+		}
+
+		overrides, err := db.GetVisibilityOverrides(friend)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable visibility from database"})
+			return
+		}
+		var override *bool
+		if visible, ok := overrides[user.UserId]; ok {
+			override = &visible
+		}
+		if !models.CanViewTimetable(user, friend, override) {
+			hidden = append(hidden, id)
+			continue
+		}
+		busyIds = append(busyIds, friend.PermissionInfo.(models.StudentInfo).Timetable.Entries...)
+	}
+	if len(hidden) > 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Some timetables are not visible to you", "hidden": hidden})
+		return
+	}
+
+	busy, err := db.GetTimeTableEntries(busyIds)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+	// 취소되거나 다른 교시로 옮겨진 수업은 그 날짜의 빈 시간을 바꿈
+	exceptions, err := db.GetTimetableExceptions(info.SchoolId, monday, friday)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+	busy, _ = models.ApplyWeekExceptions(monday, busy, exceptions)
+
+	bells, err := weekBells(info.SchoolId, monday)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bell schedule from database"})
+		return
+	}
+	events, err := schoolEventsBetween(info.SchoolId, monday, friday)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get events from database"})
+		return
+	}
+
+	slots, skipped := models.FindFreeSlots(monday, bells, busy, events)
+	c.JSON(http.StatusOK, gin.H{
+		"from":    monday.Format("2006-01-02"),
+		"to":      friday.Format("2006-01-02"),
+		"members": append([]uuid.UUID{user.UserId}, friendIds...),
+		"slots":   slots,
+		"skipped": skipped,
+	})
+}
+
+// schoolEventsBetween from과 to가 걸친 달의 학사일정을 모두 불러옴
+// 아직 등록되지 않은 달은 건너뜀
+func schoolEventsBetween(schoolId models.SchoolId, from time.Time, to time.Time) ([]models.EventEntry, error) {
+	events := make([]models.EventEntry, 0)
+	// schoolevents는 달만 저장하므로 1년이 넘는 범위에서도 같은 달은 한번만 읽음
+	seen := make(map[time.Month]bool)
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location()); !month.After(to); month = month.AddDate(0, 1, 0) {
+		if seen[month.Month()] {
+			continue
+		}
+		seen[month.Month()] = true
+
+		monthEvents, err := db.GetSchoolEventsByMonth(schoolId, int(month.Month()))
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, monthEvents.Events...)
+	}
+	return events, nil
+}
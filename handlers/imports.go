@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/neis"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// NeisClient main에서 설정함
+var NeisClient *neis.Client = nil
+
+type timetableImportRequest struct {
+	SchoolId  models.SchoolId `json:"school_id"`
+	Grade     int             `json:"grade"`
+	Class     int             `json:"class"`
+	TeacherId uuid.UUID       `json:"teacher_id"`
+	// YYYY-MM-DD, 비어 있으면 이번 주와 다음 주를 가져옴
+	From string `json:"from"`
+	To   string `json:"to"`
+	// true면 이 반을 예약된 동기화 목록에도 넣음
+	Schedule bool `json:"schedule"`
+}
+
+// ImportNeisTimetable handles the POST /admins/imports/timetable endpoint
+func ImportNeisTimetable(c *gin.Context) {
+	var request timetableImportRequest
+	err := c.BindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if request.SchoolId == "" || request.Grade <= 0 || request.Class <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id, grade and class are required"})
+		return
+	}
+
+	from, to, ok := importWindow(c, request.From, request.To)
+	if !ok {
+		return
+	}
+
+	// NEIS 시간표에는 선생님이 없으므로 가져온 수업을 맡을 선생님이 그 학교에 있어야 함
+	teacher, err := db.GetAccountById(&request.TeacherId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "teacher_id should be a teacher of the school"})
+		return
+	}
+	info, ok := teacher.PermissionInfo.(models.TeacherInfo)
+	if !ok || info.SchoolId != request.SchoolId {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "teacher_id should be a teacher of the school"})
+		return
+	}
+
+	_, err = db.GetSchool(request.SchoolId)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "School not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get school from database"})
+		return
+	}
+
+	job := models.TimetableImport{
+		SchoolId:  request.SchoolId,
+		Grade:     request.Grade,
+		Class:     request.Class,
+		TeacherId: request.TeacherId,
+	}
+	result, err := neis.ImportTimetable(c.Request.Context(), NeisClient, job, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to import timetable from NEIS: " + err.Error()})
+		return
+	}
+
+	response := gin.H{"result": result}
+	if request.Schedule {
+		err = db.SaveTimetableImport(&job)
+		if err == nil {
+			err = db.RecordTimetableImport(job.ID, time.Now(), nil)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Imported, but failed to schedule the import"})
+			return
+		}
+		response["import"] = job
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type mealImportRequest struct {
+	SchoolId models.SchoolId `json:"school_id"`
+	// YYYY-MM-DD, 비어 있으면 이번 주와 다음 주를 가져옴
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ImportNeisMeals handles the POST /admins/imports/meals endpoint
+func ImportNeisMeals(c *gin.Context) {
+	var request mealImportRequest
+	err := c.BindJSON(&request)
+	if err != nil || request.SchoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	from, to, ok := importWindow(c, request.From, request.To)
+	if !ok {
+		return
+	}
+
+	_, err = db.GetSchool(request.SchoolId)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "School not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get school from database"})
+		return
+	}
+
+	result, err := neis.ImportMeals(c.Request.Context(), NeisClient, request.SchoolId, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to import meals from NEIS: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
+
+// importWindow from과 to가 모두 비어 있으면 이번 주와 다음 주를 씀, 잘못되면 응답을 쓰고 false를 반환함
+func importWindow(c *gin.Context, fromParam string, toParam string) (time.Time, time.Time, bool) {
+	from, to := neis.SyncWindow(time.Now())
+	if fromParam == "" && toParam == "" {
+		return from, to, true
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", fromParam, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+		return from, to, false
+	}
+	to, err = time.ParseInLocation("2006-01-02", toParam, time.Local)
+	if err != nil || to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD on or after from"})
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// GetTimetableImports handles the GET /admins/imports/timetable endpoint
+func GetTimetableImports(c *gin.Context) {
+	jobs, err := db.GetTimetableImports()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get scheduled imports from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// DeleteTimetableImport handles the DELETE /admins/imports/timetable/:id endpoint
+func DeleteTimetableImport(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import ID"})
+		return
+	}
+
+	err = db.DeleteTimetableImport(models.DbId(id))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Scheduled import not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete scheduled import"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
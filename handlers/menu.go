@@ -1,8 +1,8 @@
 package handlers
 
 import (
+	"database/sql"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -10,24 +10,129 @@ import (
 	"github.com/username/schoolapp/models"
 )
 
-// GetCafeteriaMenus handles the GET /cafeteria endpoint
+// maxMenuRangeDays 한번에 불러올 수 있는 급식 기간
+const maxMenuRangeDays = 31
+
+// GetCafeteriaMenus handles the GET /cafeteria_menus endpoint
+// 요청한 사람의 학교에서 from부터 to까지의 급식을 날짜별로 묶어 반환함, meal(예: 중식)로 거를 수 있음
+// 예전 클라이언트가 보내는 date는 from과 to가 같은 것으로 봄
 func GetCafeteriaMenus(c *gin.Context) {
-	// Parse date from request query string
-	dateParam := c.Query("date")
-	date, err := time.Parse("2006-01-02", dateParam)
+	schoolId := callerSchool(c)
+	if schoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required"})
+		return
+	}
+
+	fromParam, toParam := c.Query("from"), c.Query("to")
+	if date := c.Query("date"); date != "" {
+		fromParam, toParam = date, date
+	}
+	from, err := time.ParseInLocation("2006-01-02", fromParam, time.Local)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+		return
+	}
+	to := from
+	if toParam != "" {
+		to, err = time.ParseInLocation("2006-01-02", toParam, time.Local)
+		if err != nil || to.Before(from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD on or after from"})
+			return
+		}
+	}
+	if to.After(from.AddDate(0, 0, maxMenuRangeDays-1)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At most 31 days can be requested at once"})
+		return
+	}
+
+	days, ok := menuDays(c, schoolId, from, to, c.Query("meal"))
+	if !ok {
 		return
 	}
 
-	// Get cafeteria menus from database
-	menus, err := db.GetMenu(date)
+	c.JSON(http.StatusOK, days)
+}
+
+// GetCafeteriaWeek handles the GET /cafeteria_menus/week endpoint
+// date(기본값 오늘)가 속한 주의 월요일부터 일요일까지 급식을 한번에 반환함
+func GetCafeteriaWeek(c *gin.Context) {
+	schoolId := callerSchool(c)
+	if schoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required"})
+		return
+	}
+
+	now := time.Now()
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if dateParam := c.Query("date"); dateParam != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateParam, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+			return
+		}
+		date = parsed
+	}
+	monday := date.AddDate(0, 0, -int(models.WeekdayOf(date)-models.Monday))
+	sunday := monday.AddDate(0, 0, 6)
+
+	days, ok := menuDays(c, schoolId, monday, sunday, c.Query("meal"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from": monday.Format("2006-01-02"),
+		"to":   sunday.Format("2006-01-02"),
+		"days": days,
+	})
+}
+
+// GetCafeteriaMenu handles the GET /cafeteria_menus/:id endpoint
+// 수정하기 전에 ETag를 받을 수 있도록 급식 하나를 반환함
+func GetCafeteriaMenu(c *gin.Context) {
+	menu := getCallerMenu(c)
+	if menu == nil {
+		return
+	}
+	err := warnAllergies(c, menu)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cafeteria menus from database"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get allergy profile from database"})
 		return
 	}
+	err = attachPhotos(c, menu)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get photos from database"})
+		return
+	}
+
+	setETag(c, menu.Version)
+	c.JSON(http.StatusOK, menu)
+}
+
+// menuDays 급식을 불러와 알레르기 경고와 사진을 채우고 날짜별로 묶음, 실패하면 응답을 쓰고 false를 반환함
+func menuDays(c *gin.Context, schoolId models.SchoolId, from time.Time, to time.Time, mealName string) ([]models.MenuDay, bool) {
+	menus, err := db.GetMenus(schoolId, from, to, mealName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cafeteria menus from database"})
+		return nil, false
+	}
+
+	pointers := make([]*models.CafeteriaMenu, len(menus))
+	for i := range menus {
+		pointers[i] = &menus[i]
+	}
+	err = warnAllergies(c, pointers...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get allergy profile from database"})
+		return nil, false
+	}
+	err = attachPhotos(c, pointers...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get photos from database"})
+		return nil, false
+	}
 
-	c.JSON(http.StatusOK, menus)
+	return models.GroupMenusByDate(from, to, menus), true
 }
 
 // CreateCafeteriaMenu handles the POST /cafeteria endpoint
@@ -39,6 +144,11 @@ func CreateCafeteriaMenu(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
+	// 관리자가 아니면 자기 학교 급식만 만들 수 있음
+	user := c.MustGet("account").(*models.Account)
+	if user.GetLevel() != models.ADMIN {
+		menu.SchoolId = callerSchool(c)
+	}
 
 	// Create menu in database
 	_, err = db.CreateMenu(&menu)
@@ -47,28 +157,25 @@ func CreateCafeteriaMenu(c *gin.Context) {
 		return
 	}
 
+	setETag(c, menu.Version)
 	c.JSON(http.StatusCreated, menu)
 }
 
 // UpdateCafeteriaMenu handles the PUT /cafeteria/:id endpoint
 func UpdateCafeteriaMenu(c *gin.Context) {
-	// Parse menu ID from request URL
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid menu ID"})
+	// 다른 학교의 급식은 없는 것처럼 404로 응답함
+	menu := getCallerMenu(c)
+	if menu == nil {
 		return
 	}
-
-	// Get existing menu from database
-	menu, err := db.GetMenuByID(models.DbId(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Menu not found"})
+	if !ifMatches(c, menu.Version) {
+		preconditionFailed(c, menu.Version, menu)
 		return
 	}
 
 	// Parse request body
 	var updatedMenu models.CafeteriaMenu
-	err = c.BindJSON(&updatedMenu)
+	err := c.BindJSON(&updatedMenu)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
@@ -78,28 +185,59 @@ func UpdateCafeteriaMenu(c *gin.Context) {
 	menu.Date = updatedMenu.Date
 	menu.MealName = updatedMenu.MealName
 	menu.Contents = updatedMenu.Contents
+	// dishes를 보내지 않은 예전 클라이언트는 items를 다시 읽어 dishes를 만듦
+	menu.Dishes = updatedMenu.Dishes
+	menu.Calories = updatedMenu.Calories
+	menu.Nutrients = updatedMenu.Nutrients
+	menu.Origins = updatedMenu.Origins
 
 	// Update menu in database
 	err = db.UpdateMenu(menu)
+	if err == db.ErrVersionConflict {
+		current, err := db.GetMenuByID(menu.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cafeteria menu"})
+			return
+		}
+		preconditionFailed(c, current.Version, current)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cafeteria menu"})
 		return
 	}
 
+	setETag(c, menu.Version)
 	c.JSON(http.StatusOK, menu)
 }
 
 // DeleteCafeteriaMenu handles the DELETE /cafeteria/:id endpoint
 func DeleteCafeteriaMenu(c *gin.Context) {
-	// Parse menu ID from request URL
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid menu ID"})
+	menu := getCallerMenu(c)
+	if menu == nil {
+		return
+	}
+	if !ifMatches(c, menu.Version) {
+		preconditionFailed(c, menu.Version, menu)
 		return
 	}
 
 	// Delete menu from database
-	err = db.DeleteMenu(models.DbId(id))
+	// 읽은 뒤에 다른 요청이 수정했으면 지우지 않음
+	err := db.DeleteMenu(menu.ID, menu.Version)
+	if err == db.ErrVersionConflict {
+		current, err := db.GetMenuByID(menu.ID)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Menu not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete cafeteria menu"})
+			return
+		}
+		preconditionFailed(c, current.Version, current)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete cafeteria menu"})
 		return
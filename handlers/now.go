@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+	"time"
+)
+
+// GetTimetableNow handles the GET /students/now endpoint
+// 지금 교시와 다음 교시, 다음 교시까지 남은 시간과 가야 할 교실을 반환함
+// 학사일정에서 오늘이 휴업일이나 공휴일이면 수업이 없는 것으로 봄
+// 오늘 취소되거나 옮겨진 수업, 바뀐 교실도 반영함
+func GetTimetableNow(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	info := user.PermissionInfo.(models.StudentInfo)
+	now := time.Now()
+
+	entries, err := db.GetTimeTableEntries(info.Timetable.Entries)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+
+	exceptions, err := db.GetTimetableExceptions(info.SchoolId, now, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+	entries, _ = models.ApplyExceptions(now, entries, exceptions)
+
+	names, err := teacherNamesOf(entries)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get teachers from database"})
+		return
+	}
+
+	var schedule *models.BellSchedule
+	school, err := getSchoolOrNil(info.SchoolId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bell schedule from database"})
+		return
+	}
+	if school != nil {
+		overrides, err := db.GetBellOverrides(info.SchoolId, now, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bell schedule from database"})
+			return
+		}
+		schedule = school.ScheduleOn(now, overrides)
+	}
+
+	// 이번 달 학사일정이 아직 등록되지 않았으면 평소처럼 수업이 있는 것으로 봄
+	events, err := schoolEventsBetween(info.SchoolId, now, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get events from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResolveNow(now, schedule, entries, names, events))
+}
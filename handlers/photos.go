@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	// menuPhotoDir 지도처럼 서버 디스크에 저장함
+	menuPhotoDir     = "./menu_photos"
+	maxMenuPhotoSize = 1024 * 1024 * 10
+	maxMenuPhotos    = 10
+	thumbnailSize    = 320
+)
+
+var errTooManyPhotos = fmt.Errorf("a menu can have at most %d photos", maxMenuPhotos)
+
+func menuPhotoPath(photo *models.MenuPhoto) string {
+	return filepath.Join(menuPhotoDir, strconv.FormatInt(int64(photo.ID), 10)+photo.Extension())
+}
+
+func menuThumbnailPath(photo *models.MenuPhoto) string {
+	return filepath.Join(menuPhotoDir, strconv.FormatInt(int64(photo.ID), 10)+"_thumb.jpg")
+}
+
+// UploadMenuPhoto handles the POST /cafeteria_menus/:id/photos endpoint
+// 파일 이름이 아니라 내용으로 형식을 확인하고, 목록에서 쓸 작은 사진을 서버에서 만듦
+func UploadMenuPhoto(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	menu := getCallerMenu(c)
+	if menu == nil {
+		return
+	}
+
+	// multipart 헤더가 있으므로 본문은 파일보다 조금 더 커도 받아들임
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxMenuPhotoSize+1024*1024)
+	file, header, err := c.Request.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An image file is required in the image field, up to 10MB"})
+		return
+	}
+	defer file.Close()
+	if header.Size > maxMenuPhotoSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Image should be at most 10MB"})
+		return
+	}
+	contents, err := io.ReadAll(io.LimitReader(file, maxMenuPhotoSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read image"})
+		return
+	}
+	if len(contents) > maxMenuPhotoSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Image should be at most 10MB"})
+		return
+	}
+
+	img, contentType, err := utils.DecodeUploadedImage(contents)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+	thumbnail, err := utils.Thumbnail(img, thumbnailSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create thumbnail"})
+		return
+	}
+
+	photo := models.MenuPhoto{
+		MenuId:      menu.ID,
+		ContentType: contentType,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}
+	// 동시에 올린 사진이 둘 다 개수 제한을 통과하지 않도록 급식 행을 잠그고 세어서 만듦
+	err = db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		_, err := tx.Menus.LockMenu(menu.ID)
+		if err != nil {
+			return err
+		}
+		count, err := tx.Photos.CountMenuPhotos(menu.ID)
+		if err != nil {
+			return err
+		}
+		if count >= maxMenuPhotos {
+			return errTooManyPhotos
+		}
+		_, err = tx.Photos.CreateMenuPhoto(&photo, user)
+		return err
+	})
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Menu not found"})
+		return
+	}
+	if err == errTooManyPhotos {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("A menu can have at most %d photos", maxMenuPhotos)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save photo"})
+		return
+	}
+
+	// 파일 이름에 사진 ID를 쓰므로 행을 먼저 만들고, 파일을 쓰지 못하면 행을 지움
+	err = os.MkdirAll(menuPhotoDir, 0755)
+	if err == nil {
+		err = os.WriteFile(menuPhotoPath(&photo), contents, 0644)
+	}
+	if err == nil {
+		err = os.WriteFile(menuThumbnailPath(&photo), thumbnail, 0644)
+	}
+	if err != nil {
+		RemoveMenuPhotoFiles(&photo)
+		_ = db.DeleteMenuPhoto(photo.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save photo"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, photo)
+}
+
+// GetMenuPhotos handles the GET /cafeteria_menus/:id/photos endpoint
+func GetMenuPhotos(c *gin.Context) {
+	menu := getCallerMenu(c)
+	if menu == nil {
+		return
+	}
+
+	err := attachPhotos(c, menu)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get photos from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, menu.Photos)
+}
+
+// ServeMenuPhoto handles the GET /cafeteria_menus/photos/:id endpoint
+func ServeMenuPhoto(c *gin.Context) {
+	photo := getCallerPhoto(c)
+	if photo == nil {
+		return
+	}
+
+	c.Header("Content-Type", photo.ContentType)
+	c.File(menuPhotoPath(photo))
+}
+
+// ServeMenuThumbnail handles the GET /cafeteria_menus/photos/:id/thumbnail endpoint
+func ServeMenuThumbnail(c *gin.Context) {
+	photo := getCallerPhoto(c)
+	if photo == nil {
+		return
+	}
+
+	c.Header("Content-Type", "image/jpeg")
+	c.File(menuThumbnailPath(photo))
+}
+
+// SetMenuPhotoHidden handles the PUT /cafeteria_menus/photos/:id/hidden endpoint
+// 숨긴 사진은 학생에게 보이지 않지만 지워지지는 않으므로 다시 보이게 할 수 있음
+func SetMenuPhotoHidden(c *gin.Context) {
+	photo := getCallerPhoto(c)
+	if photo == nil {
+		return
+	}
+
+	var body struct {
+		Hidden *bool `json:"hidden"`
+	}
+	err := c.BindJSON(&body)
+	if err != nil || body.Hidden == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	err = db.SetMenuPhotoHidden(photo.ID, *body.Hidden)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update photo"})
+		return
+	}
+
+	photo.Hidden = *body.Hidden
+	c.JSON(http.StatusOK, photo)
+}
+
+// ReorderMenuPhotos handles the PUT /cafeteria_menus/:id/photos/order endpoint
+// ids에 급식의 사진을 모두 보여줄 순서대로 넣어야 함
+func ReorderMenuPhotos(c *gin.Context) {
+	menu := getCallerMenu(c)
+	if menu == nil {
+		return
+	}
+
+	var body struct {
+		Ids []models.DbId `json:"ids"`
+	}
+	err := c.BindJSON(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	// 사진 추가와 같은 급식 행을 잠가서 순서를 바꾸는 동안 사진이 늘어나지 않게 함
+	err = db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		_, err := tx.Menus.LockMenu(menu.ID)
+		if err != nil {
+			return err
+		}
+		return tx.Photos.ReorderMenuPhotos(menu.ID, body.Ids)
+	})
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Menu not found"})
+		return
+	}
+	if errors.Is(err, db.ErrPhotoOrder) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder photos"})
+		return
+	}
+
+	err = attachPhotos(c, menu)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get photos from database"})
+		return
+	}
+	c.JSON(http.StatusOK, menu.Photos)
+}
+
+// DeleteMenuPhoto handles the DELETE /cafeteria_menus/photos/:id endpoint
+// 올린 사람이나 선생님, 관리자만 지울 수 있음
+func DeleteMenuPhoto(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	photo := getCallerPhoto(c)
+	if photo == nil {
+		return
+	}
+	uploader := photo.UploadedBy != nil && *photo.UploadedBy == user.UserId
+	if user.GetLevel() == models.STUDENT && !uploader {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the uploader or a teacher can delete this photo"})
+		return
+	}
+
+	err := db.DeleteMenuPhoto(photo.ID)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete photo"})
+		return
+	}
+	RemoveMenuPhotoFiles(photo)
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RemoveMenuPhotoFiles deletes a photo and its thumbnail from disk
+// 휴지통에서 급식이 완전히 지워질 때도 쓰임
+func RemoveMenuPhotoFiles(photo *models.MenuPhoto) {
+	_ = os.Remove(menuPhotoPath(photo))
+	_ = os.Remove(menuThumbnailPath(photo))
+}
+
+// attachPhotos menus의 Photos를 채움, 숨긴 사진은 선생님과 관리자에게만 넣음
+func attachPhotos(c *gin.Context, menus ...*models.CafeteriaMenu) error {
+	user := c.MustGet("account").(*models.Account)
+
+	ids := make([]models.DbId, len(menus))
+	for i, menu := range menus {
+		ids[i] = menu.ID
+	}
+	photos, err := db.GetMenuPhotos(ids, user.GetLevel() != models.STUDENT)
+	if err != nil {
+		return err
+	}
+
+	byMenu := make(map[models.DbId][]models.MenuPhoto)
+	for _, photo := range photos {
+		byMenu[photo.MenuId] = append(byMenu[photo.MenuId], photo)
+	}
+	for _, menu := range menus {
+		menu.Photos = byMenu[menu.ID]
+		if menu.Photos == nil {
+			menu.Photos = make([]models.MenuPhoto, 0)
+		}
+	}
+	return nil
+}
+
+// getCallerPhoto 다른 학교의 사진과 학생에게 숨긴 사진은 없는 것으로 보고, 찾지 못하면 응답을 쓰고 nil을 반환함
+func getCallerPhoto(c *gin.Context) *models.MenuPhoto {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo ID"})
+		return nil
+	}
+
+	photo, err := db.GetMenuPhoto(models.DbId(id))
+	user := c.MustGet("account").(*models.Account)
+	if err == nil && user.GetLevel() != models.ADMIN && photo.SchoolId != callerSchool(c) {
+		err = sql.ErrNoRows
+	}
+	if err == nil && user.GetLevel() == models.STUDENT && photo.Hidden {
+		err = sql.ErrNoRows
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Photo not found"})
+		return nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get photo from database"})
+		return nil
+	}
+	return photo
+}
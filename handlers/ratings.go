@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 대시보드에서 요리 순위에 넣으려면 필요한 최소 평가 수와 보여줄 요리 수
+const (
+	minDishRatings = 3
+	rankedDishes   = 10
+)
+
+// RateMeal handles the PUT /cafeteria_menus/:id/ratings endpoint
+// 학생은 자기 학교에서 이미 나온 급식만 평가할 수 있고, 같은 급식이나 요리를 다시 평가하면 고쳐짐
+func RateMeal(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	menu := getCallerMenu(c)
+	if menu == nil {
+		return
+	}
+
+	var rating models.MealRating
+	err := c.BindJSON(&rating)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	rating.MenuId = menu.ID
+
+	served, err := time.ParseInLocation("2006-01-02", menu.Date, time.Local)
+	if err != nil || served.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Meals can only be rated once they are served"})
+		return
+	}
+	err = utils.ValidateMealRating(&rating, menu)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = db.RateMeal(user, &rating)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rating"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rating)
+}
+
+// GetMyMealRatings handles the GET /cafeteria_menus/:id/ratings/mine endpoint
+func GetMyMealRatings(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	menu := getCallerMenu(c)
+	if menu == nil {
+		return
+	}
+
+	ratings, err := db.GetMyRatings(user, menu.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get ratings from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ratings)
+}
+
+// DeleteMealRating handles the DELETE /cafeteria_menus/:id/ratings endpoint
+// dish 쿼리가 없으면 급식 전체에 대한 평가를 지움
+func DeleteMealRating(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	menu := getCallerMenu(c)
+	if menu == nil {
+		return
+	}
+
+	err := db.DeleteMealRating(user, menu.ID, c.Query("dish"))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rating not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rating"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetMealRatings handles the GET /cafeteria_menus/:id/ratings endpoint
+// 급식과 요리별 평균 점수, 검토를 통과한 코멘트를 반환함, 학생에게는 누가 쓴 코멘트인지 보여주지 않음
+func GetMealRatings(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	menu := getCallerMenu(c)
+	if menu == nil {
+		return
+	}
+
+	scores, err := db.GetMenuScores(menu.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get ratings from database"})
+		return
+	}
+	comments, err := db.GetRatingComments(menu.ID, models.CommentApproved)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comments from database"})
+		return
+	}
+	if user.GetLevel() == models.STUDENT {
+		for i := range comments {
+			comments[i].UserId = nil
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"menu_id":  menu.ID,
+		"scores":   scores,
+		"comments": comments,
+	})
+}
+
+// GetRatingDashboard handles the GET /cafeteria_menus/ratings/dashboard endpoint
+// from부터 to까지(기본값 최근 90일) 평가가 가장 좋은 요리와 나쁜 요리, 주별 평균 점수를 반환함
+func GetRatingDashboard(c *gin.Context) {
+	schoolId := callerSchool(c)
+	if schoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required"})
+		return
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from := to.AddDate(0, 0, -89)
+	var err error
+	if fromParam := c.Query("from"); fromParam != "" {
+		from, err = time.ParseInLocation("2006-01-02", fromParam, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	if toParam := c.Query("to"); toParam != "" {
+		to, err = time.ParseInLocation("2006-01-02", toParam, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to should be on or after from"})
+		return
+	}
+
+	scores, err := db.GetDishScores(schoolId, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get ratings from database"})
+		return
+	}
+	trends, err := db.GetRatingTrends(schoolId, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get ratings from database"})
+		return
+	}
+
+	top, bottom := models.RankDishes(scores, minDishRatings, rankedDishes)
+	c.JSON(http.StatusOK, gin.H{
+		"from":   from.Format("2006-01-02"),
+		"to":     to.Format("2006-01-02"),
+		"top":    top,
+		"bottom": bottom,
+		"trends": trends,
+		"dishes": scores,
+	})
+}
+
+// GetRatingComments handles the GET /cafeteria_menus/ratings/comments endpoint
+// status(기본값 pending)인 코멘트를 오래된 것부터 반환함
+func GetRatingComments(c *gin.Context) {
+	schoolId := callerSchool(c)
+	if schoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required"})
+		return
+	}
+
+	status := models.CommentStatus(c.DefaultQuery("status", string(models.CommentPending)))
+	if status != models.CommentPending && status != models.CommentApproved && status != models.CommentRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status should be pending, approved or rejected"})
+		return
+	}
+
+	comments, err := db.GetSchoolComments(schoolId, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comments from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// ModerateRatingComment handles the PUT /cafeteria_menus/ratings/:id/moderation endpoint
+// 선생님은 자기 학교 코멘트만, 관리자는 모든 코멘트를 검토할 수 있음
+func ModerateRatingComment(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rating ID"})
+		return
+	}
+
+	var body struct {
+		Status models.CommentStatus `json:"status"`
+	}
+	err = c.BindJSON(&body)
+	if err != nil || (body.Status != models.CommentApproved && body.Status != models.CommentRejected) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status should be approved or rejected"})
+		return
+	}
+
+	rating, err := db.GetRating(models.DbId(id))
+	if err == nil && user.GetLevel() != models.ADMIN && rating.SchoolId != callerSchool(c) {
+		err = sql.ErrNoRows
+	}
+	if err == nil {
+		err = db.ModerateComment(rating.ID, body.Status)
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to moderate comment"})
+		return
+	}
+
+	rating.CommentStatus = body.Status
+	c.JSON(http.StatusOK, rating)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// GetRooms handles the GET /rooms endpoint
+func GetRooms(c *gin.Context) {
+	schoolId := callerSchool(c)
+	if schoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required"})
+		return
+	}
+
+	rooms, err := db.GetRooms(schoolId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get rooms from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rooms)
+}
+
+// GetAvailableRooms handles the GET /rooms/available endpoint
+// day(요일)와 period(교시)에 수업이 없는 교실을 반환함
+func GetAvailableRooms(c *gin.Context) {
+	schoolId := callerSchool(c)
+	if schoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required"})
+		return
+	}
+	day, err := models.ParseWeekday(c.Query("day"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid day"})
+		return
+	}
+	period, err := models.ParsePeriod(c.Query("period"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period"})
+		return
+	}
+
+	rooms, err := db.GetAvailableRooms(schoolId, day, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get rooms from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"day": day, "period": period, "rooms": rooms})
+}
+
+// GetRoomWeek handles the GET /rooms/:id/week endpoint
+// 학생 주간 시간표와 같은 모양으로 교실이 언제 쓰이는지 보여줌
+func GetRoomWeek(c *gin.Context) {
+	room := getCallerRoom(c)
+	if room == nil {
+		return
+	}
+
+	entries, err := db.GetRoomEntries(room)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+
+	// 교실이 바뀐 수업은 원래 교실에서 빠지고 새 교실에 들어감
+	now := time.Now()
+	monday := now.AddDate(0, 0, -int(models.WeekdayOf(now)-models.Monday))
+	week, err := datedWeek(room.SchoolId, monday, entries, func(entry models.TimetableEntry) bool {
+		return entry.Location == room.Name
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"room": room,
+		"week": week,
+	})
+}
+
+// CreateRoom handles the POST /rooms endpoint
+func CreateRoom(c *gin.Context) {
+	var room models.Room
+	err := c.BindJSON(&room)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	_, err = db.CreateRoom(&room)
+	if err == db.ErrDuplicate {
+		c.JSON(http.StatusConflict, gin.H{"error": "A room with this name already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, room)
+}
+
+// DeleteRoom handles the DELETE /rooms/:id endpoint
+func DeleteRoom(c *gin.Context) {
+	room := getCallerRoom(c)
+	if room == nil {
+		return
+	}
+
+	err := db.DeleteRoom(room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete room"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// getCallerRoom 다른 학교의 교실은 없는 것으로 보고, 찾지 못하면 응답을 쓰고 nil을 반환함
+// 관리자는 모든 학교의 교실을 볼 수 있음
+func getCallerRoom(c *gin.Context) *models.Room {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return nil
+	}
+
+	room, err := db.GetRoom(models.DbId(id))
+	user := c.MustGet("account").(*models.Account)
+	if err == nil && user.GetLevel() != models.ADMIN && room.SchoolId != callerSchool(c) {
+		err = sql.ErrNoRows
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+		return nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get room from database"})
+		return nil
+	}
+	return room
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+	"strconv"
+)
+
+// 여러 핸들러가 요청한 사람의 학교로 범위를 좁힐 때 쓰는 함수
+
+// callerSchool 학생과 선생님은 자기 학교를, 학교가 없는 관리자는 school_id 쿼리를 씀
+func callerSchool(c *gin.Context) models.SchoolId {
+	user := c.MustGet("account").(*models.Account)
+	switch info := user.PermissionInfo.(type) {
+	case models.StudentInfo:
+		return info.SchoolId
+	case models.TeacherInfo:
+		return info.SchoolId
+	}
+	return models.SchoolId(c.Query("school_id"))
+}
+
+// getCallerMenu 다른 학교의 급식은 없는 것으로 보고, 찾지 못하면 응답을 쓰고 nil을 반환함
+// 관리자는 모든 학교의 급식을 볼 수 있음
+func getCallerMenu(c *gin.Context) *models.CafeteriaMenu {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid menu ID"})
+		return nil
+	}
+
+	menu, err := db.GetMenuByID(models.DbId(id))
+	user := c.MustGet("account").(*models.Account)
+	if err == nil && user.GetLevel() != models.ADMIN && menu.SchoolId != callerSchool(c) {
+		err = sql.ErrNoRows
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Menu not found"})
+		return nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cafeteria menu from database"})
+		return nil
+	}
+	return menu
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/utils"
+	"net/http"
+	"time"
+)
+
+// mealPassLifetime QR 식권이 유효한 시간, 앱은 그 전에 새 식권을 받아야 함
+const mealPassLifetime = 5 * time.Minute
+
+// GetSignupPolicy handles the GET /cafeteria_menus/signups/policy endpoint
+func GetSignupPolicy(c *gin.Context) {
+	schoolId := callerSchool(c)
+	if schoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required"})
+		return
+	}
+
+	policy, err := db.GetSignupPolicy(schoolId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sign-up policy from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// SetSignupPolicy handles the PUT /cafeteria_menus/signups/policy endpoint
+func SetSignupPolicy(c *gin.Context) {
+	schoolId := callerSchool(c)
+	if schoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required"})
+		return
+	}
+
+	var policy models.MealSignupPolicy
+	err := c.BindJSON(&policy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	policy.SchoolId = schoolId
+
+	// DB 에러와 구분할 수 있도록 저장하기 전에 확인함
+	err = utils.ValidateMealSignupPolicy(&policy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = db.SetSignupPolicy(&policy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update signup policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// SignUpForMeal handles the PUT /cafeteria_menus/:id/signup endpoint
+// attending이 false면 먹지 않겠다고 알리는 것이고, 학교의 마감 전까지 몇 번이든 바꿀 수 있음
+func SignUpForMeal(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	menu := getCallerMenu(c)
+	if menu == nil {
+		return
+	}
+
+	var body struct {
+		Attending *bool `json:"attending"`
+	}
+	err := c.BindJSON(&body)
+	if err != nil || body.Attending == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	policy, err := db.GetSignupPolicy(menu.SchoolId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sign-up policy from database"})
+		return
+	}
+	served, err := time.ParseInLocation("2006-01-02", menu.Date, time.Local)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid menu date"})
+		return
+	}
+	deadline := policy.Deadline(served)
+	if time.Now().After(deadline) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Sign-up deadline has passed", "deadline": deadline})
+		return
+	}
+
+	signup, err := db.SetMealSignup(user, menu.ID, *body.Attending)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save sign-up"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"signup": signup, "deadline": deadline})
+}
+
+// GetMySignups handles the GET /cafeteria_menus/signups endpoint
+// from부터 to까지(기본값 오늘부터 2주) 학생이 신청하거나 취소한 급식을 반환함
+func GetMySignups(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	today := startOfToday()
+	from, to, ok := queryDateRange(c, today, today.AddDate(0, 0, 13))
+	if !ok {
+		return
+	}
+
+	signups, err := db.GetMySignups(user, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sign-ups from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, signups)
+}
+
+// GetHeadcounts handles the GET /cafeteria_menus/headcount endpoint
+// from부터 to까지(기본값 오늘) 급식마다 신청, 취소, 실제로 먹은 학생 수를 반환함
+func GetHeadcounts(c *gin.Context) {
+	schoolId := callerSchool(c)
+	if schoolId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required"})
+		return
+	}
+
+	today := startOfToday()
+	from, to, ok := queryDateRange(c, today, today)
+	if !ok {
+		return
+	}
+
+	menus, err := db.GetMenus(schoolId, from, to, c.Query("meal"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cafeteria menus from database"})
+		return
+	}
+	signups, err := db.GetSchoolSignups(schoolId, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sign-ups from database"})
+		return
+	}
+
+	byMenu := make(map[models.DbId][]models.MealSignup)
+	for _, signup := range signups {
+		byMenu[signup.MenuId] = append(byMenu[signup.MenuId], signup)
+	}
+	headcounts := make([]models.MealHeadcount, len(menus))
+	for i, menu := range menus {
+		headcounts[i] = models.CountHeadcount(menu, byMenu[menu.ID])
+	}
+
+	c.JSON(http.StatusOK, headcounts)
+}
+
+// GetMealHeadcount handles the GET /cafeteria_menus/:id/headcount endpoint
+// 수를 세는 것과 함께 학년, 반, 번호 순으로 학생 목록을 반환함
+func GetMealHeadcount(c *gin.Context) {
+	menu := getCallerMenu(c)
+	if menu == nil {
+		return
+	}
+
+	served, err := time.ParseInLocation("2006-01-02", menu.Date, time.Local)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid menu date"})
+		return
+	}
+	signups, err := db.GetSchoolSignups(menu.SchoolId, served, served)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sign-ups from database"})
+		return
+	}
+	mealSignups := make([]models.MealSignup, 0)
+	for _, signup := range signups {
+		if signup.MenuId == menu.ID {
+			mealSignups = append(mealSignups, signup)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"headcount": models.CountHeadcount(*menu, mealSignups),
+		"signups":   mealSignups,
+	})
+}
+
+// GetMealPass handles the GET /students/meal_pass endpoint
+// 배식대에서 찍을 QR 코드에 넣을 식권 토큰을 반환함
+func GetMealPass(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	expires := time.Now().Add(mealPassLifetime)
+	token, err := utils.SignMealPass(user.UserId, expires)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create meal pass"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token, "expires_at": expires})
+}
+
+// CheckInMeal handles the POST /cafeteria_menus/:id/checkin endpoint
+// 학생 QR 코드의 식권 토큰으로 오늘 급식을 먹었다고 기록함, 신청하지 않은 학생도 기록됨
+func CheckInMeal(c *gin.Context) {
+	menu := getCallerMenu(c)
+	if menu == nil {
+		return
+	}
+	if menu.Date != startOfToday().Format("2006-01-02") {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only today's meals can be checked in"})
+		return
+	}
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	err := c.BindJSON(&body)
+	if err != nil || body.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	userId, err := utils.VerifyMealPass(body.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	student, err := db.GetAccountById(&userId)
+	if err == nil {
+		info, ok := student.PermissionInfo.(models.StudentInfo)
+		if !ok || info.SchoolId != menu.SchoolId {
+			err = sql.ErrNoRows
+		}
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found in this school"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get student from database"})
+		return
+	}
+
+	signup, first, err := db.CheckInMeal(student, menu.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"signup": signup, "already_checked_in": !first})
+}
+
+func startOfToday() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// queryDateRange from과 to 쿼리를 읽고 없으면 기본값을 씀, 잘못되었거나 31일이 넘으면 응답을 쓰고 false를 반환함
+func queryDateRange(c *gin.Context, from time.Time, to time.Time) (time.Time, time.Time, bool) {
+	var err error
+	if fromParam := c.Query("from"); fromParam != "" {
+		from, err = time.ParseInLocation("2006-01-02", fromParam, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return from, to, false
+		}
+	}
+	if toParam := c.Query("to"); toParam != "" {
+		to, err = time.ParseInLocation("2006-01-02", toParam, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return from, to, false
+		}
+	}
+	if to.Before(from) || to.After(from.AddDate(0, 0, maxMenuRangeDays-1)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to should be on or after from, and at most 31 days can be requested at once"})
+		return from, to, false
+	}
+	return from, to, true
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+	"strconv"
+)
+
+// GetTeacherTimetable handles the GET /teachers/timetable endpoint
+// 선생님이 맡은 수업을 요일, 교시 순으로 반환하고 수업마다 듣는 학생 수를 붙임
+// date(YYYY-MM-DD)를 주면 그날의 예외를 반영한 수업만 반환함
+func GetTeacherTimetable(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	info := user.PermissionInfo.(models.TeacherInfo)
+
+	date, ok := queryDate(c)
+	if !ok {
+		return
+	}
+
+	entries, err := db.GetTeacherEntries(&user.UserId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+
+	// 날짜를 주면 그날 대신 들어가는 수업을 더하고, 취소되거나 다른 선생님이 맡은 수업은 뺌
+	var schedule *models.BellSchedule
+	var cancelled []models.TimetableEntry
+	if date != nil {
+		exceptions, err := db.GetTimetableExceptions(info.SchoolId, *date, *date)
+		if err == nil {
+			entries, err = withExceptionEntries(db.GetTimeTableEntries, entries, exceptions, func(exception models.TimetableException) bool {
+				return exception.TeacherId != nil && *exception.TeacherId == user.UserId
+			})
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+			return
+		}
+		entries, cancelled = models.ApplyExceptions(*date, entries, exceptions)
+		mine := func(entry models.TimetableEntry) bool {
+			return entry.TeacherId == user.UserId
+		}
+		entries = filterEntries(entries, mine)
+		cancelled = filterEntries(cancelled, mine)
+	}
+
+	ids := make([]models.DbId, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	counts, err := db.GetEnrollmentCounts(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get enrollments from database"})
+		return
+	}
+
+	school, err := getSchoolOrNil(info.SchoolId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bell schedule from database"})
+		return
+	}
+
+	if date != nil && school != nil {
+		overrides, err := db.GetBellOverrides(info.SchoolId, *date, *date)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bell schedule from database"})
+			return
+		}
+		schedule = school.ScheduleOn(*date, overrides)
+	}
+
+	lessons := make([]models.TeacherLesson, len(entries))
+	for i, entry := range entries {
+		if date == nil && school != nil {
+			schedule = school.ScheduleFor(entry.Day)
+		}
+		lessons[i] = models.TeacherLesson{
+			ScheduledEntry: models.NewScheduledEntry(entry, schedule),
+			Enrolled:       counts[entry.ID],
+		}
+	}
+
+	if date != nil {
+		c.JSON(http.StatusOK, gin.H{"date": date.Format("2006-01-02"), "entries": lessons, "cancelled": cancelled})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": lessons})
+}
+
+// GetLessonRoster handles the GET /teachers/timetable/:id/students endpoint
+// 선생님은 자기가 맡은 수업의 명단만 볼 수 있음
+func GetLessonRoster(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+
+	lesson, err := db.GetTimeTableEntry(models.DbId(id))
+	if err == sql.ErrNoRows || (err == nil && user.GetLevel() == models.TEACHER && lesson.TeacherId != user.UserId) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+
+	students, err := db.GetStudentsOfTimetableEntry(lesson.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get students from database"})
+		return
+	}
+
+	roster := make([]models.RosterStudent, 0, len(students))
+	for _, student := range students {
+		info, ok := student.PermissionInfo.(models.StudentInfo)
+		if !ok {
+			continue
+		}
+		roster = append(roster, models.RosterStudent{
+			UserId: student.UserId,
+			Name:   student.Name,
+			Grade:  info.Grade,
+			Class:  info.Class,
+			Number: info.Number,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lesson": lesson, "students": roster})
+}
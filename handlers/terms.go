@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+	"strconv"
+)
+
+// GetTerms handles the GET /schools/:schoolId/terms endpoint
+func GetTerms(c *gin.Context) {
+	terms, err := db.GetTerms(models.SchoolId(c.Param("schoolId")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get terms from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, terms)
+}
+
+// CreateTerm handles the POST /schools/:schoolId/terms endpoint
+func CreateTerm(c *gin.Context) {
+	var term models.Term
+	err := c.BindJSON(&term)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	term.SchoolId = models.SchoolId(c.Param("schoolId"))
+
+	_, err = db.CreateTerm(&term)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, term)
+}
+
+// DeleteTerm handles the DELETE /schools/:schoolId/terms/:id endpoint
+func DeleteTerm(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid term ID"})
+		return
+	}
+
+	err = db.DeleteTerm(models.SchoolId(c.Param("schoolId")), models.DbId(id))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Term not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete term"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
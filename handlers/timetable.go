@@ -1,95 +1,352 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/username/schoolapp/db"
 	"github.com/username/schoolapp/models"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 // LockTimetable locks the timetable
 func LockTimetable(c *gin.Context) {
-	fetched, exists := c.Get("user_id")
+	setTimetableVisibility(c, false)
+}
 
-	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user ID from context"})
-		return
-	}
+// UnLockTimetable unlocks the timetable
+func UnLockTimetable(c *gin.Context) {
+	setTimetableVisibility(c, true)
+}
+
+func setTimetableVisibility(c *gin.Context, isPublic bool) {
+	user := c.MustGet("account").(*models.Account)
+	info := user.PermissionInfo.(models.StudentInfo)
 
-	studentId := fetched.(uuid.UUID)
-	user, err := db.GetAccountById(&studentId)
+	err := db.SetTimetableVisibility(user, isPublic)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
-	if user.PermissionInfo.GetLevel() != models.STUDENT {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Requested user is not student"})
-	}
-	info := user.PermissionInfo.(models.StudentInfo)
-	info.Timetable.IsPublic = false
+	info.Timetable.IsPublic = isPublic
 
+	setETag(c, user.Version)
 	c.JSON(http.StatusOK, info.Timetable)
 }
 
-// UnLockTimetable unlocks the timetable
-func UnLockTimetable(c *gin.Context) {
-	fetched, exists := c.Get("user_id")
+// GetTimetable handles the GET /students/timetable endpoint
+// 학생이 듣는 수업을 모두 불러와서 요일, 교시 순으로 반환함
+// 각 수업에는 학교 종 시간표에 따른 시작, 끝 시각이 붙음
+// date(YYYY-MM-DD)를 주면 그날의 예외를 반영해서 그날 실제로 하는 수업만 반환함
+func GetTimetable(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	info := user.PermissionInfo.(models.StudentInfo)
 
-	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user ID from context"})
+	date, ok := queryDate(c)
+	if !ok {
 		return
 	}
-
-	studentId := fetched.(uuid.UUID)
-	user, err := db.GetAccountById(&studentId)
+	resolved, err := resolveTimetable(info, date)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
 		return
 	}
-	if user.PermissionInfo.GetLevel() != models.STUDENT {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Requested user is not student"})
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, resolved)
+}
+
+// resolveTimetable 학생이 듣는 수업을 불러와서 학교 종 시간표의 시각을 붙임
+func resolveTimetable(info models.StudentInfo, date *time.Time) (models.ResolvedTimetable, error) {
+	entries, err := db.GetTimeTableEntries(info.Timetable.Entries)
+	if err != nil {
+		return models.ResolvedTimetable{}, err
+	}
+	school, err := getSchoolOrNil(info.SchoolId)
+	if err != nil {
+		return models.ResolvedTimetable{}, err
+	}
+
+	resolved := models.ResolvedTimetable{IsPublic: info.Timetable.IsPublic}
+	var dated *models.BellSchedule
+	if date != nil {
+		exceptions, err := db.GetTimetableExceptions(info.SchoolId, *date, *date)
+		if err != nil {
+			return models.ResolvedTimetable{}, err
+		}
+		entries, resolved.Cancelled = models.ApplyExceptions(*date, entries, exceptions)
+		resolved.Date = date.Format("2006-01-02")
+		if school != nil {
+			overrides, err := db.GetBellOverrides(info.SchoolId, *date, *date)
+			if err != nil {
+				return models.ResolvedTimetable{}, err
+			}
+			dated = school.ScheduleOn(*date, overrides)
+		}
+	}
+
+	resolved.Entries = make([]models.ScheduledEntry, len(entries))
+	for i, entry := range entries {
+		schedule := dated
+		if date == nil && school != nil {
+			schedule = school.ScheduleFor(entry.Day)
+		}
+		resolved.Entries[i] = models.NewScheduledEntry(entry, schedule)
 	}
+	return resolved, nil
+}
+
+// GetTimetableWeek handles the GET /students/timetable/week endpoint
+// 월요일부터 금요일까지 교시별로 칸을 채워서 반환하고, 수업이 없는 칸도 빠짐없이 넣음
+// date(YYYY-MM-DD)가 속한 주의 종 시간표와 예외를 쓰며, 없으면 이번 주를 보여줌
+func GetTimetableWeek(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
 	info := user.PermissionInfo.(models.StudentInfo)
-	info.Timetable.IsPublic = true
 
-	c.JSON(http.StatusOK, info.Timetable)
+	date := time.Now()
+	if dateParam := c.Query("date"); dateParam != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateParam, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+			return
+		}
+		date = parsed
+	}
+	monday := date.AddDate(0, 0, -int(models.WeekdayOf(date)-models.Monday))
+
+	entries, err := db.GetTimeTableEntries(info.Timetable.Entries)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+
+	week, err := datedWeek(info.SchoolId, monday, entries, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+	week.IsPublic = info.Timetable.IsPublic
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, week)
+}
+
+// datedWeek monday부터 금요일까지 날짜별 예외와 종 시간표를 반영한 주간 시간표를 만듦
+// keep이 nil이 아니면 예외 때문에 이번 주에 새로 들어오는 수업도 불러오고, keep에 맞는 수업만 남김
+func datedWeek(schoolId models.SchoolId, monday time.Time, entries []models.TimetableEntry, keep func(models.TimetableEntry) bool) (models.TimetableWeek, error) {
+	friday := monday.AddDate(0, 0, len(models.SchoolDays)-1)
+	exceptions, err := db.GetTimetableExceptions(schoolId, monday, friday)
+	if err != nil {
+		return models.TimetableWeek{}, err
+	}
+	if keep != nil {
+		entries, err = withExceptionEntries(db.GetTimeTableEntries, entries, exceptions, func(models.TimetableException) bool {
+			return true
+		})
+		if err != nil {
+			return models.TimetableWeek{}, err
+		}
+	}
+
+	effective, cancelled := models.ApplyWeekExceptions(monday, entries, exceptions)
+	if keep != nil {
+		effective = filterEntries(effective, keep)
+		cancelled = filterEntries(cancelled, keep)
+	}
+
+	names, err := teacherNamesOf(append(append([]models.TimetableEntry{}, effective...), cancelled...))
+	if err != nil {
+		return models.TimetableWeek{}, err
+	}
+	bells, err := weekBells(schoolId, monday)
+	if err != nil {
+		return models.TimetableWeek{}, err
+	}
+
+	week := models.NewTimetableWeek(effective, names, bells)
+	week.SetDates(monday, cancelled, names)
+	return week, nil
 }
 
-// GetTimetableEntry handles the GET /timetable endpoint
+func filterEntries(entries []models.TimetableEntry, keep func(models.TimetableEntry) bool) []models.TimetableEntry {
+	kept := make([]models.TimetableEntry, 0, len(entries))
+	for _, entry := range entries {
+		if keep(entry) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// teacherNamesOf 수업을 맡은 선생님들의 이름을 한번에 불러옴
+func teacherNamesOf(entries []models.TimetableEntry) (map[uuid.UUID]string, error) {
+	teachers := make([]uuid.UUID, 0, len(entries))
+	for _, entry := range entries {
+		teachers = append(teachers, entry.TeacherId)
+	}
+	return db.GetAccountNames(teachers)
+}
+
+// getSchoolOrNil 학교가 아직 등록되지 않았으면 에러 없이 nil을 반환함
+func getSchoolOrNil(schoolId models.SchoolId) (*models.School, error) {
+	school, err := db.GetSchool(schoolId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return school, err
+}
+
+// weekBells monday부터 금요일까지 날짜별 변경을 반영한 종 시간표를 요일별로 찾음
+func weekBells(schoolId models.SchoolId, monday time.Time) (map[models.Weekday]*models.BellSchedule, error) {
+	bells := make(map[models.Weekday]*models.BellSchedule, len(models.SchoolDays))
+	school, err := getSchoolOrNil(schoolId)
+	if err != nil || school == nil {
+		return bells, err
+	}
+
+	overrides, err := db.GetBellOverrides(schoolId, monday, monday.AddDate(0, 0, len(models.SchoolDays)-1))
+	if err != nil {
+		return nil, err
+	}
+	for i, day := range models.SchoolDays {
+		bells[day] = school.ScheduleOn(monday.AddDate(0, 0, i), overrides)
+	}
+	return bells, nil
+}
+
+// GetTimetableEntry handles the GET /students/timetable/:id endpoint
 func GetTimetableEntry(c *gin.Context) {
-	// Get user ID from request context
-	fetched, exists := c.Get("id")
-	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user ID from context"})
+	// Parse lesson ID from request URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
 		return
 	}
 
 	// Get timetable from database
-	dbId := models.DbId(fetched.(int))
-	timetable, err := db.GetTimeTableEntry(dbId)
+	// 관리자가 아니면 다른 학교의 수업은 없는 것으로 봄
+	timetable, err := db.GetTimeTableEntry(models.DbId(id))
+	user := c.MustGet("account").(*models.Account)
+	if err == nil && user.GetLevel() != models.ADMIN && timetable.SchoolId != callerSchool(c) {
+		err = sql.ErrNoRows
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
 		return
 	}
 
+	setETag(c, timetable.Version)
 	c.JSON(http.StatusOK, timetable)
 }
 
-// CreateTimetable handles the POST /timetable endpoint
-func CreateTimetable(c *gin.Context) {
-	// Get user ID from request context
-	fetched, exists := c.Get("teacherID")
-	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user ID from context"})
+// EnrollTimetableEntry handles the POST /students/timetable/entries/:id endpoint
+func EnrollTimetableEntry(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	info := user.PermissionInfo.(models.StudentInfo)
+
+	// Parse lesson ID from request URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
 		return
 	}
 
+	// 다른 학교의 수업은 없는 것으로 봄
+	lesson, err := db.GetTimeTableEntry(models.DbId(id))
+	if err == nil && lesson.SchoolId != info.SchoolId {
+		err = sql.ErrNoRows
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+
+	// 같은 요일, 교시에 이미 듣는 수업이 있으면 신청할 수 없음
+	// 미들웨어가 읽은 계정은 캐시에서 왔을 수 있으므로, 계정 행을 잠그고 듣는 수업을 트랜잭션 안에서 다시 읽음
+	var conflict *models.TimetableConflict
+	var version int64
+	err = db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		student, err := tx.Accounts.LockAccount(&user.UserId)
+		if err != nil {
+			return err
+		}
+		studentInfo, ok := student.PermissionInfo.(models.StudentInfo)
+		if !ok {
+			return errors.New("account is not a student")
+		}
+		enrolled, err := tx.Timetables.GetTimeTableEntries(studentInfo.Timetable.Entries)
+		if err != nil {
+			return err
+		}
+		found := models.StudentConflictWith(user.UserId, enrolled, *lesson)
+		if found != nil {
+			conflict = found
+			return errTimetableConflict
+		}
+		err = tx.Accounts.AddTimetableEntry(student, lesson.ID)
+		if err != nil {
+			return err
+		}
+		version = student.Version
+		return nil
+	})
+	if err == errTimetableConflict {
+		timetableConflict(c, []models.TimetableConflict{*conflict})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enroll in lesson"})
+		return
+	}
+
+	setETag(c, version)
+	c.JSON(http.StatusOK, lesson)
+}
+
+// DropTimetableEntry handles the DELETE /students/timetable/entries/:id endpoint
+func DropTimetableEntry(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	// Parse lesson ID from request URL
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+
+	err = db.RemoveTimetableEntry(user, models.DbId(id))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not enrolled in lesson"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to drop lesson"})
+		return
+	}
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// CreateTimetable handles the POST /students/timetable endpoint
+// 요청한 선생님이 담당 선생님이 됨
+func CreateTimetable(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	info := user.PermissionInfo.(models.TeacherInfo)
+
 	// Parse request body
 	var lesson models.TimetableEntry
 	err := c.BindJSON(&lesson)
@@ -98,16 +355,34 @@ func CreateTimetable(c *gin.Context) {
 		return
 	}
 
-	// Set user ID for new lesson
-	lesson.TeacherId = fetched.(uuid.UUID)
+	// 수업은 담당 선생님의 학교에 속함
+	lesson.TeacherId = user.UserId
+	lesson.SchoolId = info.SchoolId
 
 	// Create lesson in database
-	_, err = db.CreateTimetable(&lesson)
+	var conflicts []models.TimetableConflict
+	err = db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		found, err := tx.Timetables.GetSlotConflicts(&lesson)
+		if err != nil {
+			return err
+		}
+		if len(found) > 0 {
+			conflicts = found
+			return errTimetableConflict
+		}
+		_, err = tx.Timetables.CreateTimetable(&lesson)
+		return err
+	})
+	if err == errTimetableConflict {
+		timetableConflict(c, conflicts)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create lesson"})
 		return
 	}
 
+	setETag(c, lesson.Version)
 	c.JSON(http.StatusCreated, lesson)
 }
 
@@ -121,11 +396,24 @@ func UpdateTimetable(c *gin.Context) {
 	}
 
 	// Get existing lesson from database
-	_, err = db.GetTimeTableEntry(models.DbId(id))
-	if err != nil {
+	// 관리자가 아니면 다른 학교의 수업은 없는 것으로 봄
+	lesson, err := db.GetTimeTableEntry(models.DbId(id))
+	user := c.MustGet("account").(*models.Account)
+	if err == nil && user.GetLevel() != models.ADMIN && lesson.SchoolId != callerSchool(c) {
+		err = sql.ErrNoRows
+	}
+	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+	if !ifMatches(c, lesson.Version) {
+		preconditionFailed(c, lesson.Version, lesson)
+		return
+	}
 
 	// Parse request body
 	var updatedLesson models.TimetableEntry
@@ -135,13 +423,63 @@ func UpdateTimetable(c *gin.Context) {
 		return
 	}
 
+	updatedLesson.ID = lesson.ID
+	updatedLesson.SchoolId = lesson.SchoolId
+	updatedLesson.Version = lesson.Version
+
+	// 담당 선생님은 관리자만 바꿀 수 있고, 같은 학교의 선생님이어야 함
+	if user.GetLevel() != models.ADMIN || updatedLesson.TeacherId == uuid.Nil {
+		updatedLesson.TeacherId = lesson.TeacherId
+	} else if updatedLesson.TeacherId != lesson.TeacherId {
+		teacher, err := db.GetAccountById(&updatedLesson.TeacherId)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Teacher not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get teacher from database"})
+			return
+		}
+		info, ok := teacher.PermissionInfo.(models.TeacherInfo)
+		if !ok || info.SchoolId != lesson.SchoolId {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Teacher must teach at the lesson's school"})
+			return
+		}
+	}
+
 	// Update lesson in database
-	err = db.UpdateTimetable(&updatedLesson)
+	// 요일, 교시, 선생님, 교실이 바뀌면 다른 수업이나 이 수업을 듣는 학생의 다른 수업과 겹칠 수 있음
+	var conflicts []models.TimetableConflict
+	err = db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		found, err := tx.Timetables.GetSlotConflicts(&updatedLesson)
+		if err != nil {
+			return err
+		}
+		if len(found) > 0 {
+			conflicts = found
+			return errTimetableConflict
+		}
+		return tx.Timetables.UpdateTimetable(&updatedLesson)
+	})
+	if err == errTimetableConflict {
+		timetableConflict(c, conflicts)
+		return
+	}
+	if err == db.ErrVersionConflict {
+		current, err := db.GetTimeTableEntry(lesson.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update lesson"})
+			return
+		}
+		preconditionFailed(c, current.Version, current)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update lesson"})
 		return
 	}
 
+	setETag(c, updatedLesson.Version)
 	c.JSON(http.StatusOK, updatedLesson)
 }
 
@@ -154,8 +492,42 @@ func DeleteTimetable(c *gin.Context) {
 		return
 	}
 
+	// Get existing lesson from database
+	// 관리자가 아니면 다른 학교의 수업은 없는 것으로 봄
+	lesson, err := db.GetTimeTableEntry(models.DbId(id))
+	user := c.MustGet("account").(*models.Account)
+	if err == nil && user.GetLevel() != models.ADMIN && lesson.SchoolId != callerSchool(c) {
+		err = sql.ErrNoRows
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+	if !ifMatches(c, lesson.Version) {
+		preconditionFailed(c, lesson.Version, lesson)
+		return
+	}
+
 	// Delete lesson from database
-	err = db.DeleteTimetable(models.DbId(id))
+	// 읽은 뒤에 다른 요청이 수정했으면 지우지 않음
+	err = db.DeleteTimetable(lesson.ID, lesson.Version)
+	if err == db.ErrVersionConflict {
+		current, err := db.GetTimeTableEntry(lesson.ID)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete lesson"})
+			return
+		}
+		preconditionFailed(c, current.Version, current)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete lesson"})
 		return
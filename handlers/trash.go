@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+	"strconv"
+)
+
+var errChecklistExists = errors.New("student already has a checklist")
+
+// GetTrash handles the GET /admins/trash/:kind endpoint
+func GetTrash(c *gin.Context) {
+	var items interface{}
+	var err error
+
+	switch c.Param("kind") {
+	case "menus":
+		items, err = db.GetDeletedMenus()
+	case "checklists":
+		items, err = db.GetDeletedChecklists()
+	case "timetables":
+		items, err = db.GetDeletedTimetables()
+	case "events":
+		items, err = db.GetDeletedEvents()
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown trash kind"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trash from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// RestoreTrash handles the POST /admins/trash/:kind/:id/restore endpoint
+func RestoreTrash(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	switch c.Param("kind") {
+	case "menus":
+		err = db.RestoreMenu(models.DbId(id))
+	case "checklists":
+		err = restoreChecklist(c, models.DbId(id))
+	case "timetables":
+		err = db.RestoreTimetable(models.DbId(id))
+	case "events":
+		err = db.RestoreEvents(models.DbId(id))
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown trash kind"})
+		return
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item is not in the trash"})
+		return
+	}
+	if err == errChecklistExists {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore item"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// restoreChecklist 학생은 체크리스트를 하나만 가질 수 있으므로, 새로 만든 체크리스트가 없을 때만 복구하고
+// StudentInfo.ChecklistId도 다시 연결함
+func restoreChecklist(c *gin.Context, id models.DbId) error {
+	return db.WithTx(c.Request.Context(), func(tx db.Stores) error {
+		checklist, err := tx.Checklists.GetDeletedChecklistById(id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Checklists.GetChecklistsOfStudent(&checklist.StudentId)
+		if err == nil {
+			return errChecklistExists
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		err = tx.Checklists.RestoreChecklist(id)
+		if err != nil {
+			return err
+		}
+
+		account, err := tx.Accounts.GetAccountById(&checklist.StudentId)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		info, ok := account.PermissionInfo.(models.StudentInfo)
+		if !ok {
+			return nil
+		}
+		info.ChecklistId = id
+		account.PermissionInfo = info
+		return tx.Accounts.UpdateAccount(account)
+	})
+}
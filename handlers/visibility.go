@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+)
+
+// GetStudentTimetable handles the GET /students/:userId/timetable endpoint
+// 친구는 시간표가 공개되어 있거나 따로 허용받았을 때, 선생님은 같은 학교 학생일 때만 볼 수 있음
+func GetStudentTimetable(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	id, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	owner, err := db.GetAccountById(&id)
+	if err == nil && owner.GetLevel() != models.STUDENT {
+		err = sql.ErrNoRows
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get student from database"})
+		return
+	}
+
+	var override *bool
+	if user.GetLevel() == models.STUDENT {
+		overrides, err := db.GetVisibilityOverrides(owner)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable visibility from database"})
+			return
+		}
+		if visible, ok := overrides[user.UserId]; ok {
+			override = &visible
+		}
+	}
+	if !models.CanViewTimetable(user, owner, override) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Timetable is not visible to you"})
+		return
+	}
+
+	date, ok := queryDate(c)
+	if !ok {
+		return
+	}
+	resolved, err := resolveTimetable(owner.PermissionInfo.(models.StudentInfo), date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resolved)
+}
+
+// GetVisibilityOverrides handles the GET /students/timetable/visibility endpoint
+func GetVisibilityOverrides(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	overrides, err := db.GetVisibilityOverrides(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get timetable visibility from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, overrides)
+}
+
+// SetVisibilityOverride handles the PUT /students/timetable/visibility/:friendId endpoint
+// IsPublic과 상관없이 이 친구에게 시간표를 보여줄지 정함
+func SetVisibilityOverride(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+	info := user.PermissionInfo.(models.StudentInfo)
+
+	friendId, err := uuid.Parse(c.Param("friendId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if !info.HasFriend(friendId) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User is not in your friends list"})
+		return
+	}
+
+	var body struct {
+		Visible *bool `json:"visible"`
+	}
+	err = c.BindJSON(&body)
+	if err != nil || body.Visible == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	err = db.SetVisibilityOverride(user, &friendId, *body.Visible)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Friend not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save timetable visibility"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"friend": friendId, "visible": *body.Visible})
+}
+
+// DeleteVisibilityOverride handles the DELETE /students/timetable/visibility/:friendId endpoint
+func DeleteVisibilityOverride(c *gin.Context) {
+	user := c.MustGet("account").(*models.Account)
+
+	friendId, err := uuid.Parse(c.Param("friendId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	err = db.DeleteVisibilityOverride(user, &friendId)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No visibility setting for this friend"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete timetable visibility"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,104 @@
+// Package ical writes iCalendar (RFC 5545) files
+// 시간은 모두 UTC로 써서 VTIMEZONE 없이도 달력 앱이 올바르게 보여줌
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+const (
+	utcFormat = "20060102T150405Z"
+	// 한 줄은 CRLF를 빼고 75옥텟을 넘으면 안 됨 (RFC 5545 3.1)
+	maxLineOctets = 75
+)
+
+// Event is a VEVENT, repeated weekly until Until when Until is set
+type Event struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Start       time.Time
+	End         time.Time
+	Until       time.Time
+	ExDates     []time.Time
+}
+
+// Calendar is a VCALENDAR holding events
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Bytes renders the calendar with CRLF line endings and folded long lines
+func (cal Calendar) Bytes(now time.Time) []byte {
+	var buf bytes.Buffer
+	w := writer{&buf}
+
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//schoolapp//timetable//KO")
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	if cal.Name != "" {
+		w.line("X-WR-CALNAME:" + escape(cal.Name))
+	}
+
+	stamp := now.UTC().Format(utcFormat)
+	for _, event := range cal.Events {
+		w.line("BEGIN:VEVENT")
+		w.line("UID:" + escape(event.UID))
+		w.line("DTSTAMP:" + stamp)
+		w.line("DTSTART:" + event.Start.UTC().Format(utcFormat))
+		w.line("DTEND:" + event.End.UTC().Format(utcFormat))
+		if !event.Until.IsZero() {
+			w.line("RRULE:FREQ=WEEKLY;UNTIL=" + event.Until.UTC().Format(utcFormat))
+		}
+		if len(event.ExDates) > 0 {
+			dates := make([]string, len(event.ExDates))
+			for i, date := range event.ExDates {
+				dates[i] = date.UTC().Format(utcFormat)
+			}
+			w.line("EXDATE:" + strings.Join(dates, ","))
+		}
+		w.line("SUMMARY:" + escape(event.Summary))
+		if event.Location != "" {
+			w.line("LOCATION:" + escape(event.Location))
+		}
+		if event.Description != "" {
+			w.line("DESCRIPTION:" + escape(event.Description))
+		}
+		w.line("END:VEVENT")
+	}
+
+	w.line("END:VCALENDAR")
+	return buf.Bytes()
+}
+
+type writer struct {
+	buf *bytes.Buffer
+}
+
+// line 75옥텟마다 줄을 접되 UTF-8 문자 중간에서는 자르지 않음
+func (w writer) line(content string) {
+	octets := 0
+	for _, r := range content {
+		size := len(string(r))
+		if octets+size > maxLineOctets {
+			w.buf.WriteString("\r\n ")
+			// 접힌 줄은 앞의 공백 한 칸도 길이에 들어감
+			octets = 1
+		}
+		w.buf.WriteRune(r)
+		octets += size
+	}
+	w.buf.WriteString("\r\n")
+}
+
+var escaper = strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n")
+
+func escape(text string) string {
+	return escaper.Replace(text)
+}
@@ -0,0 +1,78 @@
+package ical
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var kst = time.FixedZone("KST", 9*60*60)
+
+func TestCalendarBytes(t *testing.T) {
+	start := time.Date(2024, 3, 4, 8, 40, 0, 0, kst)
+	cal := Calendar{
+		Name: "김학생, 2024; 1학기",
+		Events: []Event{
+			{
+				// 쉼표, 세미콜론, 역슬래시, 줄바꿈은 이스케이프하고 긴 줄은 75옥텟마다 접음
+				UID:         "timetable-1-term-2@schoolapp",
+				Summary:     "국어, 문학; 독서\\작문",
+				Location:    "본관 3층\r\n1-3",
+				Description: "박선생 선생님, 1교시\n교과서와 공책, 필기구를 챙겨 오세요; 수행평가가 있습니다. 늦지 않게 오세요.",
+				Start:       start,
+				End:         start.Add(50 * time.Minute),
+				Until:       time.Date(2024, 7, 19, 23, 59, 59, 0, kst),
+				ExDates:     []time.Time{start.AddDate(0, 0, 7), start.AddDate(0, 0, 14)},
+			},
+			{
+				UID:     "timetable-1-exception-3@schoolapp",
+				Summary: strings.Repeat("a", 80),
+				Start:   start.AddDate(0, 0, 7),
+				End:     start.AddDate(0, 0, 7).Add(50 * time.Minute),
+			},
+		},
+	}
+	got := cal.Bytes(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	if *update {
+		if err := os.WriteFile("testdata/calendar.ics", got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile("testdata/calendar.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Bytes() differs from testdata/calendar.ics:\n%s", got)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(string(got), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line is %d octets: %q", len(line), line)
+		}
+	}
+}
+
+func TestFoldKeepsCharactersWhole(t *testing.T) {
+	// 한글은 3옥텟이므로 75옥텟 경계에서 글자가 잘리면 안 됨
+	var buf bytes.Buffer
+	content := "SUMMARY:" + strings.Repeat("가", 40)
+	writer{&buf}.line(content)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines: %q", len(lines), lines)
+	}
+	if unfolded := lines[0] + strings.TrimPrefix(lines[1], " "); unfolded != content {
+		t.Errorf("unfolded = %q, want %q", unfolded, content)
+	}
+	if len(lines[0]) != 74 {
+		t.Errorf("first line is %d octets, want 74", len(lines[0]))
+	}
+}
@@ -4,15 +4,19 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/username/schoolapp/cache"
 	"github.com/username/schoolapp/db"
 	"github.com/username/schoolapp/handlers"
 	"github.com/username/schoolapp/middlewares"
 	"github.com/username/schoolapp/models"
+	"github.com/username/schoolapp/neis"
 	"github.com/username/schoolapp/utils"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 func main() {
@@ -22,10 +26,38 @@ func main() {
 		log.Fatal("Error loading .env file")
 	}
 
-	db.Connect()
+	// 마이그레이션이 급식의 알레르기 번호를 읽으므로 Connect보다 먼저 불러야 함
 	models.InitAllergies()
+	db.Connect()
+	configureCache()
 	utils.InitKeys()
 
+	// Permanently delete trashed items after the retention period
+	retentionDays := 30
+	if value := os.Getenv("TRASH_RETENTION_DAYS"); value != "" {
+		retentionDays, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatal("TRASH_RETENTION_DAYS should be a number")
+		}
+	}
+	db.StartTrashPurger(time.Duration(retentionDays)*24*time.Hour, time.Hour, handlers.RemoveMenuPhotoFiles)
+
+	// Allocate elective groups once their enrollment window closes
+	db.StartElectiveAllocator(time.Minute)
+
+	// Warn students who turned on allergy notifications about today's meals
+	db.StartAllergyNotifier(time.Hour)
+
+	// Import timetables and meals from NEIS, and keep scheduled classes and every school's meals in sync when NEIS_SYNC_HOURS is set
+	handlers.NeisClient = neis.NewClientFromEnv()
+	if value := os.Getenv("NEIS_SYNC_HOURS"); value != "" {
+		syncHours, err := strconv.Atoi(value)
+		if err != nil || syncHours <= 0 {
+			log.Fatal("NEIS_SYNC_HOURS should be a positive number")
+		}
+		neis.StartTimetableSync(handlers.NeisClient, time.Duration(syncHours)*time.Hour)
+	}
+
 	handlers.Oauth2Application = &oauth2.Config{
 		ClientID:     os.Getenv("OAUTH_ID"),
 		ClientSecret: os.Getenv("OAUTH_SECRET"),
@@ -50,6 +82,9 @@ func main() {
 		auth.POST("/login", handlers.Login)
 	}
 
+	// 달력 앱이 Authorization 헤더 없이 구독할 수 있도록 인증 미들웨어보다 먼저 등록함
+	r.GET("/feeds/:token/timetable.ics", handlers.GetTimetableFeed)
+
 	// Use authentication middleware for protected endpoints
 	r.Use(middlewares.CheckAuthHeader)
 	r.Use(middlewares.VerifyToken)
@@ -57,31 +92,123 @@ func main() {
 	// Routes for handling students
 	students := r.Group("/students")
 	{
+		students.GET("/now", middlewares.RequirePermission(models.STUDENT), handlers.GetTimetableNow)
+		students.GET("/timetable.ics", middlewares.RequirePermission(models.STUDENT), handlers.GetTimetableICS)
+		students.GET("/free_periods", middlewares.RequirePermission(models.STUDENT), handlers.GetFreePeriods)
+		students.GET("/allergies", middlewares.RequirePermission(models.STUDENT), handlers.GetAllergyProfile)
+		students.PUT("/allergies", middlewares.RequirePermission(models.STUDENT), handlers.SetAllergyProfile)
+		students.GET("/notifications", middlewares.RequirePermission(models.STUDENT), handlers.GetNotifications)
+		students.GET("/meal_pass", middlewares.RequirePermission(models.STUDENT), handlers.GetMealPass)
+		students.PUT("/notifications/:id/read", middlewares.RequirePermission(models.STUDENT), handlers.ReadNotification)
+		students.GET("/:userId/timetable", middlewares.RequirePermission(models.STUDENT, models.TEACHER), handlers.GetStudentTimetable)
+
 		// Routes for handling timetables
 		timetable := students.Group("/timetable")
 		{
-			timetable.GET("/lock", handlers.LockTimetable)
-			timetable.GET("/unlock", handlers.UnLockTimetable)
-			timetable.GET("", handlers.GetTimetableEntry)
-			timetable.POST("", handlers.CreateTimetable)
-			timetable.PUT("/:id", handlers.UpdateTimetable)
-			timetable.DELETE("/:id", handlers.DeleteTimetable)
+			student := middlewares.RequirePermission(models.STUDENT)
+			timetable.GET("/lock", student, handlers.LockTimetable)
+			timetable.GET("/unlock", student, handlers.UnLockTimetable)
+			timetable.GET("", student, handlers.GetTimetable)
+			timetable.GET("/week", student, handlers.GetTimetableWeek)
+			timetable.POST("/entries/:id", student, handlers.EnrollTimetableEntry)
+			timetable.DELETE("/entries/:id", student, handlers.DropTimetableEntry)
+			timetable.POST("/feed", student, handlers.CreateTimetableFeed)
+			timetable.DELETE("/feed", student, handlers.DeleteTimetableFeed)
+			timetable.GET("/visibility", student, handlers.GetVisibilityOverrides)
+			timetable.PUT("/visibility/:friendId", student, handlers.SetVisibilityOverride)
+			timetable.DELETE("/visibility/:friendId", student, handlers.DeleteVisibilityOverride)
+			timetable.GET("/:id", handlers.GetTimetableEntry)
+			timetable.POST("", middlewares.RequirePermission(models.TEACHER), handlers.CreateTimetable)
+			timetable.PUT("/:id", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.UpdateTimetable)
+			timetable.DELETE("/:id", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.DeleteTimetable)
 		}
 	}
 
+	// Routes for handling teachers
+	teachers := r.Group("/teachers")
+	{
+		teachers.GET("/timetable", middlewares.RequirePermission(models.TEACHER), handlers.GetTeacherTimetable)
+		teachers.GET("/timetable/:id/students", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.GetLessonRoster)
+
+		// Routes for changing lessons on a single date
+		exceptions := middlewares.RequirePermission(models.TEACHER, models.ADMIN)
+		teachers.GET("/timetable/exceptions", exceptions, handlers.GetTimetableExceptions)
+		teachers.POST("/timetable/:id/exceptions", exceptions, handlers.CreateTimetableException)
+		teachers.DELETE("/timetable/exceptions/:id", exceptions, handlers.DeleteTimetableException)
+	}
+
+	// Routes for handling elective groups
+	electives := r.Group("/electives", middlewares.RequirePermission(models.STUDENT, models.TEACHER, models.ADMIN))
+	{
+		staff := middlewares.RequirePermission(models.TEACHER, models.ADMIN)
+		student := middlewares.RequirePermission(models.STUDENT)
+		electives.GET("", handlers.GetElectiveGroups)
+		electives.GET("/:id", handlers.GetElectiveGroup)
+		electives.POST("", staff, handlers.CreateElectiveGroup)
+		electives.PUT("/:id/window", staff, handlers.SetElectiveWindow)
+		electives.POST("/:id/allocate", staff, handlers.AllocateElectiveGroup)
+		electives.DELETE("/:id", staff, handlers.DeleteElectiveGroup)
+		electives.GET("/:id/choices", staff, handlers.GetElectiveChoices)
+		electives.PUT("/:id/choice", student, handlers.ChooseElective)
+		electives.DELETE("/:id/choice", student, handlers.WithdrawElective)
+	}
+
 	admins := r.Group("/admins")
 	{
 		admins.GET("", handlers.GetAccountById)
 		admins.PUT("/config", handlers.UpdateAccount)
+
+		admins.GET("/cache/stats", middlewares.RequirePermission(models.ADMIN), handlers.GetCacheStats)
+		admins.GET("/timetable_conflicts", middlewares.RequirePermission(models.ADMIN), handlers.GetTimetableConflicts)
+
+		// Routes for importing data from NEIS
+		imports := admins.Group("/imports", middlewares.RequirePermission(models.ADMIN))
+		{
+			imports.POST("/timetable", handlers.ImportNeisTimetable)
+			imports.GET("/timetable", handlers.GetTimetableImports)
+			imports.DELETE("/timetable/:id", handlers.DeleteTimetableImport)
+			imports.POST("/meals", handlers.ImportNeisMeals)
+		}
+
+		// Routes for handling soft-deleted items
+		trash := admins.Group("/trash", middlewares.RequirePermission(models.ADMIN))
+		{
+			trash.GET("/:kind", handlers.GetTrash)
+			trash.POST("/:kind/:id/restore", handlers.RestoreTrash)
+		}
 	}
 
 	// Routes for handling cafeteria menus
 	cafeteriaMenus := r.Group("/cafeteria_menus")
 	{
-		cafeteriaMenus.GET("", handlers.GetCafeteriaMenus)
-		cafeteriaMenus.POST("", handlers.CreateCafeteriaMenu)
-		cafeteriaMenus.PUT("/:id", handlers.UpdateCafeteriaMenu)
-		cafeteriaMenus.DELETE("/:id", handlers.DeleteCafeteriaMenu)
+		cafeteriaMenus.GET("", middlewares.RequirePermission(models.STUDENT, models.TEACHER, models.ADMIN), handlers.GetCafeteriaMenus)
+		cafeteriaMenus.GET("/week", middlewares.RequirePermission(models.STUDENT, models.TEACHER, models.ADMIN), handlers.GetCafeteriaWeek)
+		cafeteriaMenus.GET("/allergens", handlers.GetAllergens)
+		cafeteriaMenus.GET("/:id", middlewares.RequirePermission(models.STUDENT, models.TEACHER, models.ADMIN), handlers.GetCafeteriaMenu)
+		cafeteriaMenus.GET("/:id/ratings", middlewares.RequirePermission(models.STUDENT, models.TEACHER, models.ADMIN), handlers.GetMealRatings)
+		cafeteriaMenus.GET("/:id/ratings/mine", middlewares.RequirePermission(models.STUDENT), handlers.GetMyMealRatings)
+		cafeteriaMenus.PUT("/:id/ratings", middlewares.RequirePermission(models.STUDENT), handlers.RateMeal)
+		cafeteriaMenus.DELETE("/:id/ratings", middlewares.RequirePermission(models.STUDENT), handlers.DeleteMealRating)
+		cafeteriaMenus.GET("/ratings/dashboard", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.GetRatingDashboard)
+		cafeteriaMenus.GET("/ratings/comments", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.GetRatingComments)
+		cafeteriaMenus.PUT("/ratings/:id/moderation", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.ModerateRatingComment)
+		cafeteriaMenus.GET("/signups", middlewares.RequirePermission(models.STUDENT), handlers.GetMySignups)
+		cafeteriaMenus.GET("/signups/policy", middlewares.RequirePermission(models.STUDENT, models.TEACHER, models.ADMIN), handlers.GetSignupPolicy)
+		cafeteriaMenus.PUT("/signups/policy", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.SetSignupPolicy)
+		cafeteriaMenus.GET("/headcount", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.GetHeadcounts)
+		cafeteriaMenus.PUT("/:id/signup", middlewares.RequirePermission(models.STUDENT), handlers.SignUpForMeal)
+		cafeteriaMenus.GET("/:id/headcount", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.GetMealHeadcount)
+		cafeteriaMenus.POST("/:id/checkin", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.CheckInMeal)
+		cafeteriaMenus.GET("/:id/photos", middlewares.RequirePermission(models.STUDENT, models.TEACHER, models.ADMIN), handlers.GetMenuPhotos)
+		cafeteriaMenus.POST("/:id/photos", middlewares.RequirePermission(models.STUDENT, models.TEACHER, models.ADMIN), handlers.UploadMenuPhoto)
+		cafeteriaMenus.PUT("/:id/photos/order", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.ReorderMenuPhotos)
+		cafeteriaMenus.GET("/photos/:id", middlewares.RequirePermission(models.STUDENT, models.TEACHER, models.ADMIN), handlers.ServeMenuPhoto)
+		cafeteriaMenus.GET("/photos/:id/thumbnail", middlewares.RequirePermission(models.STUDENT, models.TEACHER, models.ADMIN), handlers.ServeMenuThumbnail)
+		cafeteriaMenus.PUT("/photos/:id/hidden", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.SetMenuPhotoHidden)
+		cafeteriaMenus.DELETE("/photos/:id", middlewares.RequirePermission(models.STUDENT, models.TEACHER, models.ADMIN), handlers.DeleteMenuPhoto)
+		cafeteriaMenus.POST("", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.CreateCafeteriaMenu)
+		cafeteriaMenus.PUT("/:id", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.UpdateCafeteriaMenu)
+		cafeteriaMenus.DELETE("/:id", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.DeleteCafeteriaMenu)
 	}
 
 	// Routes for handling checklists
@@ -97,7 +224,34 @@ func main() {
 
 	events := r.Group("/events")
 	{
-		events.GET("/:months", handlers.GetEventsOfOneMonth)
+		events.GET("/:month", handlers.GetEventsOfOneMonth)
+		events.DELETE("/:id", middlewares.RequirePermission(models.TEACHER, models.ADMIN), handlers.DeleteEvents)
+	}
+
+	// Routes for handling bell schedules and terms
+	schools := r.Group("/schools/:schoolId")
+	{
+		admin := middlewares.RequirePermission(models.ADMIN)
+		schools.GET("/bell_schedules", handlers.GetBellSchedules)
+		schools.POST("/bell_schedules", admin, handlers.CreateBellSchedule)
+		schools.PUT("/bell_schedules/:id", admin, handlers.UpdateBellSchedule)
+		schools.DELETE("/bell_schedules/:id", admin, handlers.DeleteBellSchedule)
+		schools.GET("/bell_overrides", handlers.GetBellOverrides)
+		schools.PUT("/bell_overrides/:date", admin, handlers.SetBellOverride)
+		schools.DELETE("/bell_overrides/:date", admin, handlers.DeleteBellOverride)
+		schools.GET("/terms", handlers.GetTerms)
+		schools.POST("/terms", admin, handlers.CreateTerm)
+		schools.DELETE("/terms/:id", admin, handlers.DeleteTerm)
+	}
+
+	// Routes for handling classrooms
+	rooms := r.Group("/rooms", middlewares.RequirePermission(models.STUDENT, models.TEACHER, models.ADMIN))
+	{
+		rooms.GET("", handlers.GetRooms)
+		rooms.GET("/available", handlers.GetAvailableRooms)
+		rooms.GET("/:id/week", handlers.GetRoomWeek)
+		rooms.POST("", middlewares.RequirePermission(models.ADMIN), handlers.CreateRoom)
+		rooms.DELETE("/:id", middlewares.RequirePermission(models.ADMIN), handlers.DeleteRoom)
 	}
 
 	r.GET("/map", handlers.GetMap)
@@ -114,3 +268,38 @@ func main() {
 	}
 	db.Close()
 }
+
+// configureCache CACHE_DRIVER가 redis면 Redis를, none이면 캐시 없이, 그 외에는 프로세스 안의 LRU 캐시를 씀
+func configureCache() {
+	ttlSeconds := 300
+	if value := os.Getenv("CACHE_TTL_SECONDS"); value != "" {
+		var err error
+		ttlSeconds, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatal("CACHE_TTL_SECONDS should be a number")
+		}
+	}
+	ttl := time.Duration(ttlSeconds) * time.Second
+
+	switch os.Getenv("CACHE_DRIVER") {
+	case "none":
+		return
+	case "redis":
+		database, _ := strconv.Atoi(os.Getenv("REDIS_DB"))
+		redisCache, err := cache.NewRedis(os.Getenv("REDIS_ADDR"), os.Getenv("REDIS_PASSWORD"), database, "schoolapp:", ttl)
+		if err != nil {
+			log.Fatalf("Error connecting to redis: %s", err.Error())
+		}
+		db.EnableCache(redisCache)
+	default:
+		size := 10000
+		if value := os.Getenv("CACHE_SIZE"); value != "" {
+			var err error
+			size, err = strconv.Atoi(value)
+			if err != nil {
+				log.Fatal("CACHE_SIZE should be a number")
+			}
+		}
+		db.EnableCache(cache.NewLRU(size, ttl))
+	}
+}
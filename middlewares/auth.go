@@ -64,6 +64,9 @@ func VerifyToken(c *gin.Context) {
 		return
 	}
 
+	// CheckAuthHeader처럼 "Bearer " 뒤의 토큰만 확인함
+	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+
 	// Verify JWT token
 	id, err := utils.ParseJWT(&tokenString, "user_id")
 	if err != nil {
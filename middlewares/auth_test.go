@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/utils"
+)
+
+// 로그인 토큰이 main.go와 같은 순서로 붙인 인증 미들웨어를 모두 통과하는지 확인함
+func TestLoginTokenPassesAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := utils.PRIVATE
+	utils.PRIVATE = key
+	t.Cleanup(func() {
+		utils.PRIVATE = previous
+	})
+
+	student := uuid.MustParse("0b7d3f1e-6a2c-4e9b-8d1f-2c3b4a5d6e7f")
+	login, err := utils.SignJWT(student.String(), "user_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pass, err := utils.SignMealPass(student, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.Use(CheckAuthHeader)
+	router.Use(VerifyToken)
+	router.GET("/me", func(c *gin.Context) {
+		c.String(http.StatusOK, c.MustGet("user_id").(uuid.UUID).String())
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"login token", "Bearer " + login, http.StatusOK},
+		{"no header", "", http.StatusUnauthorized},
+		{"not a token", "Bearer not.a.token", http.StatusUnauthorized},
+		// 식권 QR 코드를 찍어서 로그인할 수 없어야 함
+		{"meal pass", "Bearer " + pass, http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if test.header != "" {
+				request.Header.Set("Authorization", test.header)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			if recorder.Code != test.status {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, test.status, recorder.Body.String())
+			}
+			if test.status == http.StatusOK && recorder.Body.String() != student.String() {
+				t.Errorf("user_id = %s, want %s", recorder.Body.String(), student)
+			}
+		})
+	}
+}
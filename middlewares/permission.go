@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"net/http"
+)
+
+// RequirePermission 주어진 권한 레벨 중 하나를 가진 계정만 통과시킴
+// 통과한 계정은 이후 핸들러에서 쓸 수 있도록 "account" 키로 컨텍스트에 저장됨
+func RequirePermission(levels ...models.PermissionLevel) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fetched, exists := c.Get("user_id")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user id is not supplied to auth header"})
+			return
+		}
+
+		userId := fetched.(uuid.UUID)
+		account, err := db.GetAccountById(&userId)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "account not found"})
+			return
+		}
+
+		for _, level := range levels {
+			if account.PermissionInfo.GetLevel() == level {
+				c.Set("account", account)
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permission"})
+	}
+}
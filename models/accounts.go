@@ -13,8 +13,10 @@ type Account struct {
 	Email          string    `json:"email"`
 	Password       []byte
 	PermissionInfo `json:"permission"`
+	Version        int64 `json:"version"`
 }
 
+// FlatAccount accounts 테이블의 한 행과 그 계정의 student_timetable_entries, friendships 행들
 type FlatAccount struct {
 	DbId
 	UserId   []byte
@@ -23,26 +25,24 @@ type FlatAccount struct {
 	Password []byte
 	PermissionLevel
 	SchoolId
-	TimeTableEntries  []byte
+	TimeTableEntries  []DbId
 	TimeTableIsPublic bool
 	Grade             int
 	Class             int
 	Number            int
 	ChecklistId       DbId
-	Friends           []byte
+	Friends           []uuid.UUID
+	Version           int64
 }
 
 func (flatAccount FlatAccount) Restore() (Account, error) {
 	var info PermissionInfo
 	var err error
 
-	timetableEntries := Int64ArrayToDbIdArray(BytesToInt64Array(flatAccount.TimeTableEntries))
-	friends := BytesToUUIDArray(flatAccount.Friends)
-
 	switch flatAccount.PermissionLevel {
 	case STUDENT:
 		timetable := Timetable{
-			Entries:  timetableEntries,
+			Entries:  flatAccount.TimeTableEntries,
 			IsPublic: flatAccount.TimeTableIsPublic,
 		}
 		info = StudentInfo{
@@ -52,7 +52,7 @@ func (flatAccount FlatAccount) Restore() (Account, error) {
 			Class:       flatAccount.Class,
 			Number:      flatAccount.Number,
 			ChecklistId: flatAccount.ChecklistId,
-			Friends:     friends,
+			Friends:     flatAccount.Friends,
 		}
 	case TEACHER:
 		info = TeacherInfo{
@@ -78,6 +78,7 @@ func (flatAccount FlatAccount) Restore() (Account, error) {
 		Email:          flatAccount.Email,
 		Password:       flatAccount.Password,
 		PermissionInfo: info,
+		Version:        flatAccount.Version,
 	}, nil
 }
 
@@ -90,18 +91,19 @@ func (account Account) ToSql() (FlatAccount, error) {
 	toReturn.Email = account.Email
 	toReturn.Password = account.Password
 	toReturn.PermissionLevel = account.PermissionInfo.GetLevel()
+	toReturn.Version = account.Version
 
 	switch account.PermissionInfo.GetLevel() {
 	case STUDENT:
 		info := account.PermissionInfo.(StudentInfo)
 		toReturn.SchoolId = info.SchoolId
-		toReturn.TimeTableEntries = Int64ArrayToBytes(DbIdArrayToInt64Array(info.Timetable.Entries))
+		toReturn.TimeTableEntries = info.Timetable.Entries
 		toReturn.TimeTableIsPublic = info.Timetable.IsPublic
 		toReturn.Grade = info.Grade
 		toReturn.Class = info.Class
 		toReturn.Number = info.Number
 		toReturn.ChecklistId = info.ChecklistId
-		toReturn.Friends = UuidArrayToBytes(info.Friends)
+		toReturn.Friends = info.Friends
 	case TEACHER:
 		info := account.PermissionInfo.(TeacherInfo)
 		toReturn.SchoolId = info.SchoolId
@@ -147,6 +149,15 @@ func (info StudentInfo) GetLevel() PermissionLevel {
 	return STUDENT
 }
 
+func (info StudentInfo) HasFriend(id uuid.UUID) bool {
+	for _, friend := range info.Friends {
+		if friend == id {
+			return true
+		}
+	}
+	return false
+}
+
 type TeacherInfo struct {
 	SchoolId `json:"school_id"`
 }
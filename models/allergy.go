@@ -0,0 +1,103 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// AllergyProfile is what a student cannot eat
+// Allergens는 Allergies의 표준 번호이고, Ingredients는 번호가 없는 재료(예: 키위)를 요리 이름에서 찾음
+type AllergyProfile struct {
+	Allergens   []AllergyType `json:"allergens"`
+	Ingredients []string      `json:"ingredients"`
+	// 오늘 급식에 알레르기 재료가 있으면 알림을 받을지
+	Notify bool `json:"notify"`
+}
+
+// DishWarning tells which parts of a student's profile a dish conflicts with
+type DishWarning struct {
+	Dish        string        `json:"dish"`
+	Allergens   []AllergyType `json:"allergens"`
+	Ingredients []string      `json:"ingredients"`
+}
+
+// Empty reports whether the profile has nothing to warn about
+func (profile AllergyProfile) Empty() bool {
+	return len(profile.Allergens) == 0 && len(profile.Ingredients) == 0
+}
+
+// Check returns one warning for each dish that contains something in the profile
+func (profile AllergyProfile) Check(dishes []Dish) []DishWarning {
+	warnings := make([]DishWarning, 0)
+	for _, dish := range dishes {
+		warning := DishWarning{Dish: dish.Name, Allergens: make([]AllergyType, 0), Ingredients: make([]string, 0)}
+		for _, allergen := range dish.Allergens {
+			for _, avoid := range profile.Allergens {
+				if allergen == avoid {
+					warning.Allergens = append(warning.Allergens, allergen)
+					break
+				}
+			}
+		}
+		name := strings.ToLower(dish.Name)
+		for _, ingredient := range profile.Ingredients {
+			if strings.Contains(name, strings.ToLower(ingredient)) {
+				warning.Ingredients = append(warning.Ingredients, ingredient)
+			}
+		}
+		if len(warning.Allergens) > 0 || len(warning.Ingredients) > 0 {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// AllergenNames returns the Korean names of allergens, e.g. ["대두", "밀"]
+func AllergenNames(allergens []AllergyType) []string {
+	names := make([]string, len(allergens))
+	for i, allergen := range allergens {
+		names[i] = allergen.String()
+	}
+	return names
+}
+
+// Notification is a message shown in a user's inbox
+type Notification struct {
+	ID        DbId       `json:"id"`
+	Kind      string     `json:"kind"`
+	Message   string     `json:"message"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at"`
+}
+
+// NotificationAllergy 오늘 급식에 알레르기 재료가 있다는 알림
+const NotificationAllergy = "allergy"
+
+// AllergyMessage writes the notification text for a meal, e.g. "오늘 중식에 알레르기 주의 요리가 있어요: 쇠고기미역국(대두, 쇠고기)"
+func AllergyMessage(menu CafeteriaMenu, warnings []DishWarning) string {
+	dishes := make([]string, len(warnings))
+	for i, warning := range warnings {
+		reasons := append(AllergenNames(warning.Allergens), warning.Ingredients...)
+		dishes[i] = warning.Dish + "(" + strings.Join(reasons, ", ") + ")"
+	}
+	return "오늘 " + menu.MealName + "에 알레르기 주의 요리가 있어요: " + strings.Join(dishes, ", ")
+}
+
+// AllergenInfo is one of the standard allergens a profile can be built from
+type AllergenInfo struct {
+	Code AllergyType `json:"code"`
+	Name string      `json:"name"`
+}
+
+// AllergenList returns every standard allergen in number order
+func AllergenList() []AllergenInfo {
+	list := make([]AllergenInfo, 0, Allergies.Size())
+	for code, name := range Allergies.GetInverseMap() {
+		list = append(list, AllergenInfo{Code: AllergyType(code), Name: name})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+	return list
+}
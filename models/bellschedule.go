@@ -0,0 +1,130 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ClockTime is a time of day in minutes since midnight, written as "HH:MM" in JSON
+type ClockTime int
+
+func ParseClockTime(s string) (ClockTime, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, errors.New("invalid time of day, expected HH:MM: " + s)
+	}
+	return ClockTime(t.Hour()*60 + t.Minute()), nil
+}
+
+func (t ClockTime) String() string {
+	return fmt.Sprintf("%02d:%02d", int(t)/60, int(t)%60)
+}
+
+// On returns the moment of this time of day on the given date, in the date's location
+func (t ClockTime) On(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, int(t)/60, int(t)%60, 0, 0, date.Location())
+}
+
+func (t ClockTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func (t *ClockTime) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	parsed, err := ParseClockTime(s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// BellPeriod is the start and end time of one period
+type BellPeriod struct {
+	Period Period    `json:"period"`
+	Start  ClockTime `json:"start"`
+	End    ClockTime `json:"end"`
+}
+
+// BellSchedule maps period numbers to clock times for a school
+// Weekdays에 있는 요일에는 이 시간표를 쓰고, 어느 시간표에도 없는 요일에는 IsDefault인 시간표를 씀
+// 예) 수요일 단축 수업, 시험 기간 시간표
+type BellSchedule struct {
+	ID        DbId `json:"id"`
+	SchoolId  `json:"school_id"`
+	Name      string       `json:"name"`
+	Weekdays  []Weekday    `json:"weekdays"`
+	IsDefault bool         `json:"is_default"`
+	Periods   []BellPeriod `json:"periods"`
+	Version   int64        `json:"version"`
+}
+
+// Times returns the start and end time of the given period
+func (schedule *BellSchedule) Times(period Period) (BellPeriod, bool) {
+	if schedule == nil {
+		return BellPeriod{}, false
+	}
+	for _, bell := range schedule.Periods {
+		if bell.Period == period {
+			return bell, true
+		}
+	}
+	return BellPeriod{}, false
+}
+
+// BellScheduleOverride replaces a school's bell schedule on one date
+type BellScheduleOverride struct {
+	ID         DbId `json:"id"`
+	SchoolId   `json:"school_id"`
+	Date       string `json:"date"`
+	ScheduleId DbId   `json:"schedule_id"`
+	Note       string `json:"note"`
+}
+
+// WeekdayOf converts a time.Weekday, where Sunday is 0, to a Weekday
+func WeekdayOf(t time.Time) Weekday {
+	if t.Weekday() == time.Sunday {
+		return Sunday
+	}
+	return Weekday(t.Weekday())
+}
+
+// ScheduleFor returns the bell schedule the school normally uses on the given day, or nil if there is none
+func (school *School) ScheduleFor(day Weekday) *BellSchedule {
+	var fallback *BellSchedule
+	for i := range school.BellSchedules {
+		schedule := &school.BellSchedules[i]
+		for _, d := range schedule.Weekdays {
+			if d == day {
+				return schedule
+			}
+		}
+		if schedule.IsDefault && fallback == nil {
+			fallback = schedule
+		}
+	}
+	return fallback
+}
+
+// ScheduleOn returns the bell schedule used on the given date, applying any override for that date
+func (school *School) ScheduleOn(date time.Time, overrides []BellScheduleOverride) *BellSchedule {
+	key := date.Format("2006-01-02")
+	for _, override := range overrides {
+		if override.Date != key {
+			continue
+		}
+		for i := range school.BellSchedules {
+			if school.BellSchedules[i].ID == override.ScheduleId {
+				return &school.BellSchedules[i]
+			}
+		}
+	}
+	return school.ScheduleFor(WeekdayOf(date))
+}
@@ -6,17 +6,8 @@ import (
 	"github.com/google/uuid"
 )
 
-func Int64ArrayToBytes(arr []int64) []byte {
-	buf := new(bytes.Buffer)
-	for _, value := range arr {
-		err := binary.Write(buf, binary.LittleEndian, value)
-		if err != nil {
-			panic(err) // handle the error according to your needs
-		}
-	}
-	return buf.Bytes()
-}
-
+// 예전에는 시간표와 친구 목록을 accounts 테이블에 바이트 배열로 저장했었음
+// 지금은 db 마이그레이션에서 옛 데이터를 읽을 때만 사용함
 func BytesToInt64Array(data []byte) []int64 {
 	buf := bytes.NewBuffer(data)
 	arrayLength := len(data) / 8 // Each int64 occupies 8 bytes
@@ -30,14 +21,6 @@ func BytesToInt64Array(data []byte) []int64 {
 	return int64Array
 }
 
-func UuidArrayToBytes(arr []uuid.UUID) []byte {
-	byteArray := make([]byte, 0)
-	for _, u := range arr {
-		byteArray = append(byteArray, u[:]...)
-	}
-	return byteArray
-}
-
 func BytesToUUIDArray(data []byte) []uuid.UUID {
 	arrayLength := len(data) / 16 // Each UUID occupies 16 bytes
 	uuidArray := make([]uuid.UUID, arrayLength)
@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"github.com/google/uuid"
+	"time"
 )
 
 // Checklist struct represents a to-do list
@@ -11,6 +12,8 @@ type Checklist struct {
 	StudentId uuid.UUID       `json:"student_id"`
 	Title     string          `json:"title"`
 	Items     []ChecklistItem `json:"items"`
+	Version   int64           `json:"version"`
+	DeletedAt *time.Time      `json:"deleted_at,omitempty"`
 }
 
 func (checklist Checklist) Flatten() (FlatCheckList, error) {
@@ -23,6 +26,8 @@ func (checklist Checklist) Flatten() (FlatCheckList, error) {
 		StudentId: checklist.StudentId,
 		Title:     checklist.Title,
 		Items:     string(data),
+		Version:   checklist.Version,
+		DeletedAt: checklist.DeletedAt,
 	}, nil
 }
 
@@ -38,6 +43,8 @@ func (flatten FlatCheckList) Restore() (Checklist, error) {
 		StudentId: flatten.StudentId,
 		Title:     flatten.Title,
 		Items:     result,
+		Version:   flatten.Version,
+		DeletedAt: flatten.DeletedAt,
 	}, nil
 }
 
@@ -46,6 +53,8 @@ type FlatCheckList struct {
 	StudentId uuid.UUID
 	Title     string
 	Items     string
+	Version   int64
+	DeletedAt *time.Time
 }
 
 // ChecklistItem struct represents an item in a to-do list
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// ConflictKind tells what two or more lessons in the same day and period share
+type ConflictKind string
+
+const (
+	StudentConflict  ConflictKind = "student"  // 한 학생이 같은 시간에 수업을 두 개 들음
+	TeacherConflict  ConflictKind = "teacher"  // 한 선생님이 같은 시간에 수업을 두 개 맡음
+	LocationConflict ConflictKind = "location" // 같은 시간에 한 교실을 두 수업이 씀
+)
+
+// TimetableConflict is a set of lessons that clash in one day and period
+// Kind에 따라 TeacherId, Location, StudentId 중 하나만 채워짐
+type TimetableConflict struct {
+	Kind      ConflictKind     `json:"kind"`
+	Day       Weekday          `json:"day"`
+	Period    Period           `json:"period"`
+	TeacherId *uuid.UUID       `json:"teacher,omitempty"`
+	Location  string           `json:"location,omitempty"`
+	StudentId *uuid.UUID       `json:"student,omitempty"`
+	Entries   []TimetableEntry `json:"entries"`
+}
+
+// StudentConflictWith returns the student's lessons that clash with lesson, or nil if there are none
+func StudentConflictWith(student uuid.UUID, enrolled []TimetableEntry, lesson TimetableEntry) *TimetableConflict {
+	clashing := make([]TimetableEntry, 0)
+	for _, entry := range enrolled {
+		if entry.ID != lesson.ID && entry.Day == lesson.Day && entry.Period == lesson.Period {
+			clashing = append(clashing, entry)
+		}
+	}
+	if len(clashing) == 0 {
+		return nil
+	}
+	return &TimetableConflict{
+		Kind:      StudentConflict,
+		Day:       lesson.Day,
+		Period:    lesson.Period,
+		StudentId: &student,
+		Entries:   append(clashing, lesson),
+	}
+}
+
+// ConflictsOn returns the clashes with lesson that still happen on date once that day's exceptions are applied
+// lesson은 예외를 반영한 수업이고, template은 주간 시간표 기준 충돌(GetSlotConflicts 결과),
+// others는 그날 예외가 걸린 다른 수업으로 옮겨 오거나 선생님, 교실이 바뀌어서 새로 겹칠 수 있는 수업임
+func ConflictsOn(date time.Time, lesson TimetableEntry, template []TimetableConflict, others []TimetableEntry, exceptions []TimetableException) []TimetableConflict {
+	clashes := func(kind ConflictKind, entry TimetableEntry) bool {
+		if entry.ID == lesson.ID || entry.Period != lesson.Period {
+			return false
+		}
+		switch kind {
+		case TeacherConflict:
+			return entry.TeacherId == lesson.TeacherId
+		case LocationConflict:
+			return entry.Location == lesson.Location
+		}
+		// 학생 충돌에는 그 학생이 듣는 수업만 들어 있으므로 교시만 같으면 겹침
+		return true
+	}
+
+	conflicts := make([]TimetableConflict, 0)
+	byKind := make(map[ConflictKind]int)
+	seen := make(map[ConflictKind]map[DbId]bool)
+	for _, conflict := range template {
+		held, _ := ApplyExceptions(date, conflict.Entries, exceptions)
+		kept := make([]TimetableEntry, 0, len(held))
+		for _, entry := range held {
+			if clashes(conflict.Kind, entry) {
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		if seen[conflict.Kind] == nil {
+			seen[conflict.Kind] = make(map[DbId]bool)
+		}
+		for _, entry := range kept {
+			seen[conflict.Kind][entry.ID] = true
+		}
+		conflict.Entries = append(kept, lesson)
+		byKind[conflict.Kind] = len(conflicts)
+		conflicts = append(conflicts, conflict)
+	}
+
+	held, _ := ApplyExceptions(date, others, exceptions)
+	for _, kind := range []ConflictKind{TeacherConflict, LocationConflict} {
+		for _, entry := range held {
+			if !clashes(kind, entry) || seen[kind][entry.ID] {
+				continue
+			}
+			i, ok := byKind[kind]
+			if !ok {
+				conflict := TimetableConflict{Kind: kind, Day: lesson.Day, Period: lesson.Period, Entries: []TimetableEntry{lesson}}
+				if kind == TeacherConflict {
+					teacher := lesson.TeacherId
+					conflict.TeacherId = &teacher
+				} else {
+					conflict.Location = lesson.Location
+				}
+				i = len(conflicts)
+				byKind[kind] = i
+				conflicts = append(conflicts, conflict)
+			}
+			// lesson은 항상 마지막에 두도록 그 앞에 끼워 넣음
+			entries := conflicts[i].Entries
+			last := len(entries) - 1
+			conflicts[i].Entries = append(append(entries[:last:last], entry), entries[last])
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var (
+	testStudent = uuid.UUID{15: 1}
+	teacherKim  = uuid.UUID{15: 2}
+	teacherLee  = uuid.UUID{15: 3}
+)
+
+func entry(id DbId, day Weekday, period Period, teacher uuid.UUID, location string) TimetableEntry {
+	return TimetableEntry{ID: id, SchoolId: "7430310", TeacherId: teacher, Location: location, Day: day, Period: period, Subject: "과목"}
+}
+
+func TestStudentConflictWith(t *testing.T) {
+	lesson := entry(10, Monday, 2, teacherKim, "과학실")
+	tests := []struct {
+		name     string
+		enrolled []TimetableEntry
+		want     *TimetableConflict
+	}{
+		{
+			// 선생님과 교실이 달라도 학생은 한 시간에 한 수업만 들을 수 있음
+			name:     "same slot with a different teacher and room",
+			enrolled: []TimetableEntry{entry(1, Monday, 1, teacherLee, "1-3"), entry(2, Monday, 2, teacherLee, "1-3")},
+			want: &TimetableConflict{
+				Kind:      StudentConflict,
+				Day:       Monday,
+				Period:    2,
+				StudentId: &testStudent,
+				Entries:   []TimetableEntry{entry(2, Monday, 2, teacherLee, "1-3"), lesson},
+			},
+		},
+		{
+			name:     "every clashing lesson is listed",
+			enrolled: []TimetableEntry{entry(2, Monday, 2, teacherLee, "1-3"), entry(3, Monday, 2, teacherKim, "과학실")},
+			want: &TimetableConflict{
+				Kind:      StudentConflict,
+				Day:       Monday,
+				Period:    2,
+				StudentId: &testStudent,
+				Entries:   []TimetableEntry{entry(2, Monday, 2, teacherLee, "1-3"), entry(3, Monday, 2, teacherKim, "과학실"), lesson},
+			},
+		},
+		{
+			name:     "same period on another day",
+			enrolled: []TimetableEntry{entry(2, Tuesday, 2, teacherKim, "과학실")},
+			want:     nil,
+		},
+		{
+			// 이미 듣는 수업을 다시 신청하는 것은 충돌이 아님
+			name:     "the lesson itself",
+			enrolled: []TimetableEntry{lesson},
+			want:     nil,
+		},
+		{
+			name:     "nothing enrolled",
+			enrolled: nil,
+			want:     nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := StudentConflictWith(testStudent, test.enrolled, lesson)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("StudentConflictWith() =\n%+v\nwant\n%+v", got, test.want)
+			}
+		})
+	}
+}
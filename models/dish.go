@@ -0,0 +1,178 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Dish is one item of a meal with the allergens it contains
+type Dish struct {
+	Name      string        `json:"name"`
+	Allergens []AllergyType `json:"allergens"`
+}
+
+// Nutrient is one line of a meal's nutrition facts, e.g. 단백질 30.1g
+type Nutrient struct {
+	Name   string  `json:"name"`
+	Unit   string  `json:"unit"`
+	Amount float64 `json:"amount"`
+}
+
+// Origin tells where an ingredient of a meal comes from, e.g. 쇠고기: 국내산(한우)
+type Origin struct {
+	Ingredient string `json:"ingredient"`
+	Origin     string `json:"origin"`
+}
+
+var (
+	// NEIS는 줄바꿈을 <br/>로 줌
+	lineBreak = regexp.MustCompile(`(?i)<br\s*/?>|\n`)
+	// 요리 이름 끝의 알레르기 번호, "(1.2.5.)"나 "1.2.5." 모두 받아들임
+	allergenSuffix = regexp.MustCompile(`\s*\(?((?:\d{1,2}\.)*\d{1,2}\.?)\)?\s*$`)
+	// "단백질(g) : 30.1"
+	nutrientLine = regexp.MustCompile(`^(.+?)\s*\((.+?)\)\s*:\s*([\d.]+)`)
+	// "812.5 Kcal"
+	caloriesValue = regexp.MustCompile(`[\d.]+`)
+)
+
+// String returns the allergen's Korean name, or its number if it is not a standard allergen
+func (allergy AllergyType) String() string {
+	if name, ok := Allergies.GetInverse(int8(allergy)); ok {
+		return name
+	}
+	return strconv.Itoa(int(allergy))
+}
+
+// Valid reports whether allergy is one of the standard allergen numbers
+func (allergy AllergyType) Valid() bool {
+	_, ok := Allergies.GetInverse(int8(allergy))
+	return ok
+}
+
+// SplitLines splits a NEIS field on its <br/> line breaks and drops empty lines
+func SplitLines(text string) []string {
+	lines := make([]string, 0)
+	for _, line := range lineBreak.Split(text, -1) {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// ParseDish reads a dish name with its trailing allergen numbers, e.g. "쇠고기미역국 (5.6.13.16.)"
+// 번호가 아닌 것이나 1~18 밖의 번호는 알레르기로 보지 않음
+func ParseDish(text string) Dish {
+	text = strings.TrimSpace(text)
+	dish := Dish{Name: text, Allergens: make([]AllergyType, 0)}
+
+	match := allergenSuffix.FindStringSubmatchIndex(text)
+	if match == nil || match[0] == 0 {
+		return dish
+	}
+	codes := text[match[2]:match[3]]
+	// 끝에 점이나 괄호가 없는 숫자는 "우유2"처럼 이름의 일부일 수 있으므로 번호로 보지 않음
+	if !strings.Contains(codes, ".") && !strings.HasSuffix(text, ")") {
+		return dish
+	}
+
+	allergens := make([]AllergyType, 0)
+	for _, code := range strings.Split(codes, ".") {
+		if code == "" {
+			continue
+		}
+		n, err := strconv.Atoi(code)
+		if err != nil || !AllergyType(n).Valid() {
+			return dish
+		}
+		allergens = append(allergens, AllergyType(n))
+	}
+	dish.Name = strings.TrimSpace(text[:match[0]])
+	dish.Allergens = allergens
+	return dish
+}
+
+// ParseDishes reads every dish of a NEIS DDISH_NM field
+func ParseDishes(text string) []Dish {
+	lines := SplitLines(text)
+	dishes := make([]Dish, len(lines))
+	for i, line := range lines {
+		dishes[i] = ParseDish(line)
+	}
+	return dishes
+}
+
+// FormatDish writes a dish the way NEIS prints it, e.g. "쇠고기미역국 (5.6.16.)"
+func FormatDish(dish Dish) string {
+	if len(dish.Allergens) == 0 {
+		return dish.Name
+	}
+	codes := make([]string, len(dish.Allergens))
+	for i, allergen := range dish.Allergens {
+		codes[i] = strconv.Itoa(int(allergen)) + "."
+	}
+	return dish.Name + " (" + strings.Join(codes, "") + ")"
+}
+
+// FlattenDishes writes dishes as one line each, the form older clients read from items
+func FlattenDishes(dishes []Dish) string {
+	lines := make([]string, len(dishes))
+	for i, dish := range dishes {
+		lines[i] = FormatDish(dish)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SyncContents keeps the legacy Contents string and Dishes describing the same meal
+// Dishes가 있으면 Contents를 Dishes로 다시 쓰고, 예전 클라이언트처럼 Contents만 보냈으면 Contents를 읽어 Dishes를 채움
+func (menu *CafeteriaMenu) SyncContents() {
+	if len(menu.Dishes) > 0 {
+		menu.Contents = FlattenDishes(menu.Dishes)
+		return
+	}
+	menu.Dishes = ParseDishes(menu.Contents)
+}
+
+// ParseNutrients reads a NEIS NTR_INFO field, skipping lines it cannot read
+func ParseNutrients(text string) []Nutrient {
+	nutrients := make([]Nutrient, 0)
+	for _, line := range SplitLines(text) {
+		match := nutrientLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		amount, err := strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			continue
+		}
+		nutrients = append(nutrients, Nutrient{Name: strings.TrimSpace(match[1]), Unit: strings.TrimSpace(match[2]), Amount: amount})
+	}
+	return nutrients
+}
+
+// ParseOrigins reads a NEIS ORPLC_INFO field, e.g. "쇠고기 : 국내산(한우)"
+func ParseOrigins(text string) []Origin {
+	origins := make([]Origin, 0)
+	for _, line := range SplitLines(text) {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		origin := Origin{Ingredient: strings.TrimSpace(parts[0]), Origin: strings.TrimSpace(parts[1])}
+		if origin.Ingredient == "" || origin.Origin == "" {
+			continue
+		}
+		origins = append(origins, origin)
+	}
+	return origins
+}
+
+// ParseCalories reads a NEIS CAL_INFO field such as "812.5 Kcal"
+func ParseCalories(text string) *float64 {
+	calories, err := strconv.ParseFloat(caloriesValue.FindString(text), 64)
+	if err != nil {
+		return nil
+	}
+	return &calories
+}
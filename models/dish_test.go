@@ -0,0 +1,150 @@
+package models
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	InitAllergies()
+	os.Exit(m.Run())
+}
+
+func TestParseDish(t *testing.T) {
+	tests := []struct {
+		text string
+		want Dish
+	}{
+		{"쇠고기미역국 (5.6.16.)", Dish{Name: "쇠고기미역국", Allergens: []AllergyType{5, 6, 16}}},
+		{"배추김치(9.13.)", Dish{Name: "배추김치", Allergens: []AllergyType{9, 13}}},
+		{"토스트 5.6.", Dish{Name: "토스트", Allergens: []AllergyType{5, 6}}},
+		{"떡국 (1)", Dish{Name: "떡국", Allergens: []AllergyType{1}}},
+		{"  쌀밥  ", Dish{Name: "쌀밥", Allergens: []AllergyType{}}},
+		// 괄호나 점이 없는 숫자는 이름의 일부임
+		{"우유2", Dish{Name: "우유2", Allergens: []AllergyType{}}},
+		// 1~18 밖의 번호가 섞여 있으면 알레르기 번호로 보지 않음
+		{"감자튀김 (19.)", Dish{Name: "감자튀김 (19.)", Allergens: []AllergyType{}}},
+		{"감자튀김 (5.19.)", Dish{Name: "감자튀김 (5.19.)", Allergens: []AllergyType{}}},
+		// 이름 없이 번호만 있으면 전체를 이름으로 둠
+		{"(5.6.)", Dish{Name: "(5.6.)", Allergens: []AllergyType{}}},
+		{"", Dish{Name: "", Allergens: []AllergyType{}}},
+	}
+	for _, test := range tests {
+		got := ParseDish(test.text)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseDish(%q) = %+v, want %+v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestParseDishes(t *testing.T) {
+	got := ParseDishes("<br/>쌀밥<BR />쇠고기미역국 (5.6.16.)<br>\n  <br/>")
+	want := []Dish{
+		{Name: "쌀밥", Allergens: []AllergyType{}},
+		{Name: "쇠고기미역국", Allergens: []AllergyType{5, 6, 16}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDishes() = %+v, want %+v", got, want)
+	}
+	if text := FlattenDishes(got); text != "쌀밥\n쇠고기미역국 (5.6.16.)" {
+		t.Errorf("FlattenDishes() = %q", text)
+	}
+}
+
+func TestParseNutrients(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Nutrient
+	}{
+		{
+			name: "recorded",
+			text: "탄수화물(g) : 120.3<br/>단백질(g) : 30.1<br/>비타민A(R.E) : 150.2<br/>칼슘(mg) : 300",
+			want: []Nutrient{
+				{Name: "탄수화물", Unit: "g", Amount: 120.3},
+				{Name: "단백질", Unit: "g", Amount: 30.1},
+				{Name: "비타민A", Unit: "R.E", Amount: 150.2},
+				{Name: "칼슘", Unit: "mg", Amount: 300},
+			},
+		},
+		{
+			name: "malformed lines are skipped",
+			text: "탄수화물 120.3<br/>단백질(g) : <br/>칼슘(mg) : .<br/>(g) : 1<br/>지방(g) : 12.0",
+			want: []Nutrient{{Name: "지방", Unit: "g", Amount: 12}},
+		},
+		{name: "empty", text: "", want: []Nutrient{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ParseNutrients(test.text)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseNutrients() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseOrigins(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Origin
+	}{
+		{
+			name: "recorded",
+			text: "쌀 : 국내산<br/>쇠고기 : 국내산(한우)<br/>빵 : 밀(미국산, 호주산)",
+			want: []Origin{
+				{Ingredient: "쌀", Origin: "국내산"},
+				{Ingredient: "쇠고기", Origin: "국내산(한우)"},
+				{Ingredient: "빵", Origin: "밀(미국산, 호주산)"},
+			},
+		},
+		{
+			// 원산지에 콜론이 있으면 첫 콜론에서만 나눔
+			name: "colon in origin",
+			text: "비고 : 가공품 : 수입산",
+			want: []Origin{{Ingredient: "비고", Origin: "가공품 : 수입산"}},
+		},
+		{
+			name: "malformed lines are skipped",
+			text: "원산지 표시 없음<br/>고춧가루 : <br/> : 국내산",
+			want: []Origin{},
+		},
+		{name: "empty", text: "", want: []Origin{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ParseOrigins(test.text)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseOrigins() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseCalories(t *testing.T) {
+	tests := []struct {
+		text string
+		want float64
+		ok   bool
+	}{
+		{"812.5 Kcal", 812.5, true},
+		{"520 Kcal", 520, true},
+		{"0 Kcal", 0, true},
+		{"Kcal", 0, false},
+		{"", 0, false},
+		{". Kcal", 0, false},
+		{"1.2.3 Kcal", 0, false},
+	}
+	for _, test := range tests {
+		got := ParseCalories(test.text)
+		if (got != nil) != test.ok {
+			t.Errorf("ParseCalories(%q) = %v, want ok = %v", test.text, got, test.ok)
+			continue
+		}
+		if got != nil && *got != test.want {
+			t.Errorf("ParseCalories(%q) = %v, want %v", test.text, *got, test.want)
+		}
+	}
+}
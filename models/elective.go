@@ -0,0 +1,144 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// ElectiveMode is how students pick an option in an ElectiveGroup
+type ElectiveMode string
+
+const (
+	FirstComeElective ElectiveMode = "first_come" // 고르는 순간 자리가 정해지고, 정원이 차면 더 못 고름
+	RankedElective    ElectiveMode = "ranked"     // 원하는 순서를 적어 내고 마감 때 한번에 배정함
+)
+
+// ElectiveOption is one course of an elective group
+// 한 과목이 일주일에 여러 번 있을 수 있으므로 수업을 여러 개 묶음
+type ElectiveOption struct {
+	ID       DbId   `json:"id"`
+	Name     string `json:"name"`
+	Entries  []DbId `json:"entries"`
+	Capacity int    `json:"capacity"`
+	// Taken 자리가 정해진 학생 수, 선착순은 고른 학생 수이고 희망순은 배정이 끝난 뒤에 채워짐
+	Taken int `json:"taken"`
+}
+
+// ElectiveGroup bundles lessons that share slots, of which each student takes exactly one
+// 선생님이 OpensAt부터 ClosesAt까지 신청 기간을 열고, 마감되면 배정 결과가 학생 시간표에 들어감
+type ElectiveGroup struct {
+	ID          DbId `json:"id"`
+	SchoolId    `json:"school_id"`
+	Name        string           `json:"name"`
+	Grade       int              `json:"grade"`
+	Mode        ElectiveMode     `json:"mode"`
+	OpensAt     *time.Time       `json:"opens_at"`
+	ClosesAt    *time.Time       `json:"closes_at"`
+	AllocatedAt *time.Time       `json:"allocated_at"`
+	CreatedBy   uuid.UUID        `json:"created_by"`
+	Options     []ElectiveOption `json:"options"`
+	Version     int64            `json:"version"`
+}
+
+// IsOpen reports whether students can choose at now
+func (group *ElectiveGroup) IsOpen(now time.Time) bool {
+	return group.AllocatedAt == nil && group.OpensAt != nil && group.ClosesAt != nil &&
+		!now.Before(*group.OpensAt) && now.Before(*group.ClosesAt)
+}
+
+// Option returns the option with the given ID, or nil if the group has none
+func (group *ElectiveGroup) Option(id DbId) *ElectiveOption {
+	for i := range group.Options {
+		if group.Options[i].ID == id {
+			return &group.Options[i]
+		}
+	}
+	return nil
+}
+
+// ElectiveChoice is one option a student picked, Preference 1 being the most wanted
+// 선착순에서는 Preference가 항상 1임
+type ElectiveChoice struct {
+	AccountId  DbId      `json:"-"`
+	UserId     uuid.UUID `json:"student"`
+	OptionId   DbId      `json:"option_id"`
+	Preference int       `json:"preference"`
+	ChosenAt   time.Time `json:"chosen_at"`
+	Assigned   bool      `json:"assigned"`
+}
+
+// ElectiveAssignment is the option a student ends up taking
+type ElectiveAssignment struct {
+	AccountId DbId      `json:"-"`
+	UserId    uuid.UUID `json:"student"`
+	OptionId  DbId      `json:"option_id"`
+}
+
+// ElectiveAllocation is the outcome of closing an elective group
+type ElectiveAllocation struct {
+	Assigned   []ElectiveAssignment `json:"assigned"`
+	Unassigned []uuid.UUID          `json:"unassigned"`
+}
+
+// AllocateElectives decides which option each student takes
+// 선착순은 이미 자리가 정해진 선택을 그대로 쓰고, 희망순은 seed로 섞은 순서대로
+// 학생마다 정원이 남은 가장 앞 순위의 과목을 줌. seed가 같으면 결과도 같음
+func AllocateElectives(group *ElectiveGroup, choices []ElectiveChoice, seed int64) ElectiveAllocation {
+	allocation := ElectiveAllocation{Assigned: make([]ElectiveAssignment, 0), Unassigned: make([]uuid.UUID, 0)}
+
+	byStudent := make(map[DbId][]ElectiveChoice)
+	students := make([]DbId, 0)
+	for _, choice := range choices {
+		if _, ok := byStudent[choice.AccountId]; !ok {
+			students = append(students, choice.AccountId)
+		}
+		byStudent[choice.AccountId] = append(byStudent[choice.AccountId], choice)
+	}
+	// map 순서에 결과가 흔들리지 않도록 정렬한 뒤에 섞음
+	sort.Slice(students, func(i, j int) bool {
+		return students[i] < students[j]
+	})
+
+	if group.Mode == FirstComeElective {
+		for _, student := range students {
+			choice := byStudent[student][0]
+			if choice.Assigned {
+				allocation.Assigned = append(allocation.Assigned, ElectiveAssignment{AccountId: student, UserId: choice.UserId, OptionId: choice.OptionId})
+			} else {
+				allocation.Unassigned = append(allocation.Unassigned, choice.UserId)
+			}
+		}
+		return allocation
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	random.Shuffle(len(students), func(i, j int) {
+		students[i], students[j] = students[j], students[i]
+	})
+
+	remaining := make(map[DbId]int, len(group.Options))
+	for _, option := range group.Options {
+		remaining[option.ID] = option.Capacity
+	}
+	for _, student := range students {
+		ranked := byStudent[student]
+		sort.Slice(ranked, func(i, j int) bool {
+			return ranked[i].Preference < ranked[j].Preference
+		})
+		assigned := false
+		for _, choice := range ranked {
+			if remaining[choice.OptionId] > 0 {
+				remaining[choice.OptionId]--
+				allocation.Assigned = append(allocation.Assigned, ElectiveAssignment{AccountId: student, UserId: choice.UserId, OptionId: choice.OptionId})
+				assigned = true
+				break
+			}
+		}
+		if !assigned {
+			allocation.Unassigned = append(allocation.Unassigned, ranked[0].UserId)
+		}
+	}
+	return allocation
+}
@@ -0,0 +1,203 @@
+package models
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+const (
+	optionA DbId = 1
+	optionB DbId = 2
+)
+
+func studentUser(student DbId) uuid.UUID {
+	return uuid.UUID{15: byte(student)}
+}
+
+func ranked(student DbId, preference int, option DbId) ElectiveChoice {
+	return ElectiveChoice{AccountId: student, UserId: studentUser(student), OptionId: option, Preference: preference}
+}
+
+func electiveGroup(mode ElectiveMode, capacityA int, capacityB int) *ElectiveGroup {
+	return &ElectiveGroup{
+		ID:   7,
+		Mode: mode,
+		Options: []ElectiveOption{
+			{ID: optionA, Name: "물리학Ⅱ", Capacity: capacityA},
+			{ID: optionB, Name: "화학Ⅱ", Capacity: capacityB},
+		},
+	}
+}
+
+// takenBy 과목마다 배정된 학생을 정렬해서 모음
+func takenBy(allocation ElectiveAllocation) map[DbId][]uuid.UUID {
+	taken := make(map[DbId][]uuid.UUID)
+	for _, assignment := range allocation.Assigned {
+		taken[assignment.OptionId] = append(taken[assignment.OptionId], assignment.UserId)
+	}
+	for _, users := range taken {
+		sort.Slice(users, func(i, j int) bool {
+			return users[i].String() < users[j].String()
+		})
+	}
+	return taken
+}
+
+func TestAllocateElectives(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   *ElectiveGroup
+		choices []ElectiveChoice
+		// wantCount 과목마다 배정된 학생 수, 누가 들어가는지는 seed에 따라 다름
+		wantCount      map[DbId]int
+		wantUnassigned int
+		// wantTaken 순서와 상관없이 정해지는 경우에만 누가 어디에 들어가는지 확인함
+		wantTaken map[DbId][]uuid.UUID
+	}{
+		{
+			name:  "everyone gets their first choice",
+			group: electiveGroup(RankedElective, 2, 2),
+			choices: []ElectiveChoice{
+				ranked(1, 1, optionA), ranked(1, 2, optionB),
+				ranked(2, 1, optionB), ranked(2, 2, optionA),
+			},
+			wantCount: map[DbId]int{optionA: 1, optionB: 1},
+			wantTaken: map[DbId][]uuid.UUID{optionA: {studentUser(1)}, optionB: {studentUser(2)}},
+		},
+		{
+			// 정원이 찬 과목을 원한 학생은 다음 순위로 넘어감
+			name:  "capacity spills over to the next preference",
+			group: electiveGroup(RankedElective, 2, 5),
+			choices: []ElectiveChoice{
+				ranked(1, 1, optionA), ranked(1, 2, optionB),
+				ranked(2, 1, optionA), ranked(2, 2, optionB),
+				ranked(3, 1, optionA), ranked(3, 2, optionB),
+			},
+			wantCount: map[DbId]int{optionA: 2, optionB: 1},
+		},
+		{
+			name:  "full options leave students unassigned",
+			group: electiveGroup(RankedElective, 1, 1),
+			choices: []ElectiveChoice{
+				ranked(1, 1, optionA), ranked(1, 2, optionB),
+				ranked(2, 1, optionA), ranked(2, 2, optionB),
+				ranked(3, 1, optionB), ranked(3, 2, optionA),
+			},
+			wantCount:      map[DbId]int{optionA: 1, optionB: 1},
+			wantUnassigned: 1,
+		},
+		{
+			// 적어 내지 않은 과목에는 자리가 남아도 넣지 않음
+			name:  "unranked options are never assigned",
+			group: electiveGroup(RankedElective, 1, 10),
+			choices: []ElectiveChoice{
+				ranked(1, 1, optionA),
+				ranked(2, 1, optionA),
+			},
+			wantCount:      map[DbId]int{optionA: 1},
+			wantUnassigned: 1,
+		},
+		{
+			// 순위는 선택한 순서가 아니라 Preference로 정해짐
+			name:  "preferences are read in rank order",
+			group: electiveGroup(RankedElective, 1, 1),
+			choices: []ElectiveChoice{
+				ranked(1, 2, optionA), ranked(1, 1, optionB),
+			},
+			wantCount: map[DbId]int{optionB: 1},
+			wantTaken: map[DbId][]uuid.UUID{optionB: {studentUser(1)}},
+		},
+		{
+			name:      "no choices",
+			group:     electiveGroup(RankedElective, 1, 1),
+			choices:   nil,
+			wantCount: map[DbId]int{},
+		},
+		{
+			// 선착순은 고를 때 정해진 자리를 그대로 씀
+			name:  "first come keeps the seats already taken",
+			group: electiveGroup(FirstComeElective, 1, 1),
+			choices: []ElectiveChoice{
+				{AccountId: 1, UserId: studentUser(1), OptionId: optionA, Preference: 1, Assigned: true},
+				{AccountId: 2, UserId: studentUser(2), OptionId: optionA, Preference: 1, Assigned: false},
+				{AccountId: 3, UserId: studentUser(3), OptionId: optionB, Preference: 1, Assigned: true},
+			},
+			wantCount:      map[DbId]int{optionA: 1, optionB: 1},
+			wantUnassigned: 1,
+			wantTaken:      map[DbId][]uuid.UUID{optionA: {studentUser(1)}, optionB: {studentUser(3)}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := AllocateElectives(test.group, test.choices, int64(test.group.ID))
+
+			count := make(map[DbId]int)
+			seen := make(map[DbId]bool)
+			for _, assignment := range got.Assigned {
+				count[assignment.OptionId]++
+				if seen[assignment.AccountId] {
+					t.Errorf("student %d assigned twice", assignment.AccountId)
+				}
+				seen[assignment.AccountId] = true
+			}
+			if !reflect.DeepEqual(count, test.wantCount) {
+				t.Errorf("assigned per option = %v, want %v", count, test.wantCount)
+			}
+			if len(got.Unassigned) != test.wantUnassigned {
+				t.Errorf("unassigned = %v, want %d students", got.Unassigned, test.wantUnassigned)
+			}
+			if test.wantTaken != nil && !reflect.DeepEqual(takenBy(got), test.wantTaken) {
+				t.Errorf("taken = %v, want %v", takenBy(got), test.wantTaken)
+			}
+		})
+	}
+}
+
+func TestAllocateElectivesTies(t *testing.T) {
+	// 같은 과목을 같은 순위로 원하면 seed로 섞은 순서가 정함
+	// 학생 번호가 작은 쪽이 항상 이기면 안 됨
+	group := electiveGroup(RankedElective, 1, 0)
+	choices := []ElectiveChoice{ranked(1, 1, optionA), ranked(2, 1, optionA)}
+
+	winners := make(map[uuid.UUID]bool)
+	for seed := int64(1); seed <= 50; seed++ {
+		got := AllocateElectives(group, choices, seed)
+		if len(got.Assigned) != 1 || len(got.Unassigned) != 1 {
+			t.Fatalf("seed %d: %+v", seed, got)
+		}
+		winners[got.Assigned[0].UserId] = true
+	}
+	if len(winners) != 2 {
+		t.Errorf("the same student won every seed: %v", winners)
+	}
+}
+
+func TestAllocateElectivesIsRepeatable(t *testing.T) {
+	group := electiveGroup(RankedElective, 2, 2)
+	choices := []ElectiveChoice{
+		ranked(1, 1, optionA), ranked(1, 2, optionB),
+		ranked(2, 1, optionA), ranked(2, 2, optionB),
+		ranked(3, 1, optionA), ranked(3, 2, optionB),
+		ranked(4, 1, optionA), ranked(4, 2, optionB),
+		ranked(5, 1, optionA),
+	}
+	first := AllocateElectives(group, choices, int64(group.ID))
+
+	// 배정을 다시 돌려도, DB가 신청을 다른 순서로 돌려줘도 결과가 같아야 함
+	reversed := make([]ElectiveChoice, len(choices))
+	for i := range choices {
+		reversed[len(choices)-1-i] = choices[i]
+	}
+	for _, again := range [][]ElectiveChoice{choices, reversed} {
+		got := AllocateElectives(group, again, int64(group.ID))
+		if !reflect.DeepEqual(got, first) {
+			t.Errorf("AllocateElectives() =\n%+v\nfirst run\n%+v", got, first)
+		}
+	}
+	if len(first.Assigned) != 4 || len(first.Unassigned) != 1 {
+		t.Errorf("AllocateElectives() = %+v, want 4 assigned and 1 unassigned", first)
+	}
+}
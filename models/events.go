@@ -1,10 +1,17 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
 type Events struct {
-	ID       DbId `json:"id"`
-	SchoolId `json:"school_id"`
-	Month    int          `json:"month"`
-	Events   []EventEntry `json:"events"`
+	ID        DbId `json:"id"`
+	SchoolId  `json:"school_id"`
+	Month     int          `json:"month"`
+	Events    []EventEntry `json:"events"`
+	Version   int64        `json:"version"`
+	DeletedAt *time.Time   `json:"deleted_at,omitempty"`
 }
 
 type EventEntry struct {
@@ -18,6 +25,34 @@ type EventEntry struct {
 	ModifiedDate       string         `json:"modified_date"`
 }
 
+// 이 날에는 수업을 하지 않음
+var noClassDateKinds = []string{"휴업일", "공휴일"}
+
+// IsEventOn reports whether the event falls on date
+// NEIS는 날짜를 YYYYMMDD로 주기 때문에 YYYY-MM-DD와 둘 다 받아들임
+func (entry EventEntry) IsEventOn(date time.Time) bool {
+	return entry.Date == date.Format("2006-01-02") || entry.Date == date.Format("20060102")
+}
+
+// ParseDate parses the event's date, written as YYYY-MM-DD or YYYYMMDD, in the given location
+func (entry EventEntry) ParseDate(loc *time.Location) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", entry.Date, loc)
+	if err != nil {
+		return time.ParseInLocation("20060102", entry.Date, loc)
+	}
+	return date, nil
+}
+
+// CancelsClasses reports whether there are no classes on the event's date
+func (entry EventEntry) CancelsClasses() bool {
+	for _, kind := range noClassDateKinds {
+		if strings.TrimSpace(entry.DateKind) == kind {
+			return true
+		}
+	}
+	return false
+}
+
 type AttendanceType int8
 
 // Neis API는 특정 행사 참여 여부를 Y, *, N으로 구분하는데, Y는 참여, *은 해당 학년 없음, N은 참여 안함
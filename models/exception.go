@@ -0,0 +1,105 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"sort"
+	"time"
+)
+
+// ExceptionKind is how a TimetableException changes a lesson on its date
+type ExceptionKind string
+
+const (
+	CancelException     ExceptionKind = "cancel"      // 수업이 없음
+	MoveException       ExceptionKind = "move"        // 같은 날 다른 교시로 옮김, 두 교시를 바꾸려면 두 수업 모두 옮김
+	SubstituteException ExceptionKind = "substitute"  // 다른 선생님이 대신 들어감, 교실도 바뀔 수 있음
+	RoomChangeException ExceptionKind = "room_change" // 교실만 바뀜
+)
+
+// TimetableException changes one lesson on one date without touching the weekly timetable
+// Kind에 따라 Period(move), TeacherId(substitute), Location(move, substitute, room_change)이 쓰임
+type TimetableException struct {
+	ID        DbId `json:"id"`
+	SchoolId  `json:"school_id"`
+	EntryId   DbId          `json:"entry_id"`
+	Date      string        `json:"date"`
+	Kind      ExceptionKind `json:"kind"`
+	Period    *Period       `json:"period,omitempty"`
+	TeacherId *uuid.UUID    `json:"teacher,omitempty"`
+	Location  string        `json:"location,omitempty"`
+	Note      string        `json:"note"`
+	CreatedBy uuid.UUID     `json:"created_by"`
+}
+
+// ParseDate parses the exception's YYYY-MM-DD date in the given location
+func (exception TimetableException) ParseDate(loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", exception.Date, loc)
+}
+
+// Apply returns entry as it takes place on the exception's date, or false if it is cancelled
+func (exception TimetableException) Apply(entry TimetableEntry) (TimetableEntry, bool) {
+	if exception.Kind == CancelException {
+		return entry, false
+	}
+	if exception.Kind == MoveException && exception.Period != nil {
+		entry.Period = *exception.Period
+	}
+	if exception.Kind == SubstituteException && exception.TeacherId != nil {
+		entry.TeacherId = *exception.TeacherId
+	}
+	if exception.Location != "" {
+		entry.Location = exception.Location
+	}
+	applied := exception
+	entry.Exception = &applied
+	return entry, true
+}
+
+// ApplyExceptions returns the lessons that take place on date ordered by period, and the ones cancelled that day
+// entries는 주간 시간표이고, 요일이 date와 다른 수업은 빠짐
+func ApplyExceptions(date time.Time, entries []TimetableEntry, exceptions []TimetableException) ([]TimetableEntry, []TimetableEntry) {
+	day := WeekdayOf(date)
+	key := date.Format("2006-01-02")
+	byEntry := make(map[DbId]TimetableException)
+	for _, exception := range exceptions {
+		if exception.Date == key {
+			byEntry[exception.EntryId] = exception
+		}
+	}
+
+	effective := make([]TimetableEntry, 0)
+	cancelled := make([]TimetableEntry, 0)
+	for _, entry := range entries {
+		if entry.Day != day {
+			continue
+		}
+		exception, ok := byEntry[entry.ID]
+		if !ok {
+			effective = append(effective, entry)
+			continue
+		}
+		if applied, held := exception.Apply(entry); held {
+			effective = append(effective, applied)
+		} else {
+			entry.Exception = &exception
+			cancelled = append(cancelled, entry)
+		}
+	}
+	// 다른 교시로 옮겨진 수업이 있으므로 다시 정렬함
+	sort.SliceStable(effective, func(i, j int) bool {
+		return effective[i].Period < effective[j].Period
+	})
+	return effective, cancelled
+}
+
+// ApplyWeekExceptions is ApplyExceptions for every school day of the week starting on monday
+func ApplyWeekExceptions(monday time.Time, entries []TimetableEntry, exceptions []TimetableException) ([]TimetableEntry, []TimetableEntry) {
+	effective := make([]TimetableEntry, 0, len(entries))
+	cancelled := make([]TimetableEntry, 0)
+	for i := range SchoolDays {
+		held, dropped := ApplyExceptions(monday.AddDate(0, 0, i), entries, exceptions)
+		effective = append(effective, held...)
+		cancelled = append(cancelled, dropped...)
+	}
+	return effective, cancelled
+}
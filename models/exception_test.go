@@ -0,0 +1,181 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 2024-03-04는 월요일
+var exceptionDate = time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)
+
+func cancelled(id DbId) TimetableException {
+	return TimetableException{EntryId: id, Date: "2024-03-04", Kind: CancelException}
+}
+
+func moved(id DbId, period Period) TimetableException {
+	return TimetableException{EntryId: id, Date: "2024-03-04", Kind: MoveException, Period: &period}
+}
+
+func substituted(id DbId, teacher uuid.UUID, location string) TimetableException {
+	return TimetableException{EntryId: id, Date: "2024-03-04", Kind: SubstituteException, TeacherId: &teacher, Location: location}
+}
+
+// changed entry를 exception대로 바꾸고 Exception을 채움
+func changed(entry TimetableEntry, exception TimetableException) TimetableEntry {
+	applied, _ := exception.Apply(entry)
+	return applied
+}
+
+func withException(entry TimetableEntry, exception TimetableException) TimetableEntry {
+	entry.Exception = &exception
+	return entry
+}
+
+func TestApplyExceptions(t *testing.T) {
+	first := entry(1, Monday, 1, teacherKim, "1-3")
+	second := entry(2, Monday, 2, teacherLee, "1-3")
+	third := entry(3, Monday, 3, teacherKim, "과학실")
+	tuesday := entry(4, Tuesday, 1, teacherKim, "1-3")
+	week := []TimetableEntry{third, second, first, tuesday}
+
+	tests := []struct {
+		name          string
+		exceptions    []TimetableException
+		wantEffective []TimetableEntry
+		wantCancelled []TimetableEntry
+	}{
+		{
+			name:          "no exceptions keeps the day in period order",
+			exceptions:    nil,
+			wantEffective: []TimetableEntry{first, second, third},
+			wantCancelled: []TimetableEntry{},
+		},
+		{
+			name:          "cancelled",
+			exceptions:    []TimetableException{cancelled(2)},
+			wantEffective: []TimetableEntry{first, third},
+			wantCancelled: []TimetableEntry{withException(second, cancelled(2))},
+		},
+		{
+			// 대신 들어가는 선생님이 다른 교실을 쓸 수도 있음
+			name:          "substitute",
+			exceptions:    []TimetableException{substituted(2, teacherKim, "어학실")},
+			wantEffective: []TimetableEntry{first, changed(second, substituted(2, teacherKim, "어학실")), third},
+			wantCancelled: []TimetableEntry{},
+		},
+		{
+			name:          "moved lessons are sorted by their new period",
+			exceptions:    []TimetableException{moved(1, 4)},
+			wantEffective: []TimetableEntry{second, third, changed(first, moved(1, 4))},
+			wantCancelled: []TimetableEntry{},
+		},
+		{
+			name: "other dates and days are ignored",
+			exceptions: []TimetableException{
+				{EntryId: 2, Date: "2024-03-11", Kind: CancelException},
+				{EntryId: 4, Date: "2024-03-04", Kind: CancelException},
+			},
+			wantEffective: []TimetableEntry{first, second, third},
+			wantCancelled: []TimetableEntry{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			effective, cancelled := ApplyExceptions(exceptionDate, week, test.exceptions)
+			if !reflect.DeepEqual(effective, test.wantEffective) {
+				t.Errorf("effective =\n%+v\nwant\n%+v", effective, test.wantEffective)
+			}
+			if !reflect.DeepEqual(cancelled, test.wantCancelled) {
+				t.Errorf("cancelled =\n%+v\nwant\n%+v", cancelled, test.wantCancelled)
+			}
+		})
+	}
+}
+
+func TestConflictsOn(t *testing.T) {
+	lesson := entry(10, Monday, 2, teacherKim, "과학실")
+	sameTeacher := entry(1, Monday, 2, teacherKim, "1-3")
+	sameRoom := entry(2, Monday, 2, teacherLee, "과학실")
+	earlier := entry(3, Monday, 1, teacherKim, "음악실")
+	elsewhere := entry(4, Monday, 2, teacherLee, "1-5")
+
+	// GetSlotConflicts가 주간 시간표에서 찾은 충돌
+	teacherClash := TimetableConflict{Kind: TeacherConflict, Day: Monday, Period: 2, TeacherId: &teacherKim, Entries: []TimetableEntry{sameTeacher, lesson}}
+	roomClash := TimetableConflict{Kind: LocationConflict, Day: Monday, Period: 2, Location: "과학실", Entries: []TimetableEntry{sameRoom, lesson}}
+
+	tests := []struct {
+		name       string
+		template   []TimetableConflict
+		others     []TimetableEntry
+		exceptions []TimetableException
+		want       []TimetableConflict
+	}{
+		{
+			name:     "weekly clashes still happen without exceptions",
+			template: []TimetableConflict{teacherClash, roomClash},
+			want:     []TimetableConflict{teacherClash, roomClash},
+		},
+		{
+			name:       "cancelled lesson no longer clashes",
+			template:   []TimetableConflict{teacherClash, roomClash},
+			exceptions: []TimetableException{cancelled(1)},
+			want:       []TimetableConflict{roomClash},
+		},
+		{
+			name:       "moved away lesson no longer clashes",
+			template:   []TimetableConflict{roomClash},
+			exceptions: []TimetableException{moved(2, 5)},
+			want:       []TimetableConflict{},
+		},
+		{
+			name:       "substitute teacher frees the teacher",
+			template:   []TimetableConflict{teacherClash},
+			exceptions: []TimetableException{substituted(1, teacherLee, "")},
+			want:       []TimetableConflict{},
+		},
+		{
+			name:       "lesson moved into the period clashes",
+			others:     []TimetableEntry{earlier},
+			exceptions: []TimetableException{moved(3, 2)},
+			want: []TimetableConflict{{
+				Kind: TeacherConflict, Day: Monday, Period: 2, TeacherId: &teacherKim,
+				Entries: []TimetableEntry{changed(earlier, moved(3, 2)), lesson},
+			}},
+		},
+		{
+			// 대신 들어간 선생님이 그 시간에 이미 수업이 있고, 옮긴 교실도 겹침
+			name:       "substitute into the lesson's teacher and room",
+			template:   []TimetableConflict{teacherClash},
+			others:     []TimetableEntry{elsewhere},
+			exceptions: []TimetableException{substituted(4, teacherKim, "과학실")},
+			want: []TimetableConflict{
+				{
+					Kind: TeacherConflict, Day: Monday, Period: 2, TeacherId: &teacherKim,
+					Entries: []TimetableEntry{sameTeacher, changed(elsewhere, substituted(4, teacherKim, "과학실")), lesson},
+				},
+				{
+					Kind: LocationConflict, Day: Monday, Period: 2, Location: "과학실",
+					Entries: []TimetableEntry{changed(elsewhere, substituted(4, teacherKim, "과학실")), lesson},
+				},
+			},
+		},
+		{
+			// 주간 시간표의 충돌에 이미 들어 있는 수업은 두 번 넣지 않음
+			name:     "weekly clash is not repeated from others",
+			template: []TimetableConflict{teacherClash},
+			others:   []TimetableEntry{sameTeacher},
+			want:     []TimetableConflict{teacherClash},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ConflictsOn(exceptionDate, lesson, test.template, test.others, test.exceptions)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ConflictsOn() =\n%+v\nwant\n%+v", got, test.want)
+			}
+		})
+	}
+}
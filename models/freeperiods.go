@@ -0,0 +1,81 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// FreeSlot is a period in which every member of a group has no lesson
+// 종 시간표가 없는 날은 Start, End가 null로 내려감
+type FreeSlot struct {
+	Date   string     `json:"date"`
+	Day    Weekday    `json:"day"`
+	Period Period     `json:"period"`
+	Start  *ClockTime `json:"start"`
+	End    *ClockTime `json:"end"`
+}
+
+// SkippedDay is a school day left out of a free period search
+type SkippedDay struct {
+	Date   string `json:"date"`
+	Reason string `json:"reason"`
+}
+
+// FindFreeSlots returns the periods from Monday to Friday of monday's week in which none of busy takes place
+// bells의 교시만 확인하고, 종 시간표가 없는 요일은 1교시부터 DefaultPeriods교시까지 확인함
+// events에서 휴업일, 공휴일인 날은 건너뜀
+func FindFreeSlots(monday time.Time, bells map[Weekday]*BellSchedule, busy []TimetableEntry, events []EventEntry) ([]FreeSlot, []SkippedDay) {
+	taken := make(map[Weekday]map[Period]bool, len(SchoolDays))
+	for _, entry := range busy {
+		if taken[entry.Day] == nil {
+			taken[entry.Day] = make(map[Period]bool)
+		}
+		taken[entry.Day][entry.Period] = true
+	}
+
+	slots := make([]FreeSlot, 0)
+	skipped := make([]SkippedDay, 0)
+	for i, day := range SchoolDays {
+		date := monday.AddDate(0, 0, i)
+		if reason, ok := noClassesOn(date, events); ok {
+			skipped = append(skipped, SkippedDay{Date: date.Format("2006-01-02"), Reason: reason})
+			continue
+		}
+
+		periods := make([]BellPeriod, 0)
+		if schedule := bells[day]; schedule != nil {
+			periods = schedule.Periods
+		}
+		for _, bell := range periods {
+			if taken[day][bell.Period] {
+				continue
+			}
+			bell := bell
+			slots = append(slots, FreeSlot{Date: date.Format("2006-01-02"), Day: day, Period: bell.Period, Start: &bell.Start, End: &bell.End})
+		}
+		if len(periods) == 0 {
+			for p := Period(1); p <= DefaultPeriods; p++ {
+				if !taken[day][p] {
+					slots = append(slots, FreeSlot{Date: date.Format("2006-01-02"), Day: day, Period: p})
+				}
+			}
+		}
+	}
+	sort.SliceStable(slots, func(i, j int) bool {
+		if slots[i].Date != slots[j].Date {
+			return slots[i].Date < slots[j].Date
+		}
+		return slots[i].Period < slots[j].Period
+	})
+	return slots, skipped
+}
+
+// noClassesOn 그날 수업이 없게 만드는 학사일정이 있으면 그 이름을 반환함
+func noClassesOn(date time.Time, events []EventEntry) (string, bool) {
+	for _, event := range events {
+		if event.IsEventOn(date) && event.CancelsClasses() {
+			return event.EventName, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden got을 testdata/name과 비교함, -update로 실행하면 golden 파일을 새로 씀
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := "testdata/" + name
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file:\n%s\nwant\n%s", name, got, want)
+	}
+}
+
+func clock(t *testing.T, s string) ClockTime {
+	t.Helper()
+	parsed, err := ParseClockTime(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestFindFreeSlots(t *testing.T) {
+	// 기본 시간표는 4교시까지, 수요일은 3교시까지 단축 수업
+	regular := &BellSchedule{Name: "기본", IsDefault: true, Periods: []BellPeriod{
+		{Period: 1, Start: clock(t, "08:40"), End: clock(t, "09:30")},
+		{Period: 2, Start: clock(t, "09:40"), End: clock(t, "10:30")},
+		{Period: 3, Start: clock(t, "10:40"), End: clock(t, "11:30")},
+		{Period: 4, Start: clock(t, "11:40"), End: clock(t, "12:30")},
+	}}
+	short := &BellSchedule{Name: "단축", Weekdays: []Weekday{Wednesday}, Periods: []BellPeriod{
+		{Period: 1, Start: clock(t, "08:40"), End: clock(t, "09:20")},
+		{Period: 2, Start: clock(t, "09:30"), End: clock(t, "10:10")},
+		{Period: 3, Start: clock(t, "10:20"), End: clock(t, "11:00")},
+	}}
+	// 금요일은 종 시간표가 없어서 1교시부터 DefaultPeriods교시까지 시각 없이 확인함
+	bells := map[Weekday]*BellSchedule{Monday: regular, Tuesday: regular, Wednesday: short, Thursday: regular}
+
+	// 두 친구의 수업을 합친 것, 같은 교시에 둘 다 수업이 있어도 한번만 빠짐
+	busy := []TimetableEntry{
+		entry(1, Monday, 1, teacherKim, "1-3"),
+		entry(2, Monday, 3, teacherKim, "1-3"),
+		entry(3, Monday, 3, teacherLee, "1-5"),
+		entry(4, Tuesday, 2, teacherLee, "1-5"),
+		entry(5, Wednesday, 3, teacherKim, "1-3"),
+		// 종 시간표에 없는 교시의 수업은 빈 시간에 영향이 없음
+		entry(6, Wednesday, 5, teacherKim, "1-3"),
+		entry(7, Thursday, 1, teacherKim, "1-3"),
+		entry(8, Friday, 6, teacherLee, "1-5"),
+	}
+	events := []EventEntry{
+		{Date: "20240307", DateKind: "공휴일", EventName: "개교기념일"},
+		{Date: "20240305", DateKind: "해당 없음", EventName: "학부모 상담"},
+	}
+
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)
+	slots, skipped := FindFreeSlots(monday, bells, busy, events)
+
+	got, err := json.MarshalIndent(struct {
+		Slots   []FreeSlot   `json:"slots"`
+		Skipped []SkippedDay `json:"skipped"`
+	}{slots, skipped}, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "free_slots.json", append(got, '\n'))
+}
+
+func TestFindFreeSlotsWhenEveryoneIsBusy(t *testing.T) {
+	busy := make([]TimetableEntry, 0)
+	for _, day := range SchoolDays {
+		for p := Period(1); p <= DefaultPeriods; p++ {
+			busy = append(busy, entry(0, day, p, teacherKim, ""))
+		}
+	}
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)
+	slots, skipped := FindFreeSlots(monday, nil, busy, nil)
+	if len(slots) != 0 || len(skipped) != 0 {
+		t.Errorf("FindFreeSlots() = %+v, %+v, want nothing", slots, skipped)
+	}
+}
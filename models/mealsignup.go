@@ -0,0 +1,77 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// MealSignupPolicy is when a school stops taking sign-ups for a meal
+// 급식 날짜의 DaysBefore일 전 Cutoff 시각까지 신청하거나 취소할 수 있음
+type MealSignupPolicy struct {
+	SchoolId   `json:"school_id"`
+	DaysBefore int       `json:"days_before"`
+	Cutoff     ClockTime `json:"cutoff"`
+}
+
+// DefaultMealSignupPolicy 정책을 정하지 않은 학교는 전날 18시까지 받음
+func DefaultMealSignupPolicy(schoolId SchoolId) MealSignupPolicy {
+	return MealSignupPolicy{SchoolId: schoolId, DaysBefore: 1, Cutoff: 18 * 60}
+}
+
+// Deadline returns the last moment to sign up for a meal served on date
+func (policy MealSignupPolicy) Deadline(date time.Time) time.Time {
+	return policy.Cutoff.On(date.AddDate(0, 0, -policy.DaysBefore))
+}
+
+// MealSignup is a student's answer to whether they will eat a meal, and whether they did
+// Attending이 nil이면 신청하지 않고 와서 먹은 학생임
+type MealSignup struct {
+	MenuId      DbId       `json:"menu_id"`
+	UserId      uuid.UUID  `json:"user_id"`
+	Name        string     `json:"name"`
+	Grade       int        `json:"grade"`
+	Class       int        `json:"class"`
+	Number      int        `json:"number"`
+	Attending   *bool      `json:"attending"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CheckedInAt *time.Time `json:"checked_in_at"`
+}
+
+// MealHeadcount is the kitchen's report for one meal
+type MealHeadcount struct {
+	MenuId    DbId   `json:"menu_id"`
+	Date      string `json:"date"`
+	MealName  string `json:"meal_name"`
+	Attending int    `json:"attending"`
+	OptedOut  int    `json:"opted_out"`
+	CheckedIn int    `json:"checked_in"`
+	// 신청하지 않았거나 취소했는데 먹은 학생
+	WalkIns int `json:"walk_ins"`
+	// 신청했는데 아직 먹지 않은 학생
+	NoShows int `json:"no_shows"`
+}
+
+// CountHeadcount counts the sign-ups and check-ins of a meal
+func CountHeadcount(menu CafeteriaMenu, signups []MealSignup) MealHeadcount {
+	headcount := MealHeadcount{MenuId: menu.ID, Date: menu.Date, MealName: menu.MealName}
+	for _, signup := range signups {
+		attending := signup.Attending != nil && *signup.Attending
+		checkedIn := signup.CheckedInAt != nil
+		switch {
+		case attending:
+			headcount.Attending++
+		case signup.Attending != nil:
+			headcount.OptedOut++
+		}
+		if checkedIn {
+			headcount.CheckedIn++
+		}
+		if checkedIn && !attending {
+			headcount.WalkIns++
+		}
+		if attending && !checkedIn {
+			headcount.NoShows++
+		}
+	}
+	return headcount
+}
@@ -1,18 +1,79 @@
 package models
 
-import "github.com/vishalkuo/bimap"
+import (
+	"github.com/vishalkuo/bimap"
+	"sort"
+	"time"
+)
 
 // CafeteriaMenu struct represents a cafeteria menu
+// items는 예전 클라이언트를 위해 Dishes를 한 줄에 하나씩 펼친 문자열임
 type CafeteriaMenu struct {
-	ID       DbId `json:"id"`
-	SchoolId `json:"school_id"`
-	MealName string `json:"meal_name"`
-	Date     string `json:"date"`
-	Contents string `json:"items"`
+	ID        DbId `json:"id"`
+	SchoolId  `json:"school_id"`
+	MealName  string     `json:"meal_name"`
+	Date      string     `json:"date"`
+	Contents  string     `json:"items"`
+	Dishes    []Dish     `json:"dishes"`
+	Calories  *float64   `json:"calories"`
+	Nutrients []Nutrient `json:"nutrients"`
+	Origins   []Origin   `json:"origins"`
+	// 요청한 학생의 알레르기 정보와 겹치는 요리, 응답할 때만 채우고 저장하지 않음
+	Warnings []DishWarning `json:"warnings,omitempty"`
+	// 급식 사진, 응답할 때만 채우고 저장하지 않음
+	Photos    []MenuPhoto `json:"photos,omitempty"`
+	Version   int64       `json:"version"`
+	DeletedAt *time.Time  `json:"deleted_at,omitempty"`
 }
 
+// MenuDay is every meal served on one date
+type MenuDay struct {
+	Date  string          `json:"date"`
+	Meals []CafeteriaMenu `json:"meals"`
+}
+
+// mealOrder 아침, 점심, 저녁 순서로 보여주기 위한 순서, 모르는 식사는 맨 뒤로 감
+var mealOrder = map[string]int{"조식": 1, "중식": 2, "석식": 3}
+
+func mealRank(mealName string) int {
+	if rank, ok := mealOrder[mealName]; ok {
+		return rank
+	}
+	return len(mealOrder) + 1
+}
+
+// SortMenus sorts menus by date, then breakfast, lunch and dinner
+func SortMenus(menus []CafeteriaMenu) {
+	sort.SliceStable(menus, func(i, j int) bool {
+		if menus[i].Date != menus[j].Date {
+			return menus[i].Date < menus[j].Date
+		}
+		return mealRank(menus[i].MealName) < mealRank(menus[j].MealName)
+	})
+}
+
+// GroupMenusByDate returns one day for every date from from to to, including days without meals
+// menus는 SortMenus로 정렬되어 있어야 함
+func GroupMenusByDate(from time.Time, to time.Time, menus []CafeteriaMenu) []MenuDay {
+	days := make([]MenuDay, 0)
+	next := 0
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		day := MenuDay{Date: date.Format("2006-01-02"), Meals: make([]CafeteriaMenu, 0)}
+		for next < len(menus) && menus[next].Date <= day.Date {
+			if menus[next].Date == day.Date {
+				day.Meals = append(day.Meals, menus[next])
+			}
+			next++
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// AllergyType is one of the 18 allergen numbers printed after dish names on Korean school menus
 type AllergyType int8
 
+// Allergies maps allergen names to their standard numbers
 var Allergies = bimap.NewBiMap[string, int8]()
 
 func InitAllergies() {
@@ -25,7 +86,7 @@ func InitAllergies() {
 	Allergies.Insert("고등어", 7)
 	Allergies.Insert("게", 8)
 	Allergies.Insert("새우", 9)
-	Allergies.Insert("돼지고", 10)
+	Allergies.Insert("돼지고기", 10)
 	Allergies.Insert("복숭아", 11)
 	Allergies.Insert("토마토", 12)
 	Allergies.Insert("아황산", 13)
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// NowStatus describes where in the school day a TimetableNow was taken
+type NowStatus string
+
+const (
+	NowNoClasses    NowStatus = "no_classes"    // 휴업일, 공휴일이거나 오늘 수업이 없음
+	NowNoSchedule   NowStatus = "no_schedule"   // 학교에 종 시간표가 없어서 교시를 알 수 없음
+	NowBeforeSchool NowStatus = "before_school" // 첫 교시 전
+	NowInClass      NowStatus = "in_class"
+	NowBreak        NowStatus = "break" // 쉬는 시간, 점심 시간
+	NowAfterSchool  NowStatus = "after_school"
+)
+
+// NowSlot is a period of today with the lesson the student takes then
+// 공강이면 Lesson이 null로 내려감
+type NowSlot struct {
+	Period Period      `json:"period"`
+	Start  ClockTime   `json:"start"`
+	End    ClockTime   `json:"end"`
+	Lesson *WeekLesson `json:"lesson"`
+}
+
+// TimetableNow is a student's current and next period
+type TimetableNow struct {
+	Date     string       `json:"date"`
+	Time     ClockTime    `json:"time"`
+	Status   NowStatus    `json:"status"`
+	Reason   string       `json:"reason,omitempty"`
+	Current  *NowSlot     `json:"current"`
+	Next     *NowSlot     `json:"next"`
+	StartsIn *int64       `json:"startsInSeconds"`
+	Events   []EventEntry `json:"events"`
+}
+
+// ResolveNow works out the current and next period at now
+// schedule은 오늘의 종 시간표이고 없으면 nil, events는 이번 달 학사일정임
+func ResolveNow(now time.Time, schedule *BellSchedule, entries []TimetableEntry, teacherNames map[uuid.UUID]string, events []EventEntry) TimetableNow {
+	result := TimetableNow{
+		Date:   now.Format("2006-01-02"),
+		Time:   ClockTime(now.Hour()*60 + now.Minute()),
+		Events: make([]EventEntry, 0),
+	}
+
+	for _, event := range events {
+		if !event.IsEventOn(now) {
+			continue
+		}
+		result.Events = append(result.Events, event)
+		if event.CancelsClasses() && result.Status == "" {
+			result.Status = NowNoClasses
+			result.Reason = event.EventName
+		}
+	}
+	if result.Status != "" {
+		return result
+	}
+
+	today := WeekdayOf(now)
+	lessons := make(map[Period]*WeekLesson)
+	for _, entry := range entries {
+		if entry.Day != today || lessons[entry.Period] != nil {
+			continue
+		}
+		lessons[entry.Period] = NewWeekLesson(entry, teacherNames)
+	}
+	if len(lessons) == 0 {
+		result.Status = NowNoClasses
+		return result
+	}
+	if schedule == nil || len(schedule.Periods) == 0 {
+		result.Status = NowNoSchedule
+		return result
+	}
+
+	// Periods가 정렬되어 있지 않을 수 있으므로 시각으로 지금 교시와 다음 교시를 찾음
+	seconds := now.Sub(ClockTime(0).On(now))
+	var last ClockTime
+	for _, bell := range schedule.Periods {
+		slot := &NowSlot{Period: bell.Period, Start: bell.Start, End: bell.End, Lesson: lessons[bell.Period]}
+		start := time.Duration(bell.Start) * time.Minute
+		end := time.Duration(bell.End) * time.Minute
+		if start <= seconds && seconds < end {
+			result.Current = slot
+		} else if seconds < start && (result.Next == nil || bell.Start < result.Next.Start) {
+			result.Next = slot
+		}
+		if bell.End > last {
+			last = bell.End
+		}
+	}
+
+	if result.Next != nil {
+		startsIn := int64((time.Duration(result.Next.Start)*time.Minute - seconds) / time.Second)
+		result.StartsIn = &startsIn
+	}
+
+	switch {
+	case result.Current != nil:
+		result.Status = NowInClass
+	case time.Duration(last)*time.Minute <= seconds:
+		result.Status = NowAfterSchool
+	case result.Next != nil && result.Next.Start == firstStart(schedule):
+		result.Status = NowBeforeSchool
+	default:
+		result.Status = NowBreak
+	}
+	return result
+}
+
+func firstStart(schedule *BellSchedule) ClockTime {
+	first := schedule.Periods[0].Start
+	for _, bell := range schedule.Periods {
+		if bell.Start < first {
+			first = bell.Start
+		}
+	}
+	return first
+}
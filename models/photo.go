@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+// MenuPhoto is a picture of a served meal uploaded by a student or teacher
+// 숨긴 사진은 선생님과 관리자에게만 보임
+type MenuPhoto struct {
+	ID           DbId `json:"id"`
+	MenuId       DbId `json:"menu_id"`
+	SchoolId     `json:"school_id"`
+	Position     int        `json:"position"`
+	ContentType  string     `json:"content_type"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	Hidden       bool       `json:"hidden"`
+	UploadedBy   *uuid.UUID `json:"uploaded_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	URL          string     `json:"url"`
+	ThumbnailURL string     `json:"thumbnail_url"`
+}
+
+// SetURLs fills the addresses the photo and its thumbnail are served from
+func (photo *MenuPhoto) SetURLs() {
+	photo.URL = fmt.Sprintf("/cafeteria_menus/photos/%d", photo.ID)
+	photo.ThumbnailURL = fmt.Sprintf("/cafeteria_menus/photos/%d/thumbnail", photo.ID)
+}
+
+// Extension returns the file extension the photo is stored with
+func (photo *MenuPhoto) Extension() string {
+	if photo.ContentType == "image/png" {
+		return ".png"
+	}
+	return ".jpg"
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"sort"
+	"time"
+)
+
+// CommentStatus is where a rating's comment is in moderation
+type CommentStatus string
+
+const (
+	// CommentNone 코멘트 없이 점수만 남긴 평가
+	CommentNone     CommentStatus = "none"
+	CommentPending  CommentStatus = "pending"
+	CommentApproved CommentStatus = "approved"
+	CommentRejected CommentStatus = "rejected"
+)
+
+// MealRating is a student's score for a meal or for one dish of it
+// Dish가 비어 있으면 급식 전체에 대한 평가이고, 학생은 급식마다 전체와 요리별로 한번씩 평가할 수 있음
+type MealRating struct {
+	ID       DbId `json:"id"`
+	MenuId   DbId `json:"menu_id"`
+	SchoolId `json:"school_id"`
+	// 다른 학생에게 코멘트를 보여줄 때는 비움
+	UserId        *uuid.UUID    `json:"user_id,omitempty"`
+	Dish          string        `json:"dish"`
+	Score         int           `json:"score"`
+	Comment       string        `json:"comment"`
+	CommentStatus CommentStatus `json:"comment_status"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// DishScore is the average score of a dish, or of whole meals when Dish is empty
+type DishScore struct {
+	Dish    string  `json:"dish"`
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+// RatingTrend is the average score of the meals served in one week
+type RatingTrend struct {
+	// 그 주의 월요일, YYYY-MM-DD
+	Week    string  `json:"week"`
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+// RankDishes returns the limit best and worst dishes rated at least minCount times
+// 평가가 적은 요리는 점수가 한두 명에 따라 크게 흔들리므로 뺌
+func RankDishes(scores []DishScore, minCount int, limit int) (top []DishScore, bottom []DishScore) {
+	ranked := make([]DishScore, 0, len(scores))
+	for _, score := range scores {
+		if score.Dish != "" && score.Count >= minCount {
+			ranked = append(ranked, score)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Average != ranked[j].Average {
+			return ranked[i].Average > ranked[j].Average
+		}
+		return ranked[i].Count > ranked[j].Count
+	})
+
+	if limit > len(ranked) {
+		limit = len(ranked)
+	}
+	top = append([]DishScore{}, ranked[:limit]...)
+	bottom = make([]DishScore, 0, limit)
+	for i := len(ranked) - 1; i >= len(ranked)-limit; i-- {
+		bottom = append(bottom, ranked[i])
+	}
+	return top, bottom
+}
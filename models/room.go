@@ -0,0 +1,11 @@
+package models
+
+// Room is a classroom of a school
+// 수업의 Location이 Name과 같으면 그 교실에서 하는 수업임
+type Room struct {
+	ID       DbId `json:"id"`
+	SchoolId `json:"school_id"`
+	Name     string `json:"name"`
+	Building string `json:"building"`
+	Capacity int    `json:"capacity"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Term is a semester of a school, dates are YYYY-MM-DD and inclusive
+type Term struct {
+	ID        DbId `json:"id"`
+	SchoolId  `json:"school_id"`
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// Dates parses StartDate and EndDate in the given location
+func (term Term) Dates(loc *time.Location) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01-02", term.StartDate, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := time.ParseInLocation("2006-01-02", term.EndDate, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
@@ -1,16 +1,24 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+	"time"
+)
 
 // TimetableEntry struct represents a lesson
 // Multiple Timetable may share same TimetableEntry
 type TimetableEntry struct {
-	ID        DbId      `json:"id"`
-	TeacherId uuid.UUID `json:"teacher"`
-	Location  string    `json:"location"`
-	Day       string    `json:"day"`
-	Period    string    `json:"period"`
-	Subject   string    `json:"subject"`
+	ID        DbId `json:"id"`
+	SchoolId  `json:"school_id"`
+	TeacherId uuid.UUID  `json:"teacher"`
+	Location  string     `json:"location"`
+	Day       Weekday    `json:"day"`
+	Period    Period     `json:"period"`
+	Subject   string     `json:"subject"`
+	Version   int64      `json:"version"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Exception 특정 날짜의 시간표를 만들 때만 채워지고, 그날 이 수업을 바꾼 예외임
+	Exception *TimetableException `json:"exception,omitempty"`
 }
 
 // Timetable is a holder of TimetableEntry objects and its visibility
@@ -19,3 +27,181 @@ type Timetable struct {
 	Entries  []DbId `json:"entries"`
 	IsPublic bool   `json:"isPublic"`
 }
+
+// CanViewTimetable reports whether viewer may see owner's timetable
+// 본인, 같은 학교 선생님, 또는 owner를 친구로 둔 학생만 볼 수 있음
+// 친구는 owner가 그 친구에게 따로 설정한 override가 있으면 그것을, 없으면 IsPublic을 따름
+func CanViewTimetable(viewer *Account, owner *Account, override *bool) bool {
+	info, ok := owner.PermissionInfo.(StudentInfo)
+	if !ok {
+		return false
+	}
+	if viewer.UserId == owner.UserId {
+		return true
+	}
+
+	switch viewerInfo := viewer.PermissionInfo.(type) {
+	case TeacherInfo:
+		return viewerInfo.SchoolId == info.SchoolId
+	case StudentInfo:
+		if !viewerInfo.HasFriend(owner.UserId) {
+			return false
+		}
+		if override != nil {
+			return *override
+		}
+		return info.Timetable.IsPublic
+	}
+	return false
+}
+
+// ScheduledEntry is a TimetableEntry with the clock times of its period
+// 학교에 종 시간표가 없으면 Start, End는 빠짐
+type ScheduledEntry struct {
+	TimetableEntry
+	Start *ClockTime `json:"start,omitempty"`
+	End   *ClockTime `json:"end,omitempty"`
+}
+
+// NewScheduledEntry looks up the times of entry's period in schedule, which may be nil
+func NewScheduledEntry(entry TimetableEntry, schedule *BellSchedule) ScheduledEntry {
+	scheduled := ScheduledEntry{TimetableEntry: entry}
+	if bell, ok := schedule.Times(entry.Period); ok {
+		scheduled.Start = &bell.Start
+		scheduled.End = &bell.End
+	}
+	return scheduled
+}
+
+// ResolvedTimetable is a Timetable with its entries loaded from the database
+// Date가 있으면 그날 실제로 하는 수업만 들어가고, 취소된 수업은 Cancelled에 들어감
+type ResolvedTimetable struct {
+	Date      string           `json:"date,omitempty"`
+	Entries   []ScheduledEntry `json:"entries"`
+	Cancelled []TimetableEntry `json:"cancelled,omitempty"`
+	IsPublic  bool             `json:"isPublic"`
+}
+
+// TeacherLesson is a lesson a teacher teaches with the number of students enrolled
+type TeacherLesson struct {
+	ScheduledEntry
+	Enrolled int `json:"enrolled"`
+}
+
+// RosterStudent is a student enrolled in a lesson
+// 비밀번호 등이 나가지 않도록 Account 대신 씀
+type RosterStudent struct {
+	UserId uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	Grade  int       `json:"grade"`
+	Class  int       `json:"class"`
+	Number int       `json:"number"`
+}
+
+// WeekLesson is a lesson placed in a TimetableWeek with its teacher's name resolved
+type WeekLesson struct {
+	ID          DbId      `json:"id"`
+	Subject     string    `json:"subject"`
+	TeacherId   uuid.UUID `json:"teacher"`
+	TeacherName string    `json:"teacherName"`
+	Location    string    `json:"location"`
+	// Exception 날짜가 정해진 시간표에서 이 수업이 평소와 다를 때만 채워짐
+	Exception *TimetableException `json:"exception,omitempty"`
+}
+
+// NewWeekLesson fills in the teacher's name of entry
+func NewWeekLesson(entry TimetableEntry, teacherNames map[uuid.UUID]string) *WeekLesson {
+	return &WeekLesson{
+		ID:          entry.ID,
+		Subject:     entry.Subject,
+		TeacherId:   entry.TeacherId,
+		TeacherName: teacherNames[entry.TeacherId],
+		Location:    entry.Location,
+		Exception:   entry.Exception,
+	}
+}
+
+// TimetableSlot is one period of one day in a TimetableWeek
+// 수업이 없는 칸은 Lesson이 null로 내려감
+type TimetableSlot struct {
+	Period Period      `json:"period"`
+	Start  *ClockTime  `json:"start"`
+	End    *ClockTime  `json:"end"`
+	Lesson *WeekLesson `json:"lesson"`
+}
+
+// TimetableDay is a column of a TimetableWeek
+// Date와 Schedule은 종 시간표를 알 수 있을 때만 채워짐
+type TimetableDay struct {
+	Day      Weekday         `json:"day"`
+	Name     string          `json:"name"`
+	Date     string          `json:"date,omitempty"`
+	Schedule string          `json:"schedule,omitempty"`
+	Slots    []TimetableSlot `json:"slots"`
+	// Cancelled 그날 취소된 수업, 날짜가 정해진 시간표에서만 채워짐
+	Cancelled []WeekLesson `json:"cancelled,omitempty"`
+}
+
+// TimetableWeek is a Monday to Friday by period grid of a student's lessons
+type TimetableWeek struct {
+	Periods  int            `json:"periods"`
+	Days     []TimetableDay `json:"days"`
+	IsPublic bool           `json:"isPublic"`
+}
+
+// NewTimetableWeek lays entries out on the week grid
+// teacherNames가 없는 선생님은 이름을 빈 문자열로 두고, bells가 없는 요일은 시각을 null로 둠
+// 같은 칸에 수업이 여러 개 있으면 먼저 온 수업만 들어감
+func NewTimetableWeek(entries []TimetableEntry, teacherNames map[uuid.UUID]string, bells map[Weekday]*BellSchedule) TimetableWeek {
+	periods := DefaultPeriods
+	for _, entry := range entries {
+		if entry.Period.Valid() && int(entry.Period) > periods {
+			periods = int(entry.Period)
+		}
+	}
+
+	week := TimetableWeek{Periods: periods, Days: make([]TimetableDay, len(SchoolDays))}
+	column := make(map[Weekday]int, len(SchoolDays))
+	for i, day := range SchoolDays {
+		schedule := bells[day]
+		slots := make([]TimetableSlot, periods)
+		for p := range slots {
+			slots[p].Period = Period(p + 1)
+			if bell, ok := schedule.Times(slots[p].Period); ok {
+				slots[p].Start = &bell.Start
+				slots[p].End = &bell.End
+			}
+		}
+		week.Days[i] = TimetableDay{Day: day, Name: day.String(), Slots: slots}
+		if schedule != nil {
+			week.Days[i].Schedule = schedule.Name
+		}
+		column[day] = i
+	}
+
+	for _, entry := range entries {
+		i, ok := column[entry.Day]
+		if !ok || !entry.Period.Valid() {
+			continue
+		}
+		slot := &week.Days[i].Slots[entry.Period-1]
+		if slot.Lesson != nil {
+			continue
+		}
+		slot.Lesson = NewWeekLesson(entry, teacherNames)
+	}
+	return week
+}
+
+// SetDates fills in the date of each day of a week starting on monday
+// cancelled는 ApplyWeekExceptions에서 취소된 수업이고 요일에 맞춰 들어감
+func (week *TimetableWeek) SetDates(monday time.Time, cancelled []TimetableEntry, teacherNames map[uuid.UUID]string) {
+	for i := range week.Days {
+		week.Days[i].Date = monday.AddDate(0, 0, i).Format("2006-01-02")
+		for _, entry := range cancelled {
+			if entry.Day == week.Days[i].Day {
+				week.Days[i].Cancelled = append(week.Days[i].Cancelled, *NewWeekLesson(entry, teacherNames))
+			}
+		}
+	}
+}
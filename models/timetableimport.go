@@ -0,0 +1,38 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// TimetableImport is a class whose timetable is copied from NEIS
+// NEIS 시간표에는 선생님 정보가 없어서 가져온 수업은 모두 TeacherId(보통 담임) 앞으로 만들어짐
+type TimetableImport struct {
+	ID        DbId `json:"id"`
+	SchoolId  `json:"school_id"`
+	Grade     int        `json:"grade"`
+	Class     int        `json:"class"`
+	TeacherId uuid.UUID  `json:"teacher_id"`
+	LastRunAt *time.Time `json:"last_run_at"`
+	LastError string     `json:"last_error"`
+}
+
+// TimetableImportResult counts what an import run changed
+type TimetableImportResult struct {
+	Rows      int `json:"rows"`
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+	Retired   int `json:"retired"` // NEIS가 더 이상 주지 않아서 휴지통으로 옮긴 수업
+	Enrolled  int `json:"enrolled"`
+}
+
+// MenuImportResult counts what a meal import run changed
+type MenuImportResult struct {
+	Rows      int `json:"rows"`
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+}
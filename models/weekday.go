@@ -0,0 +1,144 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Weekday is a day of the school week, stored as 1 (Monday) to 7 (Sunday)
+// time.Weekday는 일요일이 0이라서 DB에 저장된 값과 맞지 않으므로 따로 정의함
+type Weekday int
+
+const (
+	Monday Weekday = iota + 1
+	Tuesday
+	Wednesday
+	Thursday
+	Friday
+	Saturday
+	Sunday
+)
+
+// SchoolDays 주간 시간표에 표시되는 요일
+var SchoolDays = []Weekday{Monday, Tuesday, Wednesday, Thursday, Friday}
+
+var weekdayNames = map[string]Weekday{
+	"monday": Monday, "mon": Monday, "월": Monday, "월요일": Monday,
+	"tuesday": Tuesday, "tue": Tuesday, "화": Tuesday, "화요일": Tuesday,
+	"wednesday": Wednesday, "wed": Wednesday, "수": Wednesday, "수요일": Wednesday,
+	"thursday": Thursday, "thu": Thursday, "목": Thursday, "목요일": Thursday,
+	"friday": Friday, "fri": Friday, "금": Friday, "금요일": Friday,
+	"saturday": Saturday, "sat": Saturday, "토": Saturday, "토요일": Saturday,
+	"sunday": Sunday, "sun": Sunday, "일": Sunday, "일요일": Sunday,
+}
+
+func (d Weekday) Valid() bool {
+	return d >= Monday && d <= Sunday
+}
+
+func (d Weekday) String() string {
+	switch d {
+	case Monday:
+		return "Monday"
+	case Tuesday:
+		return "Tuesday"
+	case Wednesday:
+		return "Wednesday"
+	case Thursday:
+		return "Thursday"
+	case Friday:
+		return "Friday"
+	case Saturday:
+		return "Saturday"
+	case Sunday:
+		return "Sunday"
+	}
+	return "Weekday(" + strconv.Itoa(int(d)) + ")"
+}
+
+// ParseWeekday accepts a number (1-7) or an English or Korean day name
+func ParseWeekday(s string) (Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if day, ok := weekdayNames[s]; ok {
+		return day, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || !Weekday(n).Valid() {
+		return 0, errors.New("invalid weekday: " + s)
+	}
+	return Weekday(n), nil
+}
+
+// UnmarshalJSON 예전 클라이언트가 요일을 문자열로 보내던 것도 받아들임
+func (d *Weekday) UnmarshalJSON(data []byte) error {
+	var n int
+	if json.Unmarshal(data, &n) == nil {
+		if !Weekday(n).Valid() {
+			return errors.New("invalid weekday: " + strconv.Itoa(n))
+		}
+		*d = Weekday(n)
+		return nil
+	}
+
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	day, err := ParseWeekday(s)
+	if err != nil {
+		return err
+	}
+	*d = day
+	return nil
+}
+
+// Period is a lesson number within a school day, starting from 1
+type Period int
+
+// DefaultPeriods 주간 시간표에 기본으로 표시되는 교시 수
+// 이보다 늦은 교시에 수업이 있으면 그 교시까지 늘어남
+const DefaultPeriods = 7
+
+// MaxPeriod 하루에 있을 수 있는 가장 늦은 교시
+const MaxPeriod = 12
+
+func (p Period) Valid() bool {
+	return p >= 1 && p <= MaxPeriod
+}
+
+// ParsePeriod accepts a number with an optional "교시" suffix, e.g. "3" or "3교시"
+func ParsePeriod(s string) (Period, error) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "교시")
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || !Period(n).Valid() {
+		return 0, errors.New("invalid period: " + s)
+	}
+	return Period(n), nil
+}
+
+// UnmarshalJSON 예전 클라이언트가 교시를 문자열로 보내던 것도 받아들임
+func (p *Period) UnmarshalJSON(data []byte) error {
+	var n int
+	if json.Unmarshal(data, &n) == nil {
+		if !Period(n).Valid() {
+			return errors.New("invalid period: " + strconv.Itoa(n))
+		}
+		*p = Period(n)
+		return nil
+	}
+
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	period, err := ParsePeriod(s)
+	if err != nil {
+		return err
+	}
+	*p = period
+	return nil
+}
@@ -0,0 +1,154 @@
+// Package neis reads school data from the NEIS Open API (https://open.neis.go.kr)
+package neis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultBaseURL = "https://open.neis.go.kr/hub"
+
+	codeOK     = "INFO-000"
+	codeNoData = "INFO-200"
+)
+
+// pageSize NEIS는 한 페이지에 최대 1000행까지 줌, 테스트에서 여러 페이지를 만들 수 있도록 변수로 둠
+var pageSize = 1000
+
+// Client calls the NEIS Open API
+// BaseURL을 바꾸면 로컬에서 띄운 가짜 서버나 녹화한 응답으로 테스트할 수 있음
+type Client struct {
+	BaseURL string
+	Key     string
+	HTTP    *http.Client
+}
+
+// NewClientFromEnv NEIS_API_URL, NEIS_API_KEY 환경 변수로 클라이언트를 만듦
+func NewClientFromEnv() *Client {
+	baseURL := os.Getenv("NEIS_API_URL")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Client{
+		BaseURL: baseURL,
+		Key:     os.Getenv("NEIS_API_KEY"),
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type result struct {
+	Code    string `json:"CODE"`
+	Message string `json:"MESSAGE"`
+}
+
+// envelope NEIS 응답은 {"서비스명": [{"head": [...]}, {"row": [...]}]} 모양이고
+// 데이터가 없으면 {"RESULT": {...}}만 옴
+type envelope struct {
+	Result *result `json:"RESULT"`
+}
+
+type section struct {
+	Head []struct {
+		TotalCount *int    `json:"list_total_count"`
+		Result     *result `json:"RESULT"`
+	} `json:"head"`
+	Row json.RawMessage `json:"row"`
+}
+
+// fetchAll 모든 페이지를 돌면서 row 배열을 하나로 모음
+func fetchAll[T any](ctx context.Context, client *Client, service string, params url.Values) ([]T, error) {
+	rows := make([]T, 0)
+	for page := 1; ; page++ {
+		pageRows, total, err := fetchPage[T](ctx, client, service, params, page)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, pageRows...)
+		if len(pageRows) < pageSize || len(rows) >= total {
+			return rows, nil
+		}
+	}
+}
+
+func fetchPage[T any](ctx context.Context, client *Client, service string, params url.Values, page int) ([]T, int, error) {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	query.Set("Type", "json")
+	query.Set("pIndex", strconv.Itoa(page))
+	query.Set("pSize", strconv.Itoa(pageSize))
+	if client.Key != "" {
+		query.Set("KEY", client.Key)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, client.BaseURL+"/"+service+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	response, err := client.HTTP.Do(request)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("neis %s: unexpected status %s", service, response.Status)
+	}
+
+	var raw map[string]json.RawMessage
+	err = json.NewDecoder(response.Body).Decode(&raw)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if body, ok := raw["RESULT"]; ok {
+		var r result
+		if err := json.Unmarshal(body, &r); err != nil {
+			return nil, 0, err
+		}
+		if r.Code == codeNoData {
+			return []T{}, 0, nil
+		}
+		return nil, 0, fmt.Errorf("neis %s: %s %s", service, r.Code, r.Message)
+	}
+
+	body, ok := raw[service]
+	if !ok {
+		return nil, 0, errors.New("neis " + service + ": response has no data")
+	}
+	var sections []section
+	err = json.Unmarshal(body, &sections)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := 0
+	var rows []T
+	for _, s := range sections {
+		for _, head := range s.Head {
+			if head.TotalCount != nil {
+				total = *head.TotalCount
+			}
+			if head.Result != nil && head.Result.Code != codeOK {
+				return nil, 0, fmt.Errorf("neis %s: %s %s", service, head.Result.Code, head.Result.Message)
+			}
+		}
+		if s.Row != nil {
+			err = json.Unmarshal(s.Row, &rows)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	return rows, total, nil
+}
@@ -0,0 +1,201 @@
+package neis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// standIn NEIS 대신 testdata의 녹화한 응답을 돌려주는 로컬 서버
+// 녹화한 응답의 row를 pIndex, pSize에 맞게 잘라서 실제 API처럼 페이지를 나눔
+type standIn struct {
+	mutex    sync.Mutex
+	fixtures map[string]string // 서비스 이름 -> testdata 파일
+	status   int
+	requests []url.Values
+}
+
+func newStandIn(t *testing.T, fixtures map[string]string) (*standIn, *Client) {
+	t.Helper()
+	stand := &standIn{fixtures: fixtures, status: http.StatusOK}
+	server := httptest.NewServer(stand)
+	t.Cleanup(server.Close)
+	return stand, &Client{BaseURL: server.URL, Key: "test-key", HTTP: server.Client()}
+}
+
+// serve 서비스의 응답 파일을 바꿈, 다음 요청부터 적용됨
+func (stand *standIn) serve(service string, fixture string) {
+	stand.mutex.Lock()
+	defer stand.mutex.Unlock()
+	stand.fixtures[service] = fixture
+}
+
+// fail 다음 요청부터 status로 응답함
+func (stand *standIn) fail(status int) {
+	stand.mutex.Lock()
+	defer stand.mutex.Unlock()
+	stand.status = status
+}
+
+func (stand *standIn) requested() []url.Values {
+	stand.mutex.Lock()
+	defer stand.mutex.Unlock()
+	return append([]url.Values(nil), stand.requests...)
+}
+
+func (stand *standIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	stand.mutex.Lock()
+	service := filepath.Base(r.URL.Path)
+	fixture, ok := stand.fixtures[service]
+	status := stand.status
+	stand.requests = append(stand.requests, r.URL.Query())
+	stand.mutex.Unlock()
+
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	recorded, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(recorded, &raw); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body, ok := raw[service]
+	if !ok {
+		// 데이터가 없거나 에러인 응답은 그대로 돌려줌
+		_, _ = w.Write(recorded)
+		return
+	}
+	var sections []struct {
+		Row []json.RawMessage `json:"row"`
+	}
+	if err := json.Unmarshal(body, &sections); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rows := make([]json.RawMessage, 0)
+	for _, s := range sections {
+		rows = append(rows, s.Row...)
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("pIndex"))
+	size, _ := strconv.Atoi(r.URL.Query().Get("pSize"))
+	start := (page - 1) * size
+	if page < 1 || size < 1 || start >= len(rows) {
+		_, _ = w.Write([]byte(`{"RESULT":{"CODE":"INFO-200","MESSAGE":"해당하는 데이터가 없습니다."}}`))
+		return
+	}
+	end := start + size
+	if end > len(rows) {
+		end = len(rows)
+	}
+	head := []interface{}{
+		map[string]int{"list_total_count": len(rows)},
+		map[string]interface{}{"RESULT": result{Code: codeOK, Message: "정상 처리되었습니다."}},
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		service: []interface{}{
+			map[string]interface{}{"head": head},
+			map[string]interface{}{"row": rows[start:end]},
+		},
+	})
+}
+
+// usePageSize 여러 페이지를 만들 수 있도록 테스트하는 동안 페이지 크기를 바꿈
+func usePageSize(t *testing.T, size int) {
+	t.Helper()
+	previous := pageSize
+	pageSize = size
+	t.Cleanup(func() {
+		pageSize = previous
+	})
+}
+
+func TestFetchAllPaging(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  string
+		pageSize int
+		status   int
+		rows     int
+		pages    int
+		wantErr  bool
+	}{
+		{name: "one page", fixture: "hisTimetable.json", pageSize: 1000, rows: 8, pages: 1},
+		{name: "several pages", fixture: "hisTimetable.json", pageSize: 3, rows: 8, pages: 3},
+		// 마지막 페이지가 꽉 차면 list_total_count를 보고 멈춰야 함
+		{name: "last page is full", fixture: "hisTimetable.json", pageSize: 4, rows: 8, pages: 2},
+		{name: "no data", fixture: "no_data.json", pageSize: 1000, rows: 0, pages: 1},
+		{name: "invalid key", fixture: "invalid_key.json", pageSize: 1000, pages: 1, wantErr: true},
+		{name: "server error", fixture: "hisTimetable.json", pageSize: 1000, status: http.StatusInternalServerError, pages: 1, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			usePageSize(t, test.pageSize)
+			stand, client := newStandIn(t, map[string]string{timetableService: test.fixture})
+			if test.status != 0 {
+				stand.fail(test.status)
+			}
+
+			params := url.Values{}
+			params.Set("GRADE", "1")
+			rows, err := fetchAll[TimetableRow](context.Background(), client, timetableService, params)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("fetchAll should fail")
+				}
+			} else if err != nil {
+				t.Fatalf("fetchAll: %s", err.Error())
+			}
+			if len(rows) != test.rows {
+				t.Errorf("got %d rows, want %d", len(rows), test.rows)
+			}
+
+			requests := stand.requested()
+			if len(requests) != test.pages {
+				t.Fatalf("made %d requests, want %d", len(requests), test.pages)
+			}
+			for i, query := range requests {
+				if query.Get("pIndex") != strconv.Itoa(i+1) || query.Get("pSize") != strconv.Itoa(test.pageSize) {
+					t.Errorf("request %d asked for page %s of size %s", i, query.Get("pIndex"), query.Get("pSize"))
+				}
+				if query.Get("Type") != "json" || query.Get("KEY") != "test-key" || query.Get("GRADE") != "1" {
+					t.Errorf("request %d has query %v", i, query)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchAllKeepsRowOrderAcrossPages(t *testing.T) {
+	usePageSize(t, 3)
+	_, client := newStandIn(t, map[string]string{timetableService: "hisTimetable.json"})
+
+	rows, err := fetchAll[TimetableRow](context.Background(), client, timetableService, url.Values{})
+	if err != nil {
+		t.Fatalf("fetchAll: %s", err.Error())
+	}
+	want := []string{"20240304", "20240304", "20240305", "20240305", "20240311", "20240311", "20240312", "20240312"}
+	for i, row := range rows {
+		if row.Date != want[i] {
+			t.Errorf("row %d date = %s, want %s", i, row.Date, want[i])
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package neis
+
+import (
+	"context"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+	"log"
+	"time"
+)
+
+// 테스트에서 DB 대신 메모리에 있는 스토어를 쓸 수 있도록 변수로 둠
+var (
+	getSchool = db.GetSchool
+	withTx    = db.WithTx
+)
+
+// ImportTimetable copies a class's NEIS timetable between from and to into timetable entries
+// and enrolls every student of the class in the imported lessons
+// 같은 기간을 다시 가져와도 수업이 중복으로 생기지 않고, 바뀐 과목이나 교실만 고쳐짐
+// 가져온 학기에 NEIS가 더 이상 주지 않는 수업은 휴지통으로 옮김
+func ImportTimetable(ctx context.Context, client *Client, job models.TimetableImport, from time.Time, to time.Time) (*models.TimetableImportResult, error) {
+	school, err := getSchool(job.SchoolId)
+	if err != nil {
+		return nil, err
+	}
+
+	// API 호출은 트랜잭션 밖에서 해서 NEIS가 느려도 DB 락을 오래 잡지 않게 함
+	rows, err := client.Timetable(ctx, TimetableQuery{
+		RegionId: school.RegionId,
+		SchoolId: school.SchoolId,
+		Grade:    job.Grade,
+		Class:    job.Class,
+		From:     from,
+		To:       to,
+	})
+	if err != nil {
+		return nil, err
+	}
+	lessons := CollapseTimetable(job, rows)
+
+	var result models.TimetableImportResult
+	err = withTx(ctx, func(tx db.Stores) error {
+		// WithTx가 다시 시도할 수 있으므로 결과는 매번 처음부터 셈
+		result = models.TimetableImportResult{Rows: len(rows)}
+		ids := make([]models.DbId, 0, len(lessons))
+		for _, lesson := range lessons {
+			entry := lesson.Entry
+			action, err := tx.Timetables.ImportTimetableEntry(&entry, lesson.Key)
+			if err != nil {
+				return err
+			}
+			switch action {
+			case db.ImportCreated:
+				result.Created++
+			case db.ImportUpdated:
+				result.Updated++
+			case db.ImportUnchanged:
+				result.Unchanged++
+			case db.ImportSkipped:
+				result.Skipped++
+				continue
+			}
+			ids = append(ids, entry.ID)
+		}
+
+		// NEIS가 더 이상 주지 않는 시간의 수업은 휴지통으로 옮기고 학생 시간표에서도 뺌
+		// 그렇지 않으면 시간표가 바뀐 뒤에 예전 수업이 새 수업과 같이 남아서 겹치는 것으로 나옴
+		keep := make([]string, len(lessons))
+		for i, lesson := range lessons {
+			keep[i] = lesson.Key
+		}
+		for _, prefix := range StalePrefixes(job, lessons) {
+			retired, err := tx.Timetables.RetireImportedTimetables(prefix, keep)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Accounts.UnenrollEntries(retired)
+			if err != nil {
+				return err
+			}
+			result.Retired += len(retired)
+		}
+
+		enrolled, err := tx.Accounts.EnrollClass(job.SchoolId, job.Grade, job.Class, ids)
+		if err != nil {
+			return err
+		}
+		result.Enrolled = len(enrolled)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ImportMeals copies the school's NEIS meals between from and to into cafeteria menus
+// 학교, 날짜, 식사 이름이 같은 급식은 새로 만들지 않고 고치며, 관리자가 지운 급식은 되살리지 않음
+func ImportMeals(ctx context.Context, client *Client, schoolId models.SchoolId, from time.Time, to time.Time) (*models.MenuImportResult, error) {
+	school, err := getSchool(schoolId)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := client.Meals(ctx, school, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.MenuImportResult
+	err = withTx(ctx, func(tx db.Stores) error {
+		result = models.MenuImportResult{Rows: len(rows)}
+		for _, row := range rows {
+			menu, ok := MenuFromRow(schoolId, row)
+			if !ok {
+				result.Skipped++
+				continue
+			}
+			action, err := tx.Menus.ImportMenu(&menu)
+			if err != nil {
+				return err
+			}
+			switch action {
+			case db.ImportCreated:
+				result.Created++
+			case db.ImportUpdated:
+				result.Updated++
+			case db.ImportUnchanged:
+				result.Unchanged++
+			case db.ImportSkipped:
+				result.Skipped++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SyncWindow 예약된 동기화는 이번 주 월요일부터 다음 주 일요일까지 가져옴
+func SyncWindow(now time.Time) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monday := today.AddDate(0, 0, -int(models.WeekdayOf(today)-models.Monday))
+	return monday, monday.AddDate(0, 0, 13)
+}
+
+// SyncTimetables imports every scheduled class and records how each run went
+// 한 반이 실패하거나 결과를 기록하지 못해도 다른 반은 계속 가져옴
+func SyncTimetables(ctx context.Context, client *Client, now time.Time) error {
+	jobs, err := db.GetTimetableImports()
+	if err != nil {
+		return err
+	}
+
+	from, to := SyncWindow(now)
+	for _, job := range jobs {
+		result, runErr := ImportTimetable(ctx, client, job, from, to)
+		if runErr != nil {
+			log.Printf("Error importing NEIS timetable of %s %d-%d: %s", job.SchoolId, job.Grade, job.Class, runErr.Error())
+		} else if result.Created+result.Updated+result.Retired > 0 {
+			log.Printf("Imported NEIS timetable of %s %d-%d: %d created, %d updated, %d retired", job.SchoolId, job.Grade, job.Class, result.Created, result.Updated, result.Retired)
+		}
+		err := db.RecordTimetableImport(job.ID, now, runErr)
+		if err != nil {
+			log.Printf("Error recording NEIS timetable import of %s %d-%d: %s", job.SchoolId, job.Grade, job.Class, err.Error())
+			continue
+		}
+	}
+	return nil
+}
+
+// SyncMeals imports the meals of every registered school
+// 한 학교가 실패해도 다른 학교는 계속 가져옴
+func SyncMeals(ctx context.Context, client *Client, now time.Time) error {
+	schools, err := db.GetSchools()
+	if err != nil {
+		return err
+	}
+
+	from, to := SyncWindow(now)
+	for _, school := range schools {
+		result, err := ImportMeals(ctx, client, school.SchoolId, from, to)
+		if err != nil {
+			log.Printf("Error importing NEIS meals of %s: %s", school.SchoolId, err.Error())
+			continue
+		}
+		if result.Created+result.Updated > 0 {
+			log.Printf("Imported NEIS meals of %s: %d created, %d updated", school.SchoolId, result.Created, result.Updated)
+		}
+	}
+	return nil
+}
+
+// StartTimetableSync NEIS 시간표와 급식을 interval마다 다시 가져오는 고루틴을 시작함
+func StartTimetableSync(client *Client, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			err := SyncTimetables(context.Background(), client, time.Now())
+			if err != nil {
+				log.Printf("Error syncing NEIS timetables: %s", err.Error())
+			}
+			err = SyncMeals(context.Background(), client, time.Now())
+			if err != nil {
+				log.Printf("Error syncing NEIS meals: %s", err.Error())
+			}
+			<-ticker.C
+		}
+	}()
+}
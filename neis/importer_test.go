@@ -0,0 +1,211 @@
+package neis
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+)
+
+// memoryTimetables 가져온 수업을 external_key로 찾는 메모리 스토어
+// ImportTimetableEntry와 RetireImportedTimetables는 sqlTimetableStore와 같은 규칙을 따름
+type memoryTimetables struct {
+	db.TimetableStore
+	entries map[models.DbId]*models.TimetableEntry
+	keys    map[string]models.DbId
+	deleted map[models.DbId]bool
+	nextId  models.DbId
+}
+
+func (s *memoryTimetables) ImportTimetableEntry(entry *models.TimetableEntry, externalKey string) (db.ImportAction, error) {
+	id, ok := s.keys[externalKey]
+	if !ok {
+		s.nextId++
+		entry.ID = s.nextId
+		entry.Version = 1
+		stored := *entry
+		s.entries[entry.ID] = &stored
+		s.keys[externalKey] = entry.ID
+		return db.ImportCreated, nil
+	}
+
+	existing := s.entries[id]
+	entry.ID = existing.ID
+	entry.Version = existing.Version
+	if s.deleted[id] {
+		return db.ImportSkipped, nil
+	}
+	entry.TeacherId = existing.TeacherId
+	if existing.SchoolId == entry.SchoolId && existing.Location == entry.Location && existing.Day == entry.Day &&
+		existing.Period == entry.Period && existing.Subject == entry.Subject {
+		return db.ImportUnchanged, nil
+	}
+	entry.Version++
+	stored := *entry
+	s.entries[id] = &stored
+	return db.ImportUpdated, nil
+}
+
+func (s *memoryTimetables) RetireImportedTimetables(keyPrefix string, keep []string) ([]models.DbId, error) {
+	kept := make(map[string]bool, len(keep))
+	for _, key := range keep {
+		kept[key] = true
+	}
+	retired := make([]models.DbId, 0)
+	for key, id := range s.keys {
+		if !strings.HasPrefix(key, keyPrefix) || kept[key] || s.deleted[id] {
+			continue
+		}
+		s.deleted[id] = true
+		delete(s.keys, key)
+		retired = append(retired, id)
+	}
+	return retired, nil
+}
+
+// memoryAccounts 한 반의 학생들이 듣는 수업만 기억하는 메모리 스토어
+type memoryAccounts struct {
+	db.AccountStore
+	class    map[uuid.UUID]map[models.DbId]bool
+	schoolId models.SchoolId
+	grade    int
+	number   int
+}
+
+func (s *memoryAccounts) EnrollClass(schoolId models.SchoolId, grade int, class int, entryIds []models.DbId) ([]uuid.UUID, error) {
+	enrolled := make([]uuid.UUID, 0)
+	if schoolId != s.schoolId || grade != s.grade || class != s.number {
+		return enrolled, nil
+	}
+	for student, entries := range s.class {
+		added := false
+		for _, id := range entryIds {
+			if !entries[id] {
+				entries[id] = true
+				added = true
+			}
+		}
+		if added {
+			enrolled = append(enrolled, student)
+		}
+	}
+	return enrolled, nil
+}
+
+func (s *memoryAccounts) UnenrollEntries(entryIds []models.DbId) ([]uuid.UUID, error) {
+	unenrolled := make([]uuid.UUID, 0)
+	for student, entries := range s.class {
+		removed := false
+		for _, id := range entryIds {
+			if entries[id] {
+				delete(entries, id)
+				removed = true
+			}
+		}
+		if removed {
+			unenrolled = append(unenrolled, student)
+		}
+	}
+	return unenrolled, nil
+}
+
+// useMemoryStores ImportTimetable이 DB 대신 메모리 스토어를 쓰게 함
+func useMemoryStores(t *testing.T, school *models.School, timetables *memoryTimetables, accounts *memoryAccounts) {
+	t.Helper()
+	previousGetSchool, previousWithTx := getSchool, withTx
+	getSchool = func(id models.SchoolId) (*models.School, error) {
+		return school, nil
+	}
+	withTx = func(ctx context.Context, fn func(tx db.Stores) error) error {
+		return fn(db.Stores{Timetables: timetables, Accounts: accounts})
+	}
+	t.Cleanup(func() {
+		getSchool, withTx = previousGetSchool, previousWithTx
+	})
+}
+
+func TestImportTimetableTwice(t *testing.T) {
+	stand, client := newStandIn(t, map[string]string{timetableService: "hisTimetable.json"})
+	timetables := &memoryTimetables{
+		entries: make(map[models.DbId]*models.TimetableEntry),
+		keys:    make(map[string]models.DbId),
+		deleted: make(map[models.DbId]bool),
+	}
+	student := uuid.MustParse("0b7d3f1e-6a2c-4e9b-8d1f-2c3b4a5d6e7f")
+	accounts := &memoryAccounts{
+		class:    map[uuid.UUID]map[models.DbId]bool{student: {}},
+		schoolId: testJob.SchoolId,
+		grade:    testJob.Grade,
+		number:   testJob.Class,
+	}
+	school := &models.School{SchoolId: testJob.SchoolId, RegionId: "G10"}
+	useMemoryStores(t, school, timetables, accounts)
+
+	from := time.Date(2024, time.March, 4, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 13)
+	run := func() models.TimetableImportResult {
+		t.Helper()
+		result, err := ImportTimetable(context.Background(), client, testJob, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *result
+	}
+
+	first := run()
+	want := models.TimetableImportResult{Rows: 8, Created: 3, Enrolled: 1}
+	if first != want {
+		t.Fatalf("first run = %+v, want %+v", first, want)
+	}
+	ids := make(map[string]models.DbId)
+	for key, id := range timetables.keys {
+		ids[key] = id
+	}
+
+	// 같은 응답을 다시 가져오면 아무것도 바뀌지 않아야 함
+	second := run()
+	want = models.TimetableImportResult{Rows: 8, Unchanged: 3}
+	if second != want {
+		t.Fatalf("second run = %+v, want %+v", second, want)
+	}
+	if len(timetables.entries) != 3 {
+		t.Errorf("second run left %d lessons, want 3", len(timetables.entries))
+	}
+	for key, id := range timetables.keys {
+		if ids[key] != id {
+			t.Errorf("lesson %s moved from %d to %d", key, ids[key], id)
+		}
+	}
+	if len(accounts.class[student]) != 3 {
+		t.Errorf("student takes %d lessons, want 3", len(accounts.class[student]))
+	}
+
+	// 시간표가 바뀌면 교실이 바뀐 수업은 고치고 없어진 수업은 휴지통으로 옮긴 뒤 학생 시간표에서도 뺌
+	stand.serve(timetableService, "hisTimetable_changed.json")
+	third := run()
+	want = models.TimetableImportResult{Rows: 6, Updated: 1, Unchanged: 1, Retired: 1}
+	if third != want {
+		t.Fatalf("third run = %+v, want %+v", third, want)
+	}
+	retired := ids["neis:7430310:2024:1:1:3:1:2"]
+	if !timetables.deleted[retired] {
+		t.Errorf("lesson %d was not retired", retired)
+	}
+	if accounts.class[student][retired] {
+		t.Errorf("student still takes retired lesson %d", retired)
+	}
+	if location := timetables.entries[ids["neis:7430310:2024:1:1:3:1:1"]].Location; location != "어학실" {
+		t.Errorf("location = %s, want 어학실", location)
+	}
+
+	// 바뀐 시간표를 다시 가져와도 아무것도 바뀌지 않아야 함
+	fourth := run()
+	want = models.TimetableImportResult{Rows: 6, Unchanged: 2}
+	if fourth != want {
+		t.Fatalf("fourth run = %+v, want %+v", fourth, want)
+	}
+}
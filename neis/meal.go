@@ -0,0 +1,58 @@
+package neis
+
+import (
+	"context"
+	"github.com/username/schoolapp/models"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const mealService = "mealServiceDietInfo"
+
+// MealRow is one meal on one date from the mealServiceDietInfo (급식식단정보) service
+type MealRow struct {
+	Date      string `json:"MLSV_YMD"`
+	MealCode  string `json:"MMEAL_SC_CODE"`
+	MealName  string `json:"MMEAL_SC_NM"`
+	Dishes    string `json:"DDISH_NM"`
+	Calories  string `json:"CAL_INFO"`
+	Nutrients string `json:"NTR_INFO"`
+	Origins   string `json:"ORPLC_INFO"`
+	Servings  string `json:"MLSV_FGR"`
+}
+
+// Meals fetches every meal of the school between from and to
+func (client *Client) Meals(ctx context.Context, school *models.School, from time.Time, to time.Time) ([]MealRow, error) {
+	params := url.Values{}
+	params.Set("ATPT_OFCDC_SC_CODE", string(school.RegionId))
+	params.Set("SD_SCHUL_CODE", string(school.SchoolId))
+	params.Set("MLSV_FROM_YMD", from.Format("20060102"))
+	params.Set("MLSV_TO_YMD", to.Format("20060102"))
+	return fetchAll[MealRow](ctx, client, mealService, params)
+}
+
+// MenuFromRow turns a NEIS meal into a cafeteria menu
+// 날짜나 식사 이름, 요리가 없는 행은 false를 반환함
+func MenuFromRow(schoolId models.SchoolId, row MealRow) (models.CafeteriaMenu, bool) {
+	date, err := time.ParseInLocation("20060102", row.Date, time.Local)
+	mealName := strings.TrimSpace(row.MealName)
+	if err != nil || mealName == "" {
+		return models.CafeteriaMenu{}, false
+	}
+
+	menu := models.CafeteriaMenu{
+		SchoolId:  schoolId,
+		MealName:  mealName,
+		Date:      date.Format("2006-01-02"),
+		Dishes:    models.ParseDishes(row.Dishes),
+		Calories:  models.ParseCalories(row.Calories),
+		Nutrients: models.ParseNutrients(row.Nutrients),
+		Origins:   models.ParseOrigins(row.Origins),
+	}
+	if len(menu.Dishes) == 0 {
+		return models.CafeteriaMenu{}, false
+	}
+	menu.SyncContents()
+	return menu, true
+}
@@ -0,0 +1,177 @@
+package neis
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/username/schoolapp/db"
+	"github.com/username/schoolapp/models"
+)
+
+func TestMain(m *testing.M) {
+	// 알레르기 번호가 맞는지 보려면 표가 있어야 함
+	models.InitAllergies()
+	os.Exit(m.Run())
+}
+
+func calories(value float64) *float64 {
+	return &value
+}
+
+func dish(name string, allergens ...models.AllergyType) models.Dish {
+	if allergens == nil {
+		allergens = []models.AllergyType{}
+	}
+	return models.Dish{Name: name, Allergens: allergens}
+}
+
+func TestMenuFromRow(t *testing.T) {
+	rows := recordedRows[MealRow](t, "mealServiceDietInfo.json", mealService)
+	tests := []struct {
+		name string
+		row  MealRow
+		want models.CafeteriaMenu
+		ok   bool
+	}{
+		{
+			name: "lunch",
+			row:  rows[0],
+			want: models.CafeteriaMenu{
+				SchoolId: "7430310",
+				MealName: "중식",
+				Date:     "2024-03-04",
+				Contents: "쌀밥\n쇠고기미역국 (5.6.16.)\n우유2\n돈육김치볶음 (5.9.10.13.)\n배추김치 (9.13.)",
+				Dishes: []models.Dish{
+					dish("쌀밥"),
+					dish("쇠고기미역국", 5, 6, 16),
+					dish("우유2"),
+					dish("돈육김치볶음", 5, 9, 10, 13),
+					dish("배추김치", 9, 13),
+				},
+				Calories: calories(812.5),
+				Nutrients: []models.Nutrient{
+					{Name: "탄수화물", Unit: "g", Amount: 120.3},
+					{Name: "단백질", Unit: "g", Amount: 30.1},
+					{Name: "지방", Unit: "g", Amount: 20.4},
+					{Name: "비타민A", Unit: "R.E", Amount: 150.2},
+				},
+				Origins: []models.Origin{
+					{Ingredient: "쌀", Origin: "국내산"},
+					{Ingredient: "쇠고기", Origin: "국내산(한우)"},
+					{Ingredient: "돼지고기", Origin: "국내산"},
+				},
+			},
+			ok: true,
+		},
+		{
+			// 읽을 수 없는 영양 정보와 원산지 줄은 건너뛰고, 칼로리 숫자가 없으면 null로 둠
+			name: "malformed nutrition facts",
+			row:  rows[1],
+			want: models.CafeteriaMenu{
+				SchoolId:  "7430310",
+				MealName:  "석식",
+				Date:      "2024-03-04",
+				Contents:  "김치볶음밥 (5.6.9.10.13.)\n계란국 (1.5.)",
+				Dishes:    []models.Dish{dish("김치볶음밥", 5, 6, 9, 10, 13), dish("계란국", 1, 5)},
+				Nutrients: []models.Nutrient{{Name: "지방", Unit: "g", Amount: 12}},
+				Origins:   []models.Origin{},
+			},
+			ok: true,
+		},
+		{name: "no dishes", row: rows[2]},
+		{name: "no meal name", row: rows[3]},
+		{name: "unreadable date", row: rows[4]},
+		{
+			// 대소문자가 다른 <BR />, 빈 줄, 1~18 밖의 번호와 괄호 없는 번호
+			name: "breakfast",
+			row:  rows[5],
+			want: models.CafeteriaMenu{
+				SchoolId:  "7430310",
+				MealName:  "조식",
+				Date:      "2024-03-06",
+				Contents:  "감자튀김 (19.)\n토스트 (5.6.)",
+				Dishes:    []models.Dish{dish("감자튀김 (19.)"), dish("토스트", 5, 6)},
+				Calories:  calories(520),
+				Nutrients: []models.Nutrient{{Name: "단백질", Unit: "g", Amount: 12}},
+				Origins:   []models.Origin{{Ingredient: "빵", Origin: "밀(미국산, 호주산)"}},
+			},
+			ok: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := MenuFromRow("7430310", test.row)
+			if ok != test.ok {
+				t.Fatalf("MenuFromRow() ok = %v, want %v", ok, test.ok)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("MenuFromRow() =\n%+v\nwant\n%+v", got, test.want)
+			}
+		})
+	}
+}
+
+// memoryMenus 학교, 날짜, 식사 이름으로 급식을 찾는 메모리 스토어
+type memoryMenus struct {
+	db.MenuStore
+	menus map[string]models.CafeteriaMenu
+}
+
+func (s *memoryMenus) ImportMenu(menu *models.CafeteriaMenu) (db.ImportAction, error) {
+	key := string(menu.SchoolId) + ":" + menu.Date + ":" + menu.MealName
+	existing, ok := s.menus[key]
+	if !ok {
+		menu.ID = models.DbId(len(s.menus) + 1)
+		menu.Version = 1
+		s.menus[key] = *menu
+		return db.ImportCreated, nil
+	}
+	menu.ID = existing.ID
+	menu.Version = existing.Version
+	if reflect.DeepEqual(existing, *menu) {
+		return db.ImportUnchanged, nil
+	}
+	menu.Version++
+	s.menus[key] = *menu
+	return db.ImportUpdated, nil
+}
+
+func TestImportMealsSkipsUnreadableRows(t *testing.T) {
+	_, client := newStandIn(t, map[string]string{mealService: "mealServiceDietInfo.json"})
+	menus := &memoryMenus{menus: make(map[string]models.CafeteriaMenu)}
+	school := &models.School{SchoolId: "7430310", RegionId: "G10"}
+
+	previousGetSchool, previousWithTx := getSchool, withTx
+	getSchool = func(id models.SchoolId) (*models.School, error) {
+		return school, nil
+	}
+	withTx = func(ctx context.Context, fn func(tx db.Stores) error) error {
+		return fn(db.Stores{Menus: menus})
+	}
+	t.Cleanup(func() {
+		getSchool, withTx = previousGetSchool, previousWithTx
+	})
+
+	from := time.Date(2024, time.March, 4, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 6)
+	result, err := ImportMeals(context.Background(), client, school.SchoolId, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.MenuImportResult{Rows: 6, Created: 3, Skipped: 3}
+	if *result != want {
+		t.Fatalf("first run = %+v, want %+v", *result, want)
+	}
+
+	result, err = ImportMeals(context.Background(), client, school.SchoolId, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = models.MenuImportResult{Rows: 6, Unchanged: 3, Skipped: 3}
+	if *result != want {
+		t.Fatalf("second run = %+v, want %+v", *result, want)
+	}
+}
@@ -0,0 +1,181 @@
+package neis
+
+import (
+	"context"
+	"fmt"
+	"github.com/username/schoolapp/models"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const timetableService = "hisTimetable"
+
+// TimetableRow is one lesson on one date from the hisTimetable (고등학교 시간표) service
+type TimetableRow struct {
+	Year      string `json:"AY"`
+	Semester  string `json:"SEM"`
+	Date      string `json:"ALL_TI_YMD"`
+	Grade     string `json:"GRADE"`
+	Class     string `json:"CLASS_NM"`
+	Period    string `json:"PERIO"`
+	Subject   string `json:"ITRT_CNTNT"`
+	Classroom string `json:"CLRM_NM"`
+}
+
+// TimetableQuery selects one class's timetable between two dates
+type TimetableQuery struct {
+	RegionId models.RegionId
+	SchoolId models.SchoolId
+	Grade    int
+	Class    int
+	From     time.Time
+	To       time.Time
+}
+
+// Timetable fetches every lesson of the class between query.From and query.To
+func (client *Client) Timetable(ctx context.Context, query TimetableQuery) ([]TimetableRow, error) {
+	params := url.Values{}
+	params.Set("ATPT_OFCDC_SC_CODE", string(query.RegionId))
+	params.Set("SD_SCHUL_CODE", string(query.SchoolId))
+	params.Set("GRADE", strconv.Itoa(query.Grade))
+	params.Set("CLASS_NM", strconv.Itoa(query.Class))
+	params.Set("TI_FROM_YMD", query.From.Format("20060102"))
+	params.Set("TI_TO_YMD", query.To.Format("20060102"))
+	return fetchAll[TimetableRow](ctx, client, timetableService, params)
+}
+
+// ImportedLesson is a weekly lesson built from NEIS rows
+// Key는 같은 반, 학기, 요일, 교시면 항상 같으므로 다시 가져와도 수업이 중복으로 생기지 않음
+type ImportedLesson struct {
+	Key      string
+	Year     string
+	Semester string
+	Entry    models.TimetableEntry
+}
+
+type slotKey struct {
+	year     string
+	semester string
+	day      models.Weekday
+	period   models.Period
+}
+
+// CollapseTimetable turns dated rows into one lesson per weekday and period
+// NEIS는 날짜마다 행을 주므로, 같은 요일과 교시에 가장 많이 나온 과목을 그 시간의 수업으로 봄
+// 과목 이름이 없거나 날짜, 교시를 읽을 수 없는 행은 건너뜀
+func CollapseTimetable(job models.TimetableImport, rows []TimetableRow) []ImportedLesson {
+	counts := make(map[slotKey]map[string]int)
+	classrooms := make(map[slotKey]map[string]string)
+	for _, row := range rows {
+		subject := cleanSubject(row.Subject)
+		if subject == "" {
+			continue
+		}
+		date, err := time.ParseInLocation("20060102", row.Date, time.Local)
+		if err != nil {
+			continue
+		}
+		period, err := models.ParsePeriod(row.Period)
+		if err != nil {
+			continue
+		}
+		key := slotKey{year: row.Year, semester: row.Semester, day: models.WeekdayOf(date), period: period}
+		if counts[key] == nil {
+			counts[key] = make(map[string]int)
+			classrooms[key] = make(map[string]string)
+		}
+		counts[key][subject]++
+		if classroom := strings.TrimSpace(row.Classroom); classroom != "" {
+			classrooms[key][subject] = classroom
+		}
+	}
+
+	lessons := make([]ImportedLesson, 0, len(counts))
+	for key, subjects := range counts {
+		subject := mostFrequent(subjects)
+		location := classrooms[key][subject]
+		if location == "" {
+			location = fmt.Sprintf("%d-%d", job.Grade, job.Class)
+		}
+		lessons = append(lessons, ImportedLesson{
+			Key:      LessonKeyPrefix(job, key.year, key.semester) + fmt.Sprintf("%d:%d", key.day, key.period),
+			Year:     key.year,
+			Semester: key.semester,
+			Entry: models.TimetableEntry{
+				SchoolId:  job.SchoolId,
+				TeacherId: job.TeacherId,
+				Location:  location,
+				Day:       key.day,
+				Period:    key.period,
+				Subject:   subject,
+			},
+		})
+	}
+	sort.Slice(lessons, func(i, j int) bool {
+		return lessons[i].Key < lessons[j].Key
+	})
+	return lessons
+}
+
+// LessonKeyPrefix 한 반의 한 학기 수업 키는 모두 "neis:학교:학년도:학기:학년:반:"으로 시작함
+func LessonKeyPrefix(job models.TimetableImport, year string, semester string) string {
+	return fmt.Sprintf("neis:%s:%s:%s:%d:%d:", job.SchoolId, year, semester, job.Grade, job.Class)
+}
+
+// StalePrefixes returns the key prefixes whose lessons should disappear when NEIS no longer returns them
+// 가져온 학기와 그 바로 전 학기를 정리함, 전 학기도 넣어야 학기가 바뀐 뒤에 지난 학기 수업이 새 수업과 같이 남지 않음
+// 행이 하나도 없으면(방학 등) 어떤 학기인지 알 수 없으므로 아무것도 정리하지 않음
+func StalePrefixes(job models.TimetableImport, lessons []ImportedLesson) []string {
+	seen := make(map[string]bool)
+	prefixes := make([]string, 0)
+	add := func(year string, semester string) {
+		prefix := LessonKeyPrefix(job, year, semester)
+		if !seen[prefix] {
+			seen[prefix] = true
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	for _, lesson := range lessons {
+		add(lesson.Year, lesson.Semester)
+		if year, semester, ok := previousSemester(lesson.Year, lesson.Semester); ok {
+			add(year, semester)
+		}
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// previousSemester 1학기의 전 학기는 전 학년도 2학기임
+func previousSemester(year string, semester string) (string, string, bool) {
+	y, err := strconv.Atoi(year)
+	if err != nil {
+		return "", "", false
+	}
+	s, err := strconv.Atoi(semester)
+	if err != nil || s < 1 {
+		return "", "", false
+	}
+	if s > 1 {
+		return year, strconv.Itoa(s - 1), true
+	}
+	return strconv.Itoa(y - 1), "2", true
+}
+
+// cleanSubject NEIS 과목 이름 앞에 붙는 "* ", "- " 같은 표시를 뗌
+func cleanSubject(subject string) string {
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(subject), "*-"))
+}
+
+// mostFrequent 횟수가 같으면 이름 순으로 앞에 오는 과목을 골라서 결과가 항상 같게 함
+func mostFrequent(counts map[string]int) string {
+	best := ""
+	for subject, count := range counts {
+		if best == "" || count > counts[best] || (count == counts[best] && subject < best) {
+			best = subject
+		}
+	}
+	return best
+}
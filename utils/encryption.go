@@ -4,9 +4,11 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/go-jose/go-jose/v3"
+	"github.com/google/uuid"
 	"os"
 	"time"
 )
@@ -109,6 +111,10 @@ func ParseJWT(encrypted *string, claim string) (interface{}, error) {
 	}
 
 	claims := VerifiedToken.Claims.(jwt.MapClaims)
+	// 식권처럼 용도가 정해진 토큰은 받지 않음
+	if _, typed := claims["typ"]; typed {
+		return "", fmt.Errorf("error: unexpected token type")
+	}
 
 	// Extract the user ID from the encrypted claims
 	contents, ok := claims[claim]
@@ -120,10 +126,14 @@ func ParseJWT(encrypted *string, claim string) (interface{}, error) {
 }
 
 func VerifyJWT(tokenString string) (*jwt.Token, error) {
-	// Define the expected signing method and secret key
-	signingMethod := jwt.SigningMethodHS256
+	// Define the expected signing method and public key
+	// SignJWT가 비밀 키로 RS256 서명을 하므로 공개 키로 확인함, 다른 알고리즘의 토큰은 받지 않음
+	signingMethod := jwt.SigningMethodRS256
 	keyFunc := func(token *jwt.Token) (interface{}, error) {
-		return PRIVATE, nil
+		if token.Method != signingMethod {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Method.Alg())
+		}
+		return &PRIVATE.PublicKey, nil
 	}
 
 	// Parse the JWT token string
@@ -153,3 +163,44 @@ func VerifyJWT(tokenString string) (*jwt.Token, error) {
 	// Token is valid, return it
 	return token, nil
 }
+
+// mealPassType 식권 토큰의 typ와 aud
+// 로그인 토큰 같은 다른 JWT를 식권으로 받지 않도록 구분함
+const mealPassType = "meal_pass"
+
+// SignMealPass 학생 QR 코드에 넣을 식권 토큰을 JWT로 만듦
+// 화면을 찍어 다른 학생에게 넘겨도 오래 쓰지 못하도록 expires가 지나면 받지 않음
+// 학생 ID는 user_id가 아니라 sub에 넣어서 식권을 로그인 토큰으로 쓸 수 없게 함
+func SignMealPass(userId uuid.UUID, expires time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"typ": mealPassType,
+		"aud": mealPassType,
+		"sub": userId.String(),
+		"exp": expires.Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	return token.SignedString(PRIVATE)
+}
+
+// VerifyMealPass checks a meal pass signed by SignMealPass and returns the student it belongs to
+func VerifyMealPass(tokenString string) (uuid.UUID, error) {
+	invalid := errors.New("invalid meal pass")
+
+	token, err := VerifyJWT(tokenString)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	if claims["typ"] != mealPassType || !claims.VerifyAudience(mealPassType, true) {
+		return uuid.Nil, invalid
+	}
+	subject, ok := claims["sub"].(string)
+	if !ok {
+		return uuid.Nil, invalid
+	}
+	userId, err := uuid.Parse(subject)
+	if err != nil {
+		return uuid.Nil, invalid
+	}
+	return userId, nil
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+)
+
+var testStudent = uuid.MustParse("0b7d3f1e-6a2c-4e9b-8d1f-2c3b4a5d6e7f")
+
+// useTestKey private.pem 대신 테스트하는 동안만 쓰는 키로 서명함
+func useTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := PRIVATE
+	PRIVATE = key
+	t.Cleanup(func() {
+		PRIVATE = previous
+	})
+	return key
+}
+
+func signClaims(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// 로그인할 때 SignJWT로 만든 토큰은 모든 인증이 필요한 요청에서 그대로 받아들여져야 함
+func TestLoginTokenVerifies(t *testing.T) {
+	useTestKey(t)
+
+	login, err := SignJWT(testStudent.String(), "user_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := VerifyJWT(login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Method != jwt.SigningMethodRS256 {
+		t.Errorf("signing method = %s, want RS256", token.Method.Alg())
+	}
+	got, err := ParseJWT(&login, "user_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != testStudent.String() {
+		t.Errorf("ParseJWT() = %v, want %s", got, testStudent)
+	}
+	if _, err := ParseJWT(&login, "email"); err == nil {
+		t.Error("ParseJWT() returned a claim the token does not have")
+	}
+}
+
+func TestVerifyJWTRejects(t *testing.T) {
+	key := useTestKey(t)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := time.Now().Add(time.Minute).Unix()
+	publicKey := x509.MarshalPKCS1PublicKey(&key.PublicKey)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"expired", signClaims(t, jwt.SigningMethodRS256, key, jwt.MapClaims{"user_id": testStudent.String(), "exp": time.Now().Add(-time.Minute).Unix()})},
+		{"no expiry", signClaims(t, jwt.SigningMethodRS256, key, jwt.MapClaims{"user_id": testStudent.String()})},
+		{"other key", signClaims(t, jwt.SigningMethodRS256, other, jwt.MapClaims{"user_id": testStudent.String(), "exp": in})},
+		// 공개 키를 HMAC 비밀 키로 쓴 토큰
+		{"HS256 with the public key", signClaims(t, jwt.SigningMethodHS256, publicKey, jwt.MapClaims{"user_id": testStudent.String(), "exp": in})},
+		{"not a token", "not.a.token"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := VerifyJWT(test.token); err == nil {
+				t.Error("VerifyJWT() accepted the token")
+			}
+		})
+	}
+}
+
+func TestMealPass(t *testing.T) {
+	useTestKey(t)
+
+	pass, err := SignMealPass(testStudent, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := VerifyMealPass(pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != testStudent {
+		t.Errorf("VerifyMealPass() = %s, want %s", got, testStudent)
+	}
+
+	// 식권으로 로그인할 수 없어야 함
+	if _, err := ParseJWT(&pass, "user_id"); err == nil {
+		t.Error("meal pass was accepted as a login token")
+	}
+	if _, err := ParseJWT(&pass, "sub"); err == nil {
+		t.Error("meal pass was accepted by ParseJWT")
+	}
+}
+
+func TestVerifyMealPassRejects(t *testing.T) {
+	key := useTestKey(t)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := time.Now().Add(time.Minute).Unix()
+	publicKey := x509.MarshalPKCS1PublicKey(&key.PublicKey)
+
+	login, err := SignJWT(testStudent.String(), "user_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := SignMealPass(testStudent, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"login token", login},
+		{"expired", expired},
+		{"wrong type", signClaims(t, jwt.SigningMethodRS256, key, jwt.MapClaims{"typ": "email", "aud": mealPassType, "sub": testStudent.String(), "exp": in})},
+		{"wrong audience", signClaims(t, jwt.SigningMethodRS256, key, jwt.MapClaims{"typ": mealPassType, "aud": "login", "sub": testStudent.String(), "exp": in})},
+		{"no subject", signClaims(t, jwt.SigningMethodRS256, key, jwt.MapClaims{"typ": mealPassType, "aud": mealPassType, "exp": in})},
+		{"no expiry", signClaims(t, jwt.SigningMethodRS256, key, jwt.MapClaims{"typ": mealPassType, "aud": mealPassType, "sub": testStudent.String()})},
+		{"other key", signClaims(t, jwt.SigningMethodRS256, other, jwt.MapClaims{"typ": mealPassType, "aud": mealPassType, "sub": testStudent.String(), "exp": in})},
+		{"HS256 with the public key", signClaims(t, jwt.SigningMethodHS256, publicKey, jwt.MapClaims{"typ": mealPassType, "aud": mealPassType, "sub": testStudent.String(), "exp": in})},
+		{"not a token", "not.a.token"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := VerifyMealPass(test.token); err == nil {
+				t.Error("VerifyMealPass() accepted the token")
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// maxImagePixels 압축을 풀면 메모리를 너무 많이 쓰는 이미지를 막기 위한 한도
+const maxImagePixels = 40_000_000
+
+var ErrUnsupportedImage = errors.New("only JPEG and PNG images are supported")
+
+// DecodeUploadedImage sniffs the real type of an upload from its contents rather than its file name
+// and decodes it, refusing images too large to decode safely
+func DecodeUploadedImage(contents []byte) (image.Image, string, error) {
+	contentType := http.DetectContentType(contents)
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return nil, "", ErrUnsupportedImage
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(contents))
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return nil, "", errors.New("image dimensions are too large")
+	}
+
+	var img image.Image
+	if contentType == "image/png" {
+		img, err = png.Decode(bytes.NewReader(contents))
+	} else {
+		img, err = jpeg.Decode(bytes.NewReader(contents))
+	}
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+	return img, contentType, nil
+}
+
+// Thumbnail shrinks img to fit in a size×size square and encodes it as JPEG
+// 각 픽셀을 원본에서 그 픽셀이 덮는 영역의 평균으로 만들어서 작게 줄여도 깨지지 않음, 이미 작으면 크기를 그대로 둠
+func Thumbnail(img image.Image, size int) ([]byte, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, maxInt(1, height*size/width)
+		} else {
+			width, height = maxInt(1, width*size/height), size
+		}
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := maxInt(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := maxInt(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			// JPEG에는 투명도가 없으므로 투명한 부분은 흰 배경 위에 놓은 것처럼 만듦
+			white := 0xffff - a/n
+			offset := thumb.PixOffset(x, y)
+			thumb.Pix[offset] = uint8((r/n + white) >> 8)
+			thumb.Pix[offset+1] = uint8((g/n + white) >> 8)
+			thumb.Pix[offset+2] = uint8((b/n + white) >> 8)
+			thumb.Pix[offset+3] = 0xff
+		}
+	}
+
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 80})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/username/schoolapp/models"
 	"golang.org/x/crypto/bcrypt"
+	"strings"
 	"time"
 )
 
@@ -89,11 +90,11 @@ func ValidateTimeTableEntry(entry *models.TimetableEntry) error {
 	if entry.Location == "" {
 		return errors.New("location field is empty")
 	}
-	if entry.Day == "" {
-		return errors.New("day field is empty")
+	if !entry.Day.Valid() {
+		return errors.New("day field is invalid")
 	}
-	if entry.Period == "" {
-		return errors.New("period field is empty")
+	if !entry.Period.Valid() {
+		return errors.New("period field is invalid")
 	}
 	if entry.Subject == "" {
 		return errors.New("subject field is empty")
@@ -140,6 +141,88 @@ func ValidateCafeteriaMenu(menu *models.CafeteriaMenu) error {
 		return errors.New("missing contents")
 	}
 
+	for _, dish := range menu.Dishes {
+		if strings.TrimSpace(dish.Name) == "" {
+			return errors.New("missing dish name")
+		}
+		for _, allergen := range dish.Allergens {
+			if !allergen.Valid() {
+				return fmt.Errorf("invalid allergen of %s: %d", dish.Name, allergen)
+			}
+		}
+	}
+	if menu.Calories != nil && *menu.Calories < 0 {
+		return errors.New("calories cannot be negative")
+	}
+	for _, nutrient := range menu.Nutrients {
+		if nutrient.Name == "" || nutrient.Amount < 0 {
+			return fmt.Errorf("invalid nutrient: %s", nutrient.Name)
+		}
+	}
+
+	return nil
+}
+
+// maxAllergyIngredients 알레르기 정보에 넣을 수 있는 재료 수
+const maxAllergyIngredients = 20
+
+// ValidateAllergyProfile checks the allergens are standard numbers and trims the custom ingredients
+func ValidateAllergyProfile(profile *models.AllergyProfile) error {
+	seen := make(map[models.AllergyType]bool)
+	for _, allergen := range profile.Allergens {
+		if !allergen.Valid() {
+			return fmt.Errorf("invalid allergen: %d", allergen)
+		}
+		if seen[allergen] {
+			return fmt.Errorf("duplicate allergen: %d", allergen)
+		}
+		seen[allergen] = true
+	}
+
+	if len(profile.Ingredients) > maxAllergyIngredients {
+		return fmt.Errorf("at most %d ingredients are allowed", maxAllergyIngredients)
+	}
+	for i, ingredient := range profile.Ingredients {
+		ingredient = strings.TrimSpace(ingredient)
+		if ingredient == "" || len([]rune(ingredient)) > 50 {
+			return errors.New("ingredients should be between 1 and 50 characters")
+		}
+		profile.Ingredients[i] = ingredient
+	}
+
+	return nil
+}
+
+// ValidateMealRating checks the score and that the rated dish is served in menu
+func ValidateMealRating(rating *models.MealRating, menu *models.CafeteriaMenu) error {
+	if rating.Score < 1 || rating.Score > 5 {
+		return errors.New("score should be between 1 and 5")
+	}
+	if len([]rune(rating.Comment)) > 500 {
+		return errors.New("comment should be at most 500 characters")
+	}
+	if rating.Dish == "" {
+		return nil
+	}
+	for _, dish := range menu.Dishes {
+		if dish.Name == rating.Dish {
+			return nil
+		}
+	}
+	return fmt.Errorf("dish is not served in this meal: %s", rating.Dish)
+}
+
+// ValidateMealSignupPolicy 마감은 급식 2주 전부터 당일까지만 정할 수 있음
+func ValidateMealSignupPolicy(policy *models.MealSignupPolicy) error {
+	if policy.SchoolId == "" {
+		return errors.New("missing school ID")
+	}
+	if policy.DaysBefore < 0 || policy.DaysBefore > 14 {
+		return errors.New("days_before should be between 0 and 14")
+	}
+	if policy.Cutoff < 0 || policy.Cutoff >= 24*60 {
+		return errors.New("invalid cutoff time")
+	}
 	return nil
 }
 
@@ -185,6 +268,70 @@ func ValidateSchool(events *models.School) error {
 	return nil
 }
 
+// ValidateBellSchedule checks that every period is listed once and ends after it starts
+func ValidateBellSchedule(schedule *models.BellSchedule) error {
+	if schedule.SchoolId == "" {
+		return fmt.Errorf("school ID is required")
+	}
+	if schedule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(schedule.Periods) == 0 {
+		return fmt.Errorf("at least one period is required")
+	}
+	for _, day := range schedule.Weekdays {
+		if !day.Valid() {
+			return fmt.Errorf("invalid weekday: %d", day)
+		}
+	}
+
+	seen := make(map[models.Period]bool, len(schedule.Periods))
+	for _, bell := range schedule.Periods {
+		if !bell.Period.Valid() {
+			return fmt.Errorf("invalid period: %d", bell.Period)
+		}
+		if seen[bell.Period] {
+			return fmt.Errorf("period %d is listed more than once", bell.Period)
+		}
+		seen[bell.Period] = true
+		if bell.End <= bell.Start {
+			return fmt.Errorf("period %d ends before it starts", bell.Period)
+		}
+	}
+	return nil
+}
+
+// ValidateTerm checks that a term has a name and ends on or after its start date
+func ValidateTerm(term *models.Term) error {
+	if term.SchoolId == "" {
+		return fmt.Errorf("school ID is required")
+	}
+	if term.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	start, end, err := term.Dates(time.UTC)
+	if err != nil {
+		return fmt.Errorf("dates should be in YYYY-MM-DD format")
+	}
+	if end.Before(start) {
+		return fmt.Errorf("term ends before it starts")
+	}
+	return nil
+}
+
+func ValidateRoom(room *models.Room) error {
+	if room.SchoolId == "" {
+		return fmt.Errorf("school ID is required")
+	}
+	if room.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if room.Capacity < 0 {
+		return fmt.Errorf("capacity cannot be negative")
+	}
+	return nil
+}
+
 func isValidAttendanceType(attendanceType models.AttendanceType) bool {
 	return attendanceType == models.YES || attendanceType == models.IGNORED || attendanceType == models.NO
 }
@@ -201,3 +348,102 @@ func VerifyPassword(hashedPassword, password []byte) bool {
 	err := bcrypt.CompareHashAndPassword(hashedPassword, password)
 	return err == nil
 }
+
+// ValidateTimetableException checks that the fields the exception's kind needs are set
+func ValidateTimetableException(exception *models.TimetableException) error {
+	if exception.SchoolId == "" {
+		return fmt.Errorf("school ID is required")
+	}
+	if _, err := exception.ParseDate(time.UTC); err != nil {
+		return fmt.Errorf("date should be in YYYY-MM-DD format")
+	}
+	switch exception.Kind {
+	case models.CancelException:
+	case models.MoveException:
+		if exception.Period == nil || !exception.Period.Valid() {
+			return fmt.Errorf("a valid period is required to move a lesson")
+		}
+	case models.SubstituteException:
+		if exception.TeacherId == nil || *exception.TeacherId == uuid.Nil {
+			return fmt.Errorf("teacher is required for a substitution")
+		}
+	case models.RoomChangeException:
+		if exception.Location == "" {
+			return fmt.Errorf("location is required for a room change")
+		}
+	default:
+		return fmt.Errorf("invalid kind: %s", exception.Kind)
+	}
+	return nil
+}
+
+// ValidateElectiveGroup checks that every option has lessons and room for students, and no lesson is in two options
+func ValidateElectiveGroup(group *models.ElectiveGroup) error {
+	if group.SchoolId == "" {
+		return fmt.Errorf("school ID is required")
+	}
+	if group.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if group.Grade <= 0 {
+		return fmt.Errorf("grade is required")
+	}
+	if len(group.Options) < 2 {
+		return fmt.Errorf("at least two options are required")
+	}
+	seen := make(map[models.DbId]bool)
+	for _, option := range group.Options {
+		if option.Name == "" {
+			return fmt.Errorf("every option needs a name")
+		}
+		if option.Capacity <= 0 {
+			return fmt.Errorf("capacity of %s should be positive", option.Name)
+		}
+		if len(option.Entries) == 0 {
+			return fmt.Errorf("%s has no lessons", option.Name)
+		}
+		for _, id := range option.Entries {
+			if seen[id] {
+				return fmt.Errorf("lesson %d is in more than one option", id)
+			}
+			seen[id] = true
+		}
+	}
+	return nil
+}
+
+// ValidateElectiveWindow checks the selection mode and that the window closes after it opens
+func ValidateElectiveWindow(group *models.ElectiveGroup) error {
+	if group.Mode != models.FirstComeElective && group.Mode != models.RankedElective {
+		return fmt.Errorf("invalid mode: %s", group.Mode)
+	}
+	if group.OpensAt == nil || group.ClosesAt == nil {
+		return fmt.Errorf("opens_at and closes_at are required")
+	}
+	if !group.ClosesAt.After(*group.OpensAt) {
+		return fmt.Errorf("window closes before it opens")
+	}
+	return nil
+}
+
+// ValidateElectiveChoice checks that optionIds are options of the group, each listed once
+// 선착순에서는 과목을 하나만 고를 수 있음
+func ValidateElectiveChoice(group *models.ElectiveGroup, optionIds []models.DbId) error {
+	if len(optionIds) == 0 {
+		return fmt.Errorf("choose at least one option")
+	}
+	if group.Mode == models.FirstComeElective && len(optionIds) > 1 {
+		return fmt.Errorf("only one option can be chosen first come, first served")
+	}
+	seen := make(map[models.DbId]bool, len(optionIds))
+	for _, id := range optionIds {
+		if group.Option(id) == nil {
+			return fmt.Errorf("option %d is not in this group", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("option %d is listed more than once", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}